@@ -101,6 +101,10 @@ func (o *addChannelOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *addChannelOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *addChannelOpts) context() Context {
 	return o.ctx
 }
@@ -137,13 +141,14 @@ func (o *addChannelOpts) buildQuery() (*url.Values, error) {
 	}
 
 	q.Set("add", strings.Join(channels, ","))
+	o.pubnub.tokenManager.setAuthParam(q, PNGroups, o.ChannelGroup)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *addChannelOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *addChannelOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *addChannelOpts) buildBody() ([]byte, error) {
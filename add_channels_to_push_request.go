@@ -97,6 +97,10 @@ func (o *addChannelsToPushOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *addChannelsToPushOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *addChannelsToPushOpts) context() Context {
 	return o.ctx
 }
@@ -146,8 +150,8 @@ func (o *addChannelsToPushOpts) buildQuery() (*url.Values, error) {
 	return q, nil
 }
 
-func (o *addChannelsToPushOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *addChannelsToPushOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *addChannelsToPushOpts) buildBody() ([]byte, error) {
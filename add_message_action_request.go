@@ -0,0 +1,217 @@
+package pubnub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/sprucehealth/pubnub-go/pnerr"
+	"github.com/sprucehealth/pubnub-go/utils"
+)
+
+var emptyPNAddMessageActionResponse *PNAddMessageActionResponse
+
+const addMessageActionPath = "/v1/message-actions/%s/channel/%s/message/%s"
+
+type addMessageActionBuilder struct {
+	opts *addMessageActionOpts
+}
+
+func newAddMessageActionBuilder(pubnub *PubNub) *addMessageActionBuilder {
+	builder := addMessageActionBuilder{
+		opts: &addMessageActionOpts{
+			pubnub: pubnub,
+		},
+	}
+
+	return &builder
+}
+
+func newAddMessageActionBuilderWithContext(pubnub *PubNub,
+	context Context) *addMessageActionBuilder {
+	builder := addMessageActionBuilder{
+		opts: &addMessageActionOpts{
+			pubnub: pubnub,
+			ctx:    context,
+		},
+	}
+
+	return &builder
+}
+
+// Channel sets the Channel of the message to add the action to.
+func (b *addMessageActionBuilder) Channel(channel string) *addMessageActionBuilder {
+	b.opts.Channel = channel
+
+	return b
+}
+
+// MessageTimetoken sets the publish timetoken of the message to add the action to.
+func (b *addMessageActionBuilder) MessageTimetoken(timetoken string) *addMessageActionBuilder {
+	b.opts.MessageTimetoken = timetoken
+
+	return b
+}
+
+// Action sets the type and value of the action to add.
+func (b *addMessageActionBuilder) Action(action MessageAction) *addMessageActionBuilder {
+	b.opts.Action = action
+
+	return b
+}
+
+// QueryParam accepts a map, the keys and values of the map are passed as the query string parameters of the URL called by the API.
+func (b *addMessageActionBuilder) QueryParam(queryParam map[string]string) *addMessageActionBuilder {
+	b.opts.QueryParam = queryParam
+
+	return b
+}
+
+// Transport sets the Transport for the addMessageAction request.
+func (b *addMessageActionBuilder) Transport(tr http.RoundTripper) *addMessageActionBuilder {
+	b.opts.Transport = tr
+	return b
+}
+
+// Execute runs the addMessageAction request.
+func (b *addMessageActionBuilder) Execute() (*PNAddMessageActionResponse, StatusResponse, error) {
+	rawJSON, status, err := executeRequest(b.opts)
+	if err != nil {
+		return emptyPNAddMessageActionResponse, status, err
+	}
+
+	return newPNAddMessageActionResponse(rawJSON, b.opts, status)
+}
+
+type addMessageActionOpts struct {
+	pubnub *PubNub
+
+	Channel          string
+	MessageTimetoken string
+	Action           MessageAction
+	QueryParam       map[string]string
+
+	Transport http.RoundTripper
+
+	ctx Context
+}
+
+func (o *addMessageActionOpts) config() Config {
+	return *o.pubnub.Config
+}
+
+func (o *addMessageActionOpts) client() *http.Client {
+	return o.pubnub.GetClient()
+}
+
+func (o *addMessageActionOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
+func (o *addMessageActionOpts) context() Context {
+	return o.ctx
+}
+
+func (o *addMessageActionOpts) validate() error {
+	if o.config().SubscribeKey == "" {
+		return newValidationError(o, StrMissingSubKey)
+	}
+
+	if o.Channel == "" {
+		return newValidationError(o, StrMissingChannel)
+	}
+
+	if o.MessageTimetoken == "" {
+		return newValidationError(o, StrMissingMessageTimetoken)
+	}
+
+	if o.Action.ActionType == "" {
+		return newValidationError(o, StrMissingMessageActionType)
+	}
+
+	if o.Action.ActionValue == "" {
+		return newValidationError(o, StrMissingMessageActionValue)
+	}
+
+	return nil
+}
+
+func (o *addMessageActionOpts) buildPath() (string, error) {
+	return fmt.Sprintf(addMessageActionPath,
+		o.pubnub.Config.SubscribeKey,
+		utils.URLEncode(o.Channel),
+		o.MessageTimetoken), nil
+}
+
+func (o *addMessageActionOpts) buildQuery() (*url.Values, error) {
+
+	q := defaultQuery(o.pubnub.Config.UUID, o.pubnub.telemetryManager)
+
+	o.pubnub.tokenManager.setAuthParam(q, PNChannels, o.Channel)
+	SetQueryParam(q, o.QueryParam)
+
+	return q, nil
+}
+
+func (o *addMessageActionOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
+}
+
+func (o *addMessageActionOpts) buildBody() ([]byte, error) {
+	jsonEncBytes, errEnc := json.Marshal(o.Action)
+
+	if errEnc != nil {
+		o.pubnub.Config.logger().Error("request body serialization failed", "error", errEnc)
+		return []byte{}, errEnc
+	}
+	return jsonEncBytes, nil
+}
+
+func (o *addMessageActionOpts) httpMethod() string {
+	return "POST"
+}
+
+func (o *addMessageActionOpts) isAuthRequired() bool {
+	return true
+}
+
+func (o *addMessageActionOpts) requestTimeout() int {
+	return o.pubnub.Config.NonSubscribeRequestTimeout
+}
+
+func (o *addMessageActionOpts) connectTimeout() int {
+	return o.pubnub.Config.ConnectTimeout
+}
+
+func (o *addMessageActionOpts) operationType() OperationType {
+	return PNAddMessageActionsOperation
+}
+
+func (o *addMessageActionOpts) telemetryManager() *TelemetryManager {
+	return o.pubnub.telemetryManager
+}
+
+// PNAddMessageActionResponse is the Message Actions API Response for Add Message Action
+type PNAddMessageActionResponse struct {
+	Status int                      `json:"status"`
+	Data   PNMessageActionsResponse `json:"data"`
+}
+
+func newPNAddMessageActionResponse(jsonBytes []byte, o *addMessageActionOpts,
+	status StatusResponse) (*PNAddMessageActionResponse, StatusResponse, error) {
+
+	resp := &PNAddMessageActionResponse{}
+
+	err := json.Unmarshal(jsonBytes, &resp)
+	if err != nil {
+		e := pnerr.NewResponseParsingError("Error unmarshalling response",
+			ioutil.NopCloser(bytes.NewBufferString(string(jsonBytes))), err)
+
+		return emptyPNAddMessageActionResponse, status, e
+	}
+
+	return resp, status, nil
+}
@@ -0,0 +1,116 @@
+package pubnub
+
+import (
+	"fmt"
+	"testing"
+
+	h "github.com/sprucehealth/pubnub-go/tests/helpers"
+	"github.com/stretchr/testify/assert"
+)
+
+func AssertAddMessageAction(t *testing.T, checkQueryParam, testContext bool) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+
+	queryParam := map[string]string{
+		"q1": "v1",
+		"q2": "v2",
+	}
+
+	if !checkQueryParam {
+		queryParam = nil
+	}
+
+	o := newAddMessageActionBuilder(pn)
+	if testContext {
+		o = newAddMessageActionBuilderWithContext(pn, backgroundContext)
+	}
+
+	o.Channel("ch")
+	o.MessageTimetoken("15698453474046033")
+	o.Action(MessageAction{
+		ActionType:  "reaction",
+		ActionValue: "smiley_face",
+	})
+	o.QueryParam(queryParam)
+
+	path, err := o.opts.buildPath()
+	assert.Nil(err)
+
+	h.AssertPathsEqual(t,
+		fmt.Sprintf("/v1/message-actions/%s/channel/%s/message/%s", pn.Config.SubscribeKey, "ch", "15698453474046033"),
+		path, []int{})
+
+	body, err := o.opts.buildBody()
+	assert.Nil(err)
+
+	expectedBody := "{\"type\":\"reaction\",\"value\":\"smiley_face\"}"
+
+	assert.Equal(expectedBody, string(body))
+	assert.Equal("POST", o.opts.httpMethod())
+
+	if checkQueryParam {
+		u, _ := o.opts.buildQuery()
+		assert.Equal("v1", u.Get("q1"))
+		assert.Equal("v2", u.Get("q2"))
+	}
+
+}
+
+func TestAddMessageAction(t *testing.T) {
+	AssertAddMessageAction(t, true, false)
+}
+
+func TestAddMessageActionContext(t *testing.T) {
+	AssertAddMessageAction(t, true, true)
+}
+
+func TestAddMessageActionValidate(t *testing.T) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+
+	o := newAddMessageActionBuilder(pn)
+	o.Channel("ch")
+	assert.Contains(o.opts.validate().Error(), StrMissingMessageTimetoken)
+
+	o.MessageTimetoken("15698453474046033")
+	assert.Contains(o.opts.validate().Error(), StrMissingMessageActionType)
+
+	o.Action(MessageAction{ActionType: "reaction"})
+	assert.Contains(o.opts.validate().Error(), StrMissingMessageActionValue)
+
+	o.Action(MessageAction{ActionType: "reaction", ActionValue: "smiley_face"})
+	assert.Nil(o.opts.validate())
+}
+
+func TestAddMessageActionResponseValueError(t *testing.T) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+	opts := &addMessageActionOpts{
+		pubnub: pn,
+	}
+	jsonBytes := []byte(`s`)
+
+	_, _, err := newPNAddMessageActionResponse(jsonBytes, opts, StatusResponse{})
+	assert.Equal("pubnub/parsing: Error unmarshalling response: {s}", err.Error())
+}
+
+// {"status": 200, "data": {"messageTimetoken": "15698453474046033", "type": "reaction", "uuid": "pn-871b8325-a11f-48cb-9c15-64984790703e", "value": "smiley_face", "actionTimetoken": "15698453605345560"}}
+func TestAddMessageActionResponseValuePass(t *testing.T) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+	opts := &addMessageActionOpts{
+		pubnub: pn,
+	}
+	jsonBytes := []byte(`{"status": 200, "data": {"messageTimetoken": "15698453474046033", "type": "reaction", "uuid": "pn-871b8325-a11f-48cb-9c15-64984790703e", "value": "smiley_face", "actionTimetoken": "15698453605345560"}}`)
+
+	r, _, err := newPNAddMessageActionResponse(jsonBytes, opts, StatusResponse{})
+	assert.Equal(200, r.Status)
+	assert.Equal("15698453474046033", r.Data.MessageTimetoken)
+	assert.Equal("reaction", r.Data.ActionType)
+	assert.Equal("smiley_face", r.Data.ActionValue)
+	assert.Equal("15698453605345560", r.Data.ActionTimetoken)
+	assert.Equal("pn-871b8325-a11f-48cb-9c15-64984790703e", r.Data.UUID)
+
+	assert.Nil(err)
+}
@@ -0,0 +1,204 @@
+package pubnub
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// catchUp fetches from the storage the messages missed while the subscribe loop was reconnecting,
+// see Config.CatchUpOnReconnect. The live messages received meanwhile are held, to be announced
+// after the missed ones, and the messages received both ways are announced once.
+type catchUp struct {
+	sync.Mutex
+
+	// generation is incremented by each catch-up, for a previous one to stop.
+	generation int
+	active     bool
+	delivered  map[string]bool
+	held       []*PNMessage
+}
+
+func newCatchUp() *catchUp {
+	return &catchUp{
+		delivered: make(map[string]bool),
+	}
+}
+
+func catchUpKey(channel string, timetoken int64) string {
+	return channel + "/" + strconv.FormatInt(timetoken, 10)
+}
+
+// start begins a catch-up, the messages of the previous one are forgotten.
+func (c *catchUp) start() int {
+	c.Lock()
+	defer c.Unlock()
+
+	c.generation++
+	c.active = true
+	c.delivered = make(map[string]bool)
+	c.held = nil
+
+	return c.generation
+}
+
+// replay returns true if the fetched message is to be announced, the catch-up is stopped
+// when a new one was started.
+func (c *catchUp) replay(generation int, message *PNMessage) (announce, stopped bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	if generation != c.generation {
+		return false, true
+	}
+
+	key := catchUpKey(message.Channel, message.Timetoken)
+	if c.delivered[key] {
+		return false, false
+	}
+	c.delivered[key] = true
+
+	return true, false
+}
+
+// live returns true if the message received by the subscribe loop is to be announced now.
+// The message is held while a catch-up is running, and dropped if it was fetched already.
+func (c *catchUp) live(message *PNMessage) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	key := catchUpKey(message.Channel, message.Timetoken)
+	if c.delivered[key] {
+		return false
+	}
+
+	if c.active {
+		c.held = append(c.held, message)
+		return false
+	}
+
+	return true
+}
+
+// next returns the held messages to announce, the ones which were fetched are skipped. The catch-up
+// is finished once there are none left, the subscribe loop then announces the live messages itself.
+func (c *catchUp) next(generation int) []*PNMessage {
+	c.Lock()
+	defer c.Unlock()
+
+	if generation != c.generation {
+		return nil
+	}
+
+	var held []*PNMessage
+	for _, message := range c.held {
+		if !c.delivered[catchUpKey(message.Channel, message.Timetoken)] {
+			held = append(held, message)
+		}
+	}
+	c.held = nil
+
+	if len(held) == 0 {
+		c.active = false
+	}
+
+	return held
+}
+
+// runCatchUp fetches the messages of the subscribed channels published after the timetoken
+// and announces them with Replayed set, then the live messages held meanwhile. The channel
+// groups and the wildcard channels can't be fetched and are skipped.
+func (m *SubscriptionManager) runCatchUp(generation int, timetoken int64) {
+	for _, ch := range m.stateManager.prepareChannelList(false) {
+		if strings.HasSuffix(ch, ".*") {
+			continue
+		}
+
+		if !m.replayChannel(generation, ch, timetoken) {
+			return
+		}
+	}
+
+	for {
+		held := m.catchUp.next(generation)
+		if len(held) == 0 {
+			return
+		}
+
+		for _, message := range held {
+			m.listenerManager.announceMessage(message)
+		}
+	}
+}
+
+// replayChannel announces the messages of the channel published after the timetoken, oldest first.
+// false is returned when the catch-up was stopped by a new one.
+func (m *SubscriptionManager) replayChannel(generation int, channel string, timetoken int64) bool {
+	cursor := timetoken
+
+	for {
+		start := cursor
+		res, status, err := m.pubnub.FetchWithContext(m.pubnub.ctx).
+			Channels([]string{channel}).
+			End(cursor + 1).
+			Count(maxCountFetch).
+			Reverse(true).
+			IncludeMeta(true).
+			IncludeUUID(true).
+			Execute()
+		if err != nil {
+			m.pubnub.Config.logger().Error("catch-up fetch failed", "channel", channel, "error", err)
+			m.listenerManager.announceStatus(&PNStatus{
+				Category:         status.Category,
+				Operation:        PNFetchMessagesOperation,
+				StatusCode:       status.StatusCode,
+				Error:            true,
+				ErrorData:        err,
+				AffectedChannels: []string{channel},
+			})
+			return true
+		}
+
+		items := res.Messages[channel]
+		m.pubnub.Config.logger().Debug("catch-up fetched messages", "channel", channel, "count", len(items))
+
+		for _, item := range items {
+			tt, err := strconv.ParseInt(item.Timetoken, 10, 64)
+			if err != nil || tt <= cursor {
+				continue
+			}
+			cursor = tt
+
+			// a message received live before the reconnection, or held meanwhile, is not replayed
+			if m.dedupCache.seen(messageDedupKey(channel, tt, item.UUID, item.Meta)) {
+				m.pubnub.Config.logger().Debug("dropping duplicate replayed message", "channel", channel, "timetoken", tt)
+				continue
+			}
+
+			message := &PNMessage{
+				Message:           item.Message,
+				UserMetadata:      item.Meta,
+				SubscribedChannel: channel,
+				Channel:           channel,
+				Publisher:         item.UUID,
+				Timetoken:         tt,
+				Replayed:          true,
+				PublishMetadata:   PNPublishMetadata{SubscribeKey: m.pubnub.Config.SubscribeKey},
+			}
+			message.Raw, _ = json.Marshal(item.Message)
+
+			announce, stopped := m.catchUp.replay(generation, message)
+			if stopped {
+				return false
+			}
+			if announce {
+				m.listenerManager.announceMessage(message)
+			}
+		}
+
+		if len(items) < maxCountFetch || cursor == start {
+			return true
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package pubnub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sprucehealth/pubnub-go/pubnubtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatchUpHoldsLiveMessages(t *testing.T) {
+	assert := assert.New(t)
+
+	c := newCatchUp()
+	assert.True(c.live(&PNMessage{Channel: "ch", Timetoken: 1}))
+
+	generation := c.start()
+	assert.False(c.live(&PNMessage{Channel: "ch", Timetoken: 3}))
+	assert.False(c.live(&PNMessage{Channel: "ch", Timetoken: 4}))
+
+	announce, stopped := c.replay(generation, &PNMessage{Channel: "ch", Timetoken: 2})
+	assert.True(announce)
+	assert.False(stopped)
+	announce, _ = c.replay(generation, &PNMessage{Channel: "ch", Timetoken: 3})
+	assert.True(announce)
+	announce, _ = c.replay(generation, &PNMessage{Channel: "ch", Timetoken: 3})
+	assert.False(announce)
+
+	held := c.next(generation)
+	if assert.Len(held, 1) {
+		assert.Equal(int64(4), held[0].Timetoken)
+	}
+	assert.Empty(c.next(generation))
+
+	assert.False(c.live(&PNMessage{Channel: "ch", Timetoken: 3}))
+	assert.True(c.live(&PNMessage{Channel: "ch", Timetoken: 5}))
+}
+
+func TestCatchUpStoppedByNewOne(t *testing.T) {
+	assert := assert.New(t)
+
+	c := newCatchUp()
+	generation := c.start()
+	c.start()
+
+	_, stopped := c.replay(generation, &PNMessage{Channel: "ch", Timetoken: 2})
+	assert.True(stopped)
+	assert.Nil(c.next(generation))
+}
+
+func TestRunCatchUp(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	config := NewConfig()
+	config.PublishKey = "pub"
+	config.SubscribeKey = "sub"
+
+	pn := NewPubNub(config)
+	defer pn.Destroy()
+	pn.SetClient(srv.Client())
+
+	listener := NewListener()
+	pn.AddListener(listener)
+
+	m := pn.subscriptionManager
+	m.stateManager.adaptSubscribeOperation(&SubscribeOperation{Channels: []string{"ch", "other", "news.*"}})
+
+	lastSeen, _ := srv.Publish("ch", "publisher", "seen")
+	srv.Publish("ch", "publisher", "missed 1")
+	missed2, _ := srv.Publish("ch", "publisher", "missed 2")
+
+	generation := m.catchUp.start()
+	assert.False(m.catchUp.live(&PNMessage{Message: "missed 2", Channel: "ch", Timetoken: missed2}))
+	assert.False(m.catchUp.live(&PNMessage{Message: "live", Channel: "ch", Timetoken: missed2 + 1}))
+
+	m.runCatchUp(generation, lastSeen)
+
+	var received []*PNMessage
+	for len(received) < 3 {
+		select {
+		case msg := <-listener.Message:
+			received = append(received, msg)
+		case <-listener.Status:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the messages")
+		}
+	}
+
+	assert.Equal("missed 1", received[0].Message)
+	assert.True(received[0].Replayed)
+	assert.Equal("missed 2", received[1].Message)
+	assert.True(received[1].Replayed)
+	assert.Equal(missed2, received[1].Timetoken)
+	assert.Equal("live", received[2].Message)
+	assert.False(received[2].Replayed)
+
+	assert.True(m.catchUp.live(&PNMessage{Channel: "ch", Timetoken: missed2 + 2}))
+}
+
+func TestRunCatchUpMessageFields(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	config := NewConfig()
+	config.PublishKey = "pub"
+	config.SubscribeKey = "sub"
+	config.UUID = "publisher"
+
+	pn := NewPubNub(config)
+	defer pn.Destroy()
+	pn.SetClient(srv.Client())
+
+	listener := NewListener()
+	pn.AddListener(listener)
+
+	m := pn.subscriptionManager
+	m.stateManager.adaptSubscribeOperation(&SubscribeOperation{Channels: []string{"ch"}})
+
+	lastSeen, _ := srv.Publish("ch", "publisher", "seen")
+
+	// received live before the reconnection
+	received, _, err := pn.Publish().Channel("ch").Message("received").Execute()
+	assert.Nil(err)
+	m.dedupCache.seen(messageDedupKey("ch", received.Timestamp, "publisher", nil))
+	_, _, err = pn.Publish().Channel("ch").Message("received").DedupeKey("a").Execute()
+	assert.Nil(err)
+	m.dedupCache.seen(messageDedupKey("ch", 0, "publisher", map[string]interface{}{"pn_dedupe_key": "a"}))
+
+	missed, _, err := pn.Publish().Channel("ch").Message(map[string]interface{}{"text": "missed"}).
+		Meta(map[string]interface{}{"k": "v"}).Execute()
+	assert.Nil(err)
+
+	m.runCatchUp(m.catchUp.start(), lastSeen)
+
+	select {
+	case msg := <-listener.Message:
+		assert.Equal(map[string]interface{}{"text": "missed"}, msg.Message)
+		assert.Equal(map[string]interface{}{"k": "v"}, msg.UserMetadata)
+		assert.Equal("publisher", msg.Publisher)
+		assert.Equal(missed.Timestamp, msg.Timetoken)
+		assert.Equal("sub", msg.PublishMetadata.SubscribeKey)
+		assert.Equal(`{"text":"missed"}`, string(msg.Raw))
+		assert.True(msg.Replayed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the message")
+	}
+
+	select {
+	case msg := <-listener.Message:
+		assert.Fail("Unexpected message", msg)
+	default:
+	}
+
+	// the replayed message is dropped when received live
+	assert.True(m.dedupCache.seen(messageDedupKey("ch", missed.Timestamp, "publisher", nil)))
+}
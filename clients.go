@@ -38,3 +38,20 @@ func NewHTTP2Client(connectTimeout int, responseReadTimeout int) *http.Client {
 
 	return client
 }
+
+// newClientWithTransport returns a client which sends the request over the per request transport,
+// keeping the read timeout from the config and the redirect policy and cookie jar of the shared client.
+func newClientWithTransport(client *http.Client, tr http.RoundTripper, responseReadTimeout int) *http.Client {
+	c := &http.Client{
+		Transport: tr,
+		// Covers the entire exchange from Dial to reading the body
+		Timeout: time.Duration(responseReadTimeout) * time.Second,
+	}
+
+	if client != nil {
+		c.CheckRedirect = client.CheckRedirect
+		c.Jar = client.Jar
+	}
+
+	return c
+}
@@ -3,7 +3,6 @@ package pubnub
 import (
 	"fmt"
 	"github.com/sprucehealth/pubnub-go/utils"
-	"log"
 )
 
 const (
@@ -14,30 +13,46 @@ const (
 // PubNub client behaviour. Configuration instance contain additional set of
 // properties which allow to perform precise PubNub client configuration.
 type Config struct {
-	PublishKey                 string             // PublishKey you can get it from admin panel (only required if publishing).
-	SubscribeKey               string             // SubscribeKey you can get it from admin panel.
-	SecretKey                  string             // SecretKey (only required for modifying/revealing access permissions).
-	AuthKey                    string             // AuthKey If Access Manager is utilized, client will use this AuthKey in all restricted requests.
-	Origin                     string             // Custom Origin if needed
-	UUID                       string             // UUID to be used as a device identifier, a default uuid is generated if not passed.
-	CipherKey                  string             // If CipherKey is passed, all communications to/from PubNub will be encrypted.
-	Secure                     bool               // True to use TLS
-	ConnectTimeout             int                // net.Dialer.Timeout
-	NonSubscribeRequestTimeout int                // http.Client.Timeout for non-subscribe requests
-	SubscribeRequestTimeout    int                // http.Client.Timeout for subscribe requests only
-	HeartbeatInterval          int                // The frequency of the pings to the server to state that the client is active
-	PresenceTimeout            int                // The time after which the server will send a timeout for the client
-	MaximumReconnectionRetries int                // The config sets how many times to retry to reconnect before giving up.
-	MaximumLatencyDataAge      int                // Max time to store the latency data for telemetry
-	FilterExpression           string             // Feature to subscribe with a custom filter expression.
-	PNReconnectionPolicy       ReconnectionPolicy // Reconnection policy selection
-	Log                        *log.Logger        // Logger instance
-	SuppressLeaveEvents        bool               // When true the SDK doesn't send out the leave requests.
-	DisablePNOtherProcessing   bool               // PNOther processing looks for pn_other in the JSON on the recevied message
-	UseHTTP2                   bool               // HTTP2 Flag
-	MessageQueueOverflowCount  int                // When the limit is exceeded by the number of messages received in a single subscribe request, a status event PNRequestMessageCountExceededCategory is fired.
-	MaxIdleConnsPerHost        int                // Used to set the value of HTTP Transport's MaxIdleConnsPerHost.
-	MaxWorkers                 int                // Number of max workers for Publish and Grant requests
+	PublishKey                    string                 // PublishKey you can get it from admin panel (only required if publishing).
+	SubscribeKey                  string                 // SubscribeKey you can get it from admin panel.
+	SecretKey                     string                 // SecretKey (only required for modifying/revealing access permissions).
+	AuthKey                       string                 // AuthKey If Access Manager is utilized, client will use this AuthKey in all restricted requests.
+	Origin                        string                 // Custom Origin if needed
+	UUID                          string                 // UUID to be used as a device identifier, a default uuid is generated if not passed.
+	CipherKey                     string                 // If CipherKey is passed, all communications to/from PubNub will be encrypted.
+	UseRandomInitializationVector bool                   // When true, messages are encrypted with AES-256-CBC and a random IV, prefixed with a cryptor header. Messages encrypted with the legacy static IV can still be decrypted.
+	Cryptor                       utils.Cryptor          // Custom Cryptor used to encrypt messages and state payloads, takes precedence over CipherKey.
+	Decryptors                    []utils.Cryptor        // Additional cryptors used to decrypt payloads, picked by the cryptor ID in the payload header.
+	Secure                        bool                   // True to use TLS
+	ConnectTimeout                int                    // net.Dialer.Timeout
+	NonSubscribeRequestTimeout    int                    // http.Client.Timeout for non-subscribe requests
+	SubscribeRequestTimeout       int                    // http.Client.Timeout for subscribe requests only
+	HeartbeatInterval             int                    // The frequency of the pings to the server to state that the client is active
+	PresenceTimeout               int                    // The time after which the server will send a timeout for the client
+	MaximumReconnectionRetries    int                    // The config sets how many times to retry to reconnect before giving up.
+	MaximumLatencyDataAge         int                    // Max time to store the latency data for telemetry
+	FilterExpression              string                 // Feature to subscribe with a custom filter expression.
+	PNReconnectionPolicy          ReconnectionPolicy     // Reconnection policy selection
+	ReconnectionBackoff           ReconnectionBackoff    // Delays between the connectivity probes of the PNReconnectionPolicy, see ReconnectionBackoff.
+	ConnectivityProbe             ConnectivityProbeFunc  // Checks the connectivity while reconnecting, a Time request when nil.
+	Log                           Logger                 // Logger instance, see NewStdLogger and NewSlogLogger
+	DisableLogRedaction           bool                   // When true the auth keys, signatures, cipher keys and messages are logged, for debugging only.
+	SuppressLeaveEvents           bool                   // When true the SDK doesn't send out the leave requests.
+	DisablePNOtherProcessing      bool                   // PNOther processing looks for pn_other in the JSON on the recevied message
+	UseHTTP2                      bool                   // HTTP2 Flag
+	MessageQueueOverflowCount     int                    // When the limit is exceeded by the number of messages received in a single subscribe request, a status event PNRequestMessageCountExceededCategory is fired.
+	MaxIdleConnsPerHost           int                    // Used to set the value of HTTP Transport's MaxIdleConnsPerHost.
+	MaxWorkers                    int                    // Number of request workers sending the non-subscribe requests, 0 to send them directly.
+	RequestQueueSize              int                    // Max number of requests of each RequestPriority waiting for a request worker.
+	RequestQueueFullPolicy        RequestQueueFullPolicy // What is done with the requests made while the queue of their priority is full.
+	RequestPriorities             RequestPriorities      // Overrides the default priorities of the operations on the request workers, see RequestPriority.
+	CatchUpOnReconnect            bool                   // When true, the messages missed while reconnecting are fetched from the storage and announced with PNMessage.Replayed set.
+	RefreshHereNowOnInterval      bool                   // When true, HereNow is called when an interval presence event has HereNowRefresh set, and its occupants are announced in a PNPresence with the PNHereNowEvent event.
+	DedupCacheSize                int                    // Number of received messages remembered to drop the ones received again, 0 disables the de-duplication.
+	DedupCacheTTL                 int                    // Seconds a received message is remembered for the de-duplication, 0 to remember it until it's evicted.
+	ListenerQueueSize             int                    // Max number of events queued for each channel of a listener, see NewListenerWithQueue.
+	ListenerOverflowPolicy        ListenerOverflowPolicy // What is done with the new events of a listener whose queue is full, they wait for room by default.
+	RequestRetryPolicy            RequestRetryPolicy     // Retries of the non-subscribe requests which fail with a connection error, a 429 or a 5xx response. Disabled by default.
 }
 
 // NewDemoConfig initiates the config with demo keys, for tests only.
@@ -68,6 +83,12 @@ func NewConfig() *Config {
 		MessageQueueOverflowCount:  100,
 		MaxIdleConnsPerHost:        30,
 		MaxWorkers:                 20,
+		RequestQueueSize:           100,
+		RequestQueueFullPolicy:     PNRequestQueueBlock,
+		DedupCacheSize:             1000,
+		DedupCacheTTL:              900,
+		ListenerQueueSize:          100,
+		ListenerOverflowPolicy:     PNOverflowBlock,
 	}
 
 	c.UUID = fmt.Sprintf("pn-%s", utils.UUID())
@@ -77,14 +98,26 @@ func NewConfig() *Config {
 
 func (c *Config) checkMinTimeout(timeout int) int {
 	if timeout < minTimeout {
-		if c.Log != nil {
-			c.Log.Println(fmt.Sprintf("PresenceTimeout value less than the min recommended value of %[1]d, setting value to %[1]d %d", minTimeout, timeout))
-		}
+		c.logger().Warn("PresenceTimeout is less than the min recommended value, using the min value",
+			"presence_timeout", timeout, "min", minTimeout)
 		timeout = minTimeout
 	}
 	return timeout
 }
 
+// logger returns the Log of the config, which redacts the sensitive fields unless DisableLogRedaction is set.
+func (c Config) logger() Logger {
+	l := c.Log
+	if l == nil {
+		l = nopLogger{}
+	}
+
+	return redactingLogger{
+		logger:   l,
+		disabled: c.DisableLogRedaction,
+	}
+}
+
 // SetPresenceTimeoutWithCustomInterval sets the presence timeout and interval.
 // timeout: How long the server will consider the client alive for presence.
 // interval: How often the client will announce itself to server.
@@ -105,3 +138,36 @@ func (c *Config) SetPresenceTimeout(timeout int) *Config {
 	timeout = c.checkMinTimeout(timeout)
 	return c.SetPresenceTimeoutWithCustomInterval(timeout, (timeout/2)-1)
 }
+
+// cryptoModule returns the CryptoModule used to encrypt and decrypt the payloads, or nil if encryption is not configured.
+// The cipherKey, when not empty, overrides both the Cryptor and the CipherKey of the Config for encryption.
+func (c *Config) cryptoModule(cipherKey string) *utils.CryptoModule {
+	encryptor := c.Cryptor
+	if cipherKey != "" {
+		encryptor = nil
+	} else {
+		cipherKey = c.CipherKey
+	}
+
+	if c.Cryptor == nil && cipherKey == "" {
+		return nil
+	}
+
+	var keyCryptors []utils.Cryptor
+	if cipherKey != "" {
+		keyCryptors = []utils.Cryptor{utils.NewLegacyCryptor(cipherKey), utils.NewAESCBCCryptor(cipherKey)}
+	}
+
+	if encryptor == nil {
+		if c.UseRandomInitializationVector {
+			encryptor = keyCryptors[1]
+		} else {
+			encryptor = keyCryptors[0]
+		}
+	}
+
+	decryptors := append(keyCryptors, c.Cryptor)
+	decryptors = append(decryptors, c.Decryptors...)
+
+	return utils.NewCryptoModule(encryptor, decryptors...)
+}
@@ -0,0 +1,200 @@
+package pubnub
+
+import (
+	"sync"
+)
+
+// connectionStateBufferSize is the number of state changes buffered for each watcher, the oldest
+// are dropped when the watcher doesn't read them.
+const connectionStateBufferSize = 16
+
+// ConnectionStateChange is sent on the channels returned by WatchConnectionState.
+type ConnectionStateChange struct {
+	Previous ConnectionState
+	Current  ConnectionState
+	// Error is the error which caused the change to PNReconnectingState, PNStoppedState or
+	// PNFailedState, if any.
+	Error error
+}
+
+// connectionEvent is an event of the subscribe loop, driving the connectionStateMachine.
+type connectionEvent int
+
+const (
+	// connectionSubscribe is the change of the subscribed channels, or a reconnection which
+	// has no timetoken to resume from.
+	connectionSubscribe connectionEvent = 1 + iota
+	// connectionUnsubscribeAll is the unsubscription of the last channels.
+	connectionUnsubscribeAll
+	// connectionReceived is a successful subscribe response.
+	connectionReceived
+	// connectionLost is a network failure or a subscribe request failure to retry.
+	connectionLost
+	// connectionReconnected is the network coming back, the loop resumes from its timetoken.
+	connectionReconnected
+	// connectionFailed is a failure which isn't retried.
+	connectionFailed
+	// connectionStop is the loop stopped by the app, or failing without a reconnection policy.
+	connectionStop
+)
+
+func (e connectionEvent) String() string {
+	switch e {
+	case connectionSubscribe:
+		return "Subscribe"
+	case connectionUnsubscribeAll:
+		return "Unsubscribe All"
+	case connectionReceived:
+		return "Received"
+	case connectionLost:
+		return "Lost"
+	case connectionReconnected:
+		return "Reconnected"
+	case connectionFailed:
+		return "Failed"
+	case connectionStop:
+		return "Stop"
+	default:
+		return ""
+	}
+}
+
+// nextConnectionState returns the state the event moves to from the state. false is returned
+// when the event doesn't change the state.
+func nextConnectionState(state ConnectionState, event connectionEvent) (ConnectionState, bool) {
+	next := state
+
+	switch event {
+	case connectionSubscribe:
+		next = PNHandshakingState
+	case connectionUnsubscribeAll:
+		// the reason the loop stopped is kept until the next subscribe
+		if state != PNStoppedState && state != PNFailedState {
+			next = PNUnsubscribedState
+		}
+	case connectionReceived:
+		if state == PNHandshakingState || state == PNReconnectingState {
+			next = PNReceivingState
+		}
+	case connectionLost:
+		if state == PNHandshakingState || state == PNReceivingState {
+			next = PNReconnectingState
+		}
+	case connectionReconnected:
+		if state == PNReconnectingState {
+			next = PNReceivingState
+		}
+	case connectionFailed:
+		next = PNFailedState
+	case connectionStop:
+		if state != PNFailedState {
+			next = PNStoppedState
+		}
+	}
+
+	return next, next != state
+}
+
+// loopRunning returns true for the states in which the subscribe loop is connecting, connected
+// or reconnecting.
+func loopRunning(state ConnectionState) bool {
+	return state == PNHandshakingState || state == PNReceivingState || state == PNReconnectingState
+}
+
+// connectionStateMachine holds the ConnectionState of the subscribe loop. The loop, the
+// subscribe and unsubscribe operations and the ReconnectionManager send it their events,
+// and it notifies the watchers of the state changes in order.
+type connectionStateMachine struct {
+	sync.Mutex
+
+	pubnub   *PubNub
+	state    ConnectionState
+	watchers map[chan ConnectionStateChange]bool
+	// stopped is called on the change to a state in which the loop isn't running, for what runs
+	// along with the loop to stop before the watchers are notified.
+	stopped func()
+}
+
+func newConnectionStateMachine(pubnub *PubNub) *connectionStateMachine {
+	return &connectionStateMachine{
+		pubnub:   pubnub,
+		state:    PNUnsubscribedState,
+		watchers: make(map[chan ConnectionStateChange]bool),
+	}
+}
+
+func (c *connectionStateMachine) current() ConnectionState {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.state
+}
+
+// running returns true if the subscribe loop is running in the current state.
+func (c *connectionStateMachine) running() bool {
+	return loopRunning(c.current())
+}
+
+// handle applies the event and returns the state before it, and false if the state didn't change.
+func (c *connectionStateMachine) handle(event connectionEvent, err error) (ConnectionState, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	previous := c.state
+	next, changed := nextConnectionState(previous, event)
+	if !changed {
+		return previous, false
+	}
+
+	c.state = next
+	c.pubnub.Config.logger().Debug("connection state changed", "event", event, "from", previous, "to", next)
+
+	if c.stopped != nil && loopRunning(previous) && !loopRunning(next) {
+		c.stopped()
+	}
+
+	change := ConnectionStateChange{
+		Previous: previous,
+		Current:  next,
+		Error:    err,
+	}
+	for w := range c.watchers {
+		notifyConnectionState(w, change)
+	}
+
+	return previous, true
+}
+
+// notifyConnectionState sends the change without blocking, dropping the oldest buffered one
+// if the watcher is full.
+func notifyConnectionState(w chan ConnectionStateChange, change ConnectionStateChange) {
+	for {
+		select {
+		case w <- change:
+			return
+		default:
+		}
+
+		select {
+		case <-w:
+		default:
+		}
+	}
+}
+
+func (c *connectionStateMachine) watch() (<-chan ConnectionStateChange, func()) {
+	w := make(chan ConnectionStateChange, connectionStateBufferSize)
+
+	c.Lock()
+	c.watchers[w] = true
+	c.Unlock()
+
+	var once sync.Once
+	return w, func() {
+		once.Do(func() {
+			c.Lock()
+			delete(c.watchers, w)
+			c.Unlock()
+		})
+	}
+}
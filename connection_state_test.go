@@ -0,0 +1,308 @@
+package pubnub
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sprucehealth/pubnub-go/pubnubtest"
+	"github.com/sprucehealth/pubnub-go/tests/stubs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextConnectionState(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		state   ConnectionState
+		event   connectionEvent
+		next    ConnectionState
+		changed bool
+	}{
+		{PNUnsubscribedState, connectionSubscribe, PNHandshakingState, true},
+		{PNReceivingState, connectionSubscribe, PNHandshakingState, true},
+		{PNFailedState, connectionSubscribe, PNHandshakingState, true},
+		{PNStoppedState, connectionSubscribe, PNHandshakingState, true},
+		{PNHandshakingState, connectionReceived, PNReceivingState, true},
+		{PNReceivingState, connectionReceived, PNReceivingState, false},
+		{PNUnsubscribedState, connectionReceived, PNUnsubscribedState, false},
+		{PNStoppedState, connectionReceived, PNStoppedState, false},
+		{PNReceivingState, connectionLost, PNReconnectingState, true},
+		{PNHandshakingState, connectionLost, PNReconnectingState, true},
+		{PNUnsubscribedState, connectionLost, PNUnsubscribedState, false},
+		{PNReconnectingState, connectionReconnected, PNReceivingState, true},
+		{PNReceivingState, connectionReconnected, PNReceivingState, false},
+		{PNReconnectingState, connectionFailed, PNFailedState, true},
+		{PNReceivingState, connectionUnsubscribeAll, PNUnsubscribedState, true},
+		{PNFailedState, connectionUnsubscribeAll, PNFailedState, false},
+		{PNStoppedState, connectionUnsubscribeAll, PNStoppedState, false},
+		{PNReceivingState, connectionStop, PNStoppedState, true},
+		{PNFailedState, connectionStop, PNFailedState, false},
+	}
+
+	for _, test := range tests {
+		next, changed := nextConnectionState(test.state, test.event)
+		assert.Equal(test.next, next, "%s on %s", test.state, test.event)
+		assert.Equal(test.changed, changed, "%s on %s", test.state, test.event)
+	}
+}
+
+func TestConnectionStateMachineWatch(t *testing.T) {
+	assert := assert.New(t)
+
+	c := newConnectionStateMachine(NewPubNub(NewDemoConfig()))
+	changes, stop := c.watch()
+
+	err := errors.New("network down")
+	c.handle(connectionSubscribe, nil)
+	c.handle(connectionReceived, nil)
+	c.handle(connectionReceived, nil)
+	c.handle(connectionLost, err)
+
+	assert.Equal(ConnectionStateChange{PNUnsubscribedState, PNHandshakingState, nil}, <-changes)
+	assert.Equal(ConnectionStateChange{PNHandshakingState, PNReceivingState, nil}, <-changes)
+	assert.Equal(ConnectionStateChange{PNReceivingState, PNReconnectingState, err}, <-changes)
+	assert.Equal(PNReconnectingState, c.current())
+
+	stop()
+	stop()
+	c.handle(connectionReconnected, nil)
+	assert.Len(changes, 0)
+}
+
+func TestConnectionStateMachineWatchOverflow(t *testing.T) {
+	assert := assert.New(t)
+
+	c := newConnectionStateMachine(NewPubNub(NewDemoConfig()))
+	changes, stop := c.watch()
+	defer stop()
+
+	for i := 0; i < connectionStateBufferSize; i++ {
+		c.handle(connectionSubscribe, nil)
+		c.handle(connectionReceived, nil)
+	}
+	c.handle(connectionStop, nil)
+
+	assert.Len(changes, connectionStateBufferSize)
+	var last ConnectionStateChange
+	for len(changes) > 0 {
+		last = <-changes
+	}
+	assert.Equal(PNStoppedState, last.Current)
+}
+
+// waitForConnectionState reads the changes until the state, the test fails after 5 seconds.
+func waitForConnectionState(t *testing.T, changes <-chan ConnectionStateChange, state ConnectionState) []ConnectionState {
+	var states []ConnectionState
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case change := <-changes:
+			states = append(states, change.Current)
+			if change.Current == state {
+				return states
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for the state %s, got %v", state, states)
+		}
+	}
+}
+
+func TestConnectionStateLifecycle(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	config := NewConfig()
+	config.PublishKey = "pub"
+	config.SubscribeKey = "sub"
+
+	pn := NewPubNub(config)
+	pn.SetClient(srv.Client())
+	pn.SetSubscribeClient(srv.Client())
+
+	changes, stop := pn.WatchConnectionState()
+	defer stop()
+	assert.Equal(PNUnsubscribedState, pn.ConnectionState())
+
+	pn.Subscribe().Channels([]string{"ch"}).Execute()
+	assert.Equal([]ConnectionState{PNHandshakingState, PNReceivingState},
+		waitForConnectionState(t, changes, PNReceivingState))
+	assert.Equal(PNReceivingState, pn.ConnectionState())
+
+	pn.UnsubscribeAll()
+	assert.Equal([]ConnectionState{PNUnsubscribedState},
+		waitForConnectionState(t, changes, PNUnsubscribedState))
+
+	pn.Destroy()
+	assert.Equal(PNStoppedState, pn.ConnectionState())
+}
+
+func TestConnectionStateAccessDenied(t *testing.T) {
+	assert := assert.New(t)
+
+	interceptor := stubs.NewInterceptor()
+	interceptor.AddStub(&stubs.Stub{
+		Method:             "GET",
+		Path:               "/v2/subscribe/sub/ch/0",
+		ResponseBody:       `{"message":"Forbidden","payload":{"channels":["ch"]},"error":true,"service":"Access Manager","status":403}`,
+		IgnoreQueryKeys:    []string{"pnsdk", "uuid", "heartbeat"},
+		ResponseStatusCode: 403,
+	})
+
+	config := NewConfig()
+	config.PublishKey = "pub"
+	config.SubscribeKey = "sub"
+	config.SuppressLeaveEvents = true
+
+	pn := NewPubNub(config)
+	defer pn.Destroy()
+	pn.SetSubscribeClient(&http.Client{Transport: interceptor.Transport})
+
+	changes, stop := pn.WatchConnectionState()
+	defer stop()
+
+	pn.Subscribe().Channels([]string{"ch"}).Execute()
+	assert.Equal([]ConnectionState{PNHandshakingState, PNFailedState},
+		waitForConnectionState(t, changes, PNFailedState))
+
+	waitUntil(t, func() bool {
+		return len(pn.GetSubscribedChannels()) == 0
+	})
+	assert.Equal(PNFailedState, pn.ConnectionState())
+}
+
+func TestConnectionLostResubscribesOnProbe(t *testing.T) {
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+	srv.FailNext(1, http.StatusInternalServerError, nil, "v2", "subscribe")
+
+	config := NewConfig()
+	config.PublishKey = "pub"
+	config.SubscribeKey = "sub"
+	config.PNReconnectionPolicy = PNLinearPolicy
+	pn := NewPubNub(config)
+	pn.SetClient(srv.Client())
+	pn.SetSubscribeClient(srv.Client())
+	defer pn.Destroy()
+
+	changes, stop := pn.WatchConnectionState()
+	defer stop()
+
+	pn.Subscribe().Channels([]string{"ch"}).Execute()
+	waitForConnectionState(t, changes, PNReconnectingState)
+
+	// the network is connected, the next successful probe resumes the subscribe loop
+	pn.NetworkChanged()
+	waitForConnectionState(t, changes, PNReceivingState)
+}
+
+// loopTransport counts the subscribe requests being sent to the server, to check the
+// ConnectionState against what the subscribe loop really does.
+type loopTransport struct {
+	srv     *pubnubtest.Server
+	polling int32
+}
+
+func (t *loopTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.Contains(req.URL.String(), "/v2/subscribe/") {
+		atomic.AddInt32(&t.polling, 1)
+		defer atomic.AddInt32(&t.polling, -1)
+	}
+
+	return t.srv.RoundTrip(req)
+}
+
+func (t *loopTransport) subscribing() bool {
+	return atomic.LoadInt32(&t.polling) > 0
+}
+
+// TestConnectionStateFollowsLoop checks after each transition that the subscribe requests, the
+// heartbeats and the connectivity probes only run in the states in which the loop is running.
+func TestConnectionStateFollowsLoop(t *testing.T) {
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+	transport := &loopTransport{srv: srv}
+
+	config := NewConfig()
+	config.PublishKey = "pub"
+	config.SubscribeKey = "sub"
+	config.PNReconnectionPolicy = PNLinearPolicy
+	config.SuppressLeaveEvents = true
+	pn := NewPubNub(config)
+	pn.SetClient(srv.Client())
+	pn.SetSubscribeClient(&http.Client{Transport: transport})
+	defer pn.Destroy()
+
+	changes, stop := pn.WatchConnectionState()
+	defer stop()
+
+	expect := func(state ConnectionState) {
+		if pn.ConnectionState() != state {
+			waitForConnectionState(t, changes, state)
+		}
+
+		subscribing := state == PNHandshakingState || state == PNReceivingState
+		running := loopRunning(state)
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			pn.heartbeatManager.RLock()
+			heartbeat := pn.heartbeatManager.hbRunning
+			pn.heartbeatManager.RUnlock()
+
+			rm := pn.subscriptionManager.reconnectionManager
+			rm.RLock()
+			probing := rm.hbRunning
+			rm.RUnlock()
+
+			if transport.subscribing() == subscribing && heartbeat == running && probing == running {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s: subscribing %v, heartbeat %v, probing %v", state, transport.subscribing(), heartbeat, probing)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Equal(t, state, pn.ConnectionState())
+	}
+	expect(PNUnsubscribedState)
+
+	pn.Subscribe().Channels([]string{"ch"}).Execute()
+	expect(PNReceivingState)
+
+	pn.Disconnect()
+	expect(PNStoppedState)
+
+	// the loop resumes from its timetoken, it's connected once a message is received
+	pn.Reconnect()
+	expect(PNHandshakingState)
+	srv.Publish("ch", "publisher", "hey")
+	expect(PNReceivingState)
+
+	srv.FailNext(1, http.StatusInternalServerError, nil, "v2", "subscribe")
+	pn.NetworkChanged()
+	expect(PNReconnectingState)
+
+	pn.NetworkChanged()
+	expect(PNReceivingState)
+
+	srv.FailNext(1, http.StatusForbidden, nil, "v2", "subscribe")
+	pn.NetworkChanged()
+	expect(PNFailedState)
+
+	pn.Subscribe().Channels([]string{"ch"}).Execute()
+	expect(PNReceivingState)
+
+	pn.UnsubscribeAll()
+	expect(PNUnsubscribedState)
+
+	pn.Subscribe().Channels([]string{"ch"}).Execute()
+	expect(PNReceivingState)
+
+	pn.Destroy()
+	expect(PNStoppedState)
+}
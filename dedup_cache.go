@@ -0,0 +1,101 @@
+package pubnub
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// dedupKey identifies a received message: the same publish of the same client on the same channel
+// has the same key, whether it was received in a subscribe response or fetched by a catch-up. The
+// messages published with a DedupeKey are identified by it instead of their timetoken, which changes
+// when the publish is retried.
+type dedupKey struct {
+	channel   string
+	timetoken int64
+	issuer    string
+	dedupe    string
+}
+
+type dedupEntry struct {
+	key    dedupKey
+	seenAt time.Time
+}
+
+// dedupCache remembers the most recently received messages, see Config.DedupCacheSize,
+// to drop the ones received again after a reconnection or a region change.
+type dedupCache struct {
+	// duplicates is first for the 64-bit alignment of the atomic operations.
+	duplicates uint64
+
+	sync.Mutex
+
+	size    int
+	ttl     time.Duration
+	now     func() time.Time
+	entries *list.List
+	index   map[dedupKey]*list.Element
+}
+
+func newDedupCache(size int, ttl time.Duration) *dedupCache {
+	return &dedupCache{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: list.New(),
+		index:   make(map[dedupKey]*list.Element),
+	}
+}
+
+// seen returns true if the message was received already, within the TTL. Otherwise it's
+// remembered, and the least recently received messages are forgotten above the size.
+func (c *dedupCache) seen(key dedupKey) bool {
+	if c.size <= 0 {
+		return false
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	now := c.now()
+
+	if e, ok := c.index[key]; ok {
+		if c.ttl <= 0 || now.Sub(e.Value.(*dedupEntry).seenAt) < c.ttl {
+			c.entries.MoveToFront(e)
+			atomic.AddUint64(&c.duplicates, 1)
+			return true
+		}
+		c.remove(e)
+	}
+
+	c.index[key] = c.entries.PushFront(&dedupEntry{key: key, seenAt: now})
+
+	for c.entries.Len() > c.size {
+		c.remove(c.entries.Back())
+	}
+	if c.ttl > 0 {
+		for e := c.entries.Back(); e != nil && now.Sub(e.Value.(*dedupEntry).seenAt) >= c.ttl; e = c.entries.Back() {
+			c.remove(e)
+		}
+	}
+
+	return false
+}
+
+func (c *dedupCache) remove(e *list.Element) {
+	c.entries.Remove(e)
+	delete(c.index, e.Value.(*dedupEntry).key)
+}
+
+func (c *dedupCache) len() int {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.entries.Len()
+}
+
+// duplicateCount returns the number of messages dropped as duplicates.
+func (c *dedupCache) duplicateCount() uint64 {
+	return atomic.LoadUint64(&c.duplicates)
+}
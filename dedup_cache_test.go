@@ -0,0 +1,126 @@
+package pubnub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupCacheEvictsLeastRecentlySeen(t *testing.T) {
+	assert := assert.New(t)
+
+	c := newDedupCache(2, 0)
+	a := dedupKey{"ch", 1, "client", ""}
+	b := dedupKey{"ch", 2, "client", ""}
+
+	assert.False(c.seen(a))
+	assert.False(c.seen(b))
+	assert.True(c.seen(a))
+
+	assert.False(c.seen(dedupKey{"ch", 3, "client", ""}))
+	assert.Equal(2, c.len())
+	assert.True(c.seen(a))
+	assert.False(c.seen(b))
+
+	assert.Equal(uint64(2), c.duplicateCount())
+}
+
+func TestDedupCacheKey(t *testing.T) {
+	assert := assert.New(t)
+
+	c := newDedupCache(10, 0)
+	assert.False(c.seen(dedupKey{"ch", 1, "client", ""}))
+	assert.False(c.seen(dedupKey{"other", 1, "client", ""}))
+	assert.False(c.seen(dedupKey{"ch", 2, "client", ""}))
+	assert.False(c.seen(dedupKey{"ch", 1, "another", ""}))
+	assert.False(c.seen(dedupKey{"ch", 1, "client", "key"}))
+	assert.Equal(uint64(0), c.duplicateCount())
+}
+
+func TestDedupCacheTTL(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1000, 0)
+	c := newDedupCache(10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	key := dedupKey{"ch", 1, "client", ""}
+	assert.False(c.seen(key))
+
+	now = now.Add(30 * time.Second)
+	assert.True(c.seen(key))
+
+	now = now.Add(time.Minute)
+	assert.False(c.seen(dedupKey{"ch", 2, "client", ""}))
+	assert.Equal(1, c.len())
+	assert.False(c.seen(key))
+}
+
+func TestDedupCacheDisabled(t *testing.T) {
+	c := newDedupCache(0, 0)
+	key := dedupKey{"ch", 1, "client", ""}
+
+	assert.False(t, c.seen(key))
+	assert.False(t, c.seen(key))
+}
+
+func TestProcessSubscribePayloadDropsDuplicates(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+
+	listener := NewListener()
+	pn.AddListener(listener)
+
+	message := func(payload, timetoken string, seqn int64) subscribeMessage {
+		return subscribeMessage{
+			Shard:           "1",
+			Channel:         "ch",
+			IssuingClientID: "publisher",
+			SequenceNumber:  seqn,
+			Payload:         payload,
+			PublishMetaData: publishMetadata{PublishTimetoken: timetoken},
+		}
+	}
+
+	processSubscribePayload(pn.subscriptionManager, message("first", "15000000000000000", 1))
+	processSubscribePayload(pn.subscriptionManager, message("first", "15000000000000000", 1))
+	processSubscribePayload(pn.subscriptionManager, message("second", "15000000000000001", 2))
+
+	assert.Equal("first", (<-listener.Message).Message)
+	assert.Equal("second", (<-listener.Message).Message)
+	assert.Equal(uint64(1), pn.DuplicateMessages())
+}
+
+func TestProcessSubscribePayloadDropsDedupeKeyDuplicates(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+
+	listener := NewListener()
+	pn.AddListener(listener)
+
+	// a retried publish has a new timetoken and sequence, but the same pn_dedupe_key
+	message := func(payload, timetoken string, seqn int64, key string) subscribeMessage {
+		return subscribeMessage{
+			Shard:           "1",
+			Channel:         "ch",
+			IssuingClientID: "publisher",
+			SequenceNumber:  seqn,
+			Payload:         payload,
+			UserMetadata:    map[string]interface{}{"pn_dedupe_key": key},
+			PublishMetaData: publishMetadata{PublishTimetoken: timetoken},
+		}
+	}
+
+	processSubscribePayload(pn.subscriptionManager, message("first", "15000000000000000", 1, "a"))
+	processSubscribePayload(pn.subscriptionManager, message("first", "15000000000000001", 2, "a"))
+	processSubscribePayload(pn.subscriptionManager, message("second", "15000000000000002", 3, "b"))
+
+	assert.Equal("first", (<-listener.Message).Message)
+	assert.Equal("second", (<-listener.Message).Message)
+	assert.Equal(uint64(1), pn.DuplicateMessages())
+}
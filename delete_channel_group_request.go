@@ -80,6 +80,10 @@ func (o *deleteChannelGroupOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *deleteChannelGroupOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *deleteChannelGroupOpts) context() Context {
 	return o.ctx
 }
@@ -107,12 +111,13 @@ func (o *deleteChannelGroupOpts) buildPath() (string, error) {
 
 func (o *deleteChannelGroupOpts) buildQuery() (*url.Values, error) {
 	q := defaultQuery(o.pubnub.Config.UUID, o.pubnub.telemetryManager)
+	o.pubnub.tokenManager.setAuthParam(q, PNGroups, o.ChannelGroup)
 	SetQueryParam(q, o.QueryParam)
 	return q, nil
 }
 
-func (o *deleteChannelGroupOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *deleteChannelGroupOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *deleteChannelGroupOpts) buildBody() ([]byte, error) {
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/sprucehealth/pubnub-go/pnerr"
@@ -12,15 +13,17 @@ import (
 )
 
 type endpointOpts interface {
-	jobQueue() chan *JobQItem
+	requestWorkers() *RequestWorkers
 	config() Config
 	client() *http.Client
+	transport() http.RoundTripper
 	context() Context
 	validate() error
 	buildPath() (string, error)
 	buildQuery() (*url.Values, error)
 	buildBody() ([]byte, error)
 	httpMethod() string
+	requestTimeout() int
 	operationType() OperationType
 	telemetryManager() *TelemetryManager
 }
@@ -73,14 +76,33 @@ func buildURL(o endpointOpts) (*url.URL, error) {
 		timestamp := time.Now().Unix()
 		query.Set("timestamp", strconv.Itoa(int(timestamp)))
 
-		signedInput := o.config().SubscribeKey + "\n" + o.config().PublishKey + "\n"
+		if o.operationType() == PNAccessManagerGrantToken {
+			// v3 endpoints are signed with the v2 signature, which covers the method and body
+			body, err := o.buildBody()
+			if err != nil {
+				return &url.URL{}, err
+			}
 
-		signedInput += fmt.Sprintf("%s\n", path)
+			signedInput := o.httpMethod() + "\n" + o.config().PublishKey + "\n"
 
-		signedInput += utils.PreparePamParams(query)
-		o.config().Log.Println("signedInput:", signedInput)
+			signedInput += fmt.Sprintf("%s\n", path)
 
-		signature = utils.GetHmacSha256(o.config().SecretKey, signedInput)
+			signedInput += utils.PreparePamParams(query) + "\n"
+
+			signedInput += string(body)
+			o.config().logger().Debug("request signed", "signed_input", signedInput)
+
+			signature = "v2." + strings.TrimRight(utils.GetHmacSha256(o.config().SecretKey, signedInput), "=")
+		} else {
+			signedInput := o.config().SubscribeKey + "\n" + o.config().PublishKey + "\n"
+
+			signedInput += fmt.Sprintf("%s\n", path)
+
+			signedInput += utils.PreparePamParams(query)
+			o.config().logger().Debug("request signed", "signed_input", signedInput)
+
+			signature = utils.GetHmacSha256(o.config().SecretKey, signedInput)
+		}
 	}
 
 	if o.operationType() == PNPublishOperation {
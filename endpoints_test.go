@@ -30,8 +30,8 @@ func (o *fakeEndpointOpts) buildBody() ([]byte, error) {
 	return []byte("myBody"), nil
 }
 
-func (o *fakeEndpointOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *fakeEndpointOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *fakeEndpointOpts) config() Config {
@@ -42,6 +42,10 @@ func (o *fakeEndpointOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *fakeEndpointOpts) transport() http.RoundTripper {
+	return nil
+}
+
 func (o *fakeEndpointOpts) validate() error {
 	return nil
 }
@@ -54,6 +58,10 @@ func (o *fakeEndpointOpts) httpMethod() string {
 	return "GET"
 }
 
+func (o *fakeEndpointOpts) requestTimeout() int {
+	return o.pubnub.Config.SubscribeRequestTimeout
+}
+
 func (o *fakeEndpointOpts) operationType() OperationType {
 	return PNSubscribeOperation
 }
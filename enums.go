@@ -11,6 +11,18 @@ type OperationType int
 // ReconnectionPolicy is used as an enum to catgorize the reconnection policies
 type ReconnectionPolicy int
 
+// ListenerOverflowPolicy is used as an enum to catgorize what is done with the events of a listener whose queue is full
+type ListenerOverflowPolicy int
+
+// RequestPriority is used as an enum to catgorize the priorities of the requests queued for the request workers
+type RequestPriority int
+
+// RequestQueueFullPolicy is used as an enum to catgorize what is done with the requests whose queue is full
+type RequestQueueFullPolicy int
+
+// ConnectionState is used as an enum to catgorize the states of the subscribe loop
+type ConnectionState int
+
 // PNPushType is used as an enum to catgorize the available Push Types
 type PNPushType int
 
@@ -29,6 +41,30 @@ type PNObjectsEvent string
 // PNObjectsEventType  is used as an enum to catgorize the available Object Event types
 type PNObjectsEventType string
 
+// PNResourceType is used as an enum to catgorize the resources a token grants access to
+type PNResourceType int
+
+const (
+	// PNChannels is the enum used for the channel resources of a token.
+	PNChannels PNResourceType = 1 + iota
+	// PNGroups is the enum used for the channel group resources of a token.
+	PNGroups
+	// PNUsers is the enum used for the user resources of a token.
+	PNUsers
+	// PNSpaces is the enum used for the space resources of a token.
+	PNSpaces
+)
+
+// PNMessageActionsEventType is used as an enum to catgorize the available Message Actions Event types
+type PNMessageActionsEventType string
+
+const (
+	// PNMessageActionsAdded is the enum when the event of type `added` occurs
+	PNMessageActionsAdded PNMessageActionsEventType = "added"
+	// PNMessageActionsRemoved is the enum when the event of type `removed` occurs
+	PNMessageActionsRemoved = "removed"
+)
+
 const (
 	// PNObjectsUserEvent is the enum when the event of type `user` occurs
 	PNObjectsUserEvent PNObjectsEventType = "user"
@@ -97,6 +133,59 @@ const (
 	PNExponentialPolicy
 )
 
+const (
+	// PNOverflowBlock is to be used when the announcement of the events waits for the listener to
+	// read them, ListenerOverflowPolicy is set in the config or with NewListenerWithQueue.
+	// With the other policies, a status event PNListenerQueueOverflowCategory is queued after
+	// the events already queued when events are dropped.
+	PNOverflowBlock ListenerOverflowPolicy = 1 + iota
+	// PNOverflowDropOldest is to be used when the oldest queued event is dropped to queue the new one.
+	PNOverflowDropOldest
+	// PNOverflowDropNewest is to be used when the new event is dropped.
+	PNOverflowDropNewest
+	// PNOverflowEmitStatus is to be used when the new event is dropped, like PNOverflowDropNewest.
+	PNOverflowEmitStatus
+)
+
+const (
+	// PNRequestPriorityLow is to be used for the requests sent once no request of a higher priority
+	// is waiting for a request worker, the history requests by default.
+	PNRequestPriorityLow RequestPriority = 1 + iota
+	// PNRequestPriorityNormal is to be used for the requests sent before the low priority ones.
+	PNRequestPriorityNormal
+	// PNRequestPriorityHigh is to be used for the requests sent first, the publish and presence
+	// requests by default.
+	PNRequestPriorityHigh
+)
+
+const (
+	// PNRequestQueueBlock is to be used when the requests wait for room in the queue of their priority
+	// once it's full, RequestQueueFullPolicy is set in the config.
+	PNRequestQueueBlock RequestQueueFullPolicy = 1 + iota
+	// PNRequestQueueFailFast is to be used when the requests fail with ErrRequestQueueFull once the
+	// queue of their priority is full.
+	PNRequestQueueFailFast
+)
+
+const (
+	// PNUnsubscribedState as the ConnectionState means no channel or channel group is subscribed.
+	PNUnsubscribedState ConnectionState = 1 + iota
+	// PNHandshakingState as the ConnectionState means the subscribe loop is connecting, after a
+	// subscribe or an unsubscribe changed the subscribed channels.
+	PNHandshakingState
+	// PNReceivingState as the ConnectionState means the subscribe loop is connected and receives the messages.
+	PNReceivingState
+	// PNReconnectingState as the ConnectionState means the network is down or the subscribe request
+	// failed, the reconnection policy is retrying.
+	PNReconnectingState
+	// PNStoppedState as the ConnectionState means the subscribe loop was stopped by the app, or failed
+	// without a reconnection policy.
+	PNStoppedState
+	// PNFailedState as the ConnectionState means the subscribe loop gave up, the access was denied or the
+	// reconnection attempts were exhausted. The next subscribe starts it again.
+	PNFailedState
+)
+
 const (
 	// PNMessageTypeSignal is to identify Signal the Subscribe response
 	PNMessageTypeSignal PNMessageType = 1 + iota
@@ -137,6 +226,12 @@ const (
 	PNReconnectionAttemptsExhausted
 	// PNRequestMessageCountExceededCategory is fired when the MessageQueueOverflowCount limit is exceeded by the number of messages received in a single subscribe request
 	PNRequestMessageCountExceededCategory
+	// PNRateLimitedCategory as the StatusCategory means the request was rejected as too many requests were sent (429).
+	PNRateLimitedCategory
+	// PNListenerQueueOverflowCategory as the StatusCategory means events were dropped as the queue of the listener was full.
+	PNListenerQueueOverflowCategory
+	// PNListenerPanicCategory as the StatusCategory means the EventHandler of a listener panicked, the panic is set as the ErrorData.
+	PNListenerPanicCategory
 )
 
 const (
@@ -218,6 +313,14 @@ const (
 	PNManageMembershipsOperation
 	// PNManageMembersOperation is the enum used to manage members in the Object API.
 	PNManageMembersOperation
+	// PNAddMessageActionsOperation is the enum used to add message actions.
+	PNAddMessageActionsOperation
+	// PNGetMessageActionsOperation is the enum used to get message actions.
+	PNGetMessageActionsOperation
+	// PNRemoveMessageActionsOperation is the enum used to remove message actions.
+	PNRemoveMessageActionsOperation
+	// PNAccessManagerGrantToken is the enum used for the Access Manager Grant Token operation.
+	PNAccessManagerGrantToken
 )
 
 const (
@@ -286,6 +389,10 @@ var operations = [...]string{
 	"PNGetMembersOperation",
 	"PNManageMembershipsOperation",
 	"PNManageMembersOperation",
+	"Add Message Actions",
+	"Get Message Actions",
+	"Remove Message Actions",
+	"Grant Token",
 }
 
 func (c StatusCategory) String() string {
@@ -326,12 +433,47 @@ func (c StatusCategory) String() string {
 	case PNNoStubMatchedCategory:
 		return "No Stub Matched"
 
+	case PNRateLimitedCategory:
+		return "Rate Limited"
+
+	case PNListenerQueueOverflowCategory:
+		return "Listener Queue Overflow"
+
+	case PNListenerPanicCategory:
+		return "Listener Panic"
+
 	default:
 		return "No Stub Matched"
 
 	}
 }
 
+func (s ConnectionState) String() string {
+	switch s {
+	case PNUnsubscribedState:
+		return "Unsubscribed"
+
+	case PNHandshakingState:
+		return "Handshaking"
+
+	case PNReceivingState:
+		return "Receiving"
+
+	case PNReconnectingState:
+		return "Reconnecting"
+
+	case PNStoppedState:
+		return "Stopped"
+
+	case PNFailedState:
+		return "Failed"
+
+	default:
+		return ""
+
+	}
+}
+
 func (t OperationType) String() string {
 	switch t {
 	case PNSubscribeOperation:
@@ -434,6 +576,14 @@ func (t OperationType) String() string {
 		return "Manage Memberships"
 	case PNManageMembersOperation:
 		return "Manage Members"
+	case PNAddMessageActionsOperation:
+		return "Add Message Actions"
+	case PNGetMessageActionsOperation:
+		return "Get Message Actions"
+	case PNRemoveMessageActionsOperation:
+		return "Remove Message Actions"
+	case PNAccessManagerGrantToken:
+		return "Grant Token"
 	default:
 		return "No Category Matched"
 	}
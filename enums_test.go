@@ -35,6 +35,9 @@ func TestStatusCategoryString(t *testing.T) {
 	assert.Equal("Reconnected", PNReconnectedCategory.String())
 	assert.Equal("Reconnection Attempts Exhausted", PNReconnectionAttemptsExhausted.String())
 	assert.Equal("No Stub Matched", PNNoStubMatchedCategory.String())
+	assert.Equal("Rate Limited", PNRateLimitedCategory.String())
+	assert.Equal("Listener Queue Overflow", PNListenerQueueOverflowCategory.String())
+	assert.Equal("Listener Panic", PNListenerPanicCategory.String())
 }
 
 func TestOperationTypeString(t *testing.T) {
@@ -63,4 +66,8 @@ func TestOperationTypeString(t *testing.T) {
 	assert.Equal("Grant", PNAccessManagerGrant.String())
 	assert.Equal("Revoke", PNAccessManagerRevoke.String())
 	assert.Equal("Delete messages", PNDeleteMessagesOperation.String())
+	assert.Equal("Add Message Actions", PNAddMessageActionsOperation.String())
+	assert.Equal("Get Message Actions", PNGetMessageActionsOperation.String())
+	assert.Equal("Remove Message Actions", PNRemoveMessageActionsOperation.String())
+	assert.Equal("Grant Token", PNAccessManagerGrantToken.String())
 }
@@ -0,0 +1,164 @@
+package pubnub
+
+import (
+	"fmt"
+)
+
+// EventHandler is called with the events of the subscriptions, as an alternative to reading the
+// channels of a Listener. The methods are called one at a time, in the order the events were
+// received, see NewEventListener.
+type EventHandler interface {
+	OnStatus(status *PNStatus)
+	OnMessage(message *PNMessage)
+	OnPresence(presence *PNPresence)
+	OnSignal(signal *PNMessage)
+	OnUserEvent(event *PNUserEvent)
+	OnSpaceEvent(event *PNSpaceEvent)
+	OnMembershipEvent(event *PNMembershipEvent)
+	OnMessageAction(event *PNMessageActionsEvent)
+}
+
+// ListenerFuncs is an EventHandler calling the funcs which are set, the events without a func are ignored.
+type ListenerFuncs struct {
+	Status          func(status *PNStatus)
+	Message         func(message *PNMessage)
+	Presence        func(presence *PNPresence)
+	Signal          func(signal *PNMessage)
+	UserEvent       func(event *PNUserEvent)
+	SpaceEvent      func(event *PNSpaceEvent)
+	MembershipEvent func(event *PNMembershipEvent)
+	MessageAction   func(event *PNMessageActionsEvent)
+}
+
+// OnStatus calls the Status func.
+func (f ListenerFuncs) OnStatus(status *PNStatus) {
+	if f.Status != nil {
+		f.Status(status)
+	}
+}
+
+// OnMessage calls the Message func.
+func (f ListenerFuncs) OnMessage(message *PNMessage) {
+	if f.Message != nil {
+		f.Message(message)
+	}
+}
+
+// OnPresence calls the Presence func.
+func (f ListenerFuncs) OnPresence(presence *PNPresence) {
+	if f.Presence != nil {
+		f.Presence(presence)
+	}
+}
+
+// OnSignal calls the Signal func.
+func (f ListenerFuncs) OnSignal(signal *PNMessage) {
+	if f.Signal != nil {
+		f.Signal(signal)
+	}
+}
+
+// OnUserEvent calls the UserEvent func.
+func (f ListenerFuncs) OnUserEvent(event *PNUserEvent) {
+	if f.UserEvent != nil {
+		f.UserEvent(event)
+	}
+}
+
+// OnSpaceEvent calls the SpaceEvent func.
+func (f ListenerFuncs) OnSpaceEvent(event *PNSpaceEvent) {
+	if f.SpaceEvent != nil {
+		f.SpaceEvent(event)
+	}
+}
+
+// OnMembershipEvent calls the MembershipEvent func.
+func (f ListenerFuncs) OnMembershipEvent(event *PNMembershipEvent) {
+	if f.MembershipEvent != nil {
+		f.MembershipEvent(event)
+	}
+}
+
+// OnMessageAction calls the MessageAction func.
+func (f ListenerFuncs) OnMessageAction(event *PNMessageActionsEvent) {
+	if f.MessageAction != nil {
+		f.MessageAction(event)
+	}
+}
+
+// NewEventListener returns a Listener which calls the handler instead of sending the events on
+// its channels, to be added with AddListener. The handler is called from a goroutine of the
+// listener, a panic of the handler is recovered and reported with a PNListenerPanicCategory status.
+func NewEventListener(handler EventHandler) *Listener {
+	return &Listener{
+		handler: handler,
+	}
+}
+
+// ListenerPanicError is the ErrorData of the PNListenerPanicCategory status.
+type ListenerPanicError struct {
+	Value interface{} // Value passed to panic.
+}
+
+func (e *ListenerPanicError) Error() string {
+	return fmt.Sprintf("pubnub: listener panicked: %v", e.Value)
+}
+
+// handle calls the handler of the listener with the event. A panic is reported to the handler
+// with a status, a panic while handling that status is only logged.
+func (q *listenerQueue) handle(event listenerEvent) {
+	err := q.call(event)
+	if err == nil {
+		return
+	}
+
+	q.logger.Error("listener panicked", "error", err)
+
+	if status, ok := event.payload.(*PNStatus); ok && status.Category == PNListenerPanicCategory {
+		return
+	}
+
+	err = q.call(listenerEvent{
+		kind: listenerStatusEvent,
+		payload: &PNStatus{
+			Category:  PNListenerPanicCategory,
+			Operation: PNSubscribeOperation,
+			Error:     true,
+			ErrorData: err,
+		},
+	})
+	if err != nil {
+		q.logger.Error("listener panicked", "error", err)
+	}
+}
+
+func (q *listenerQueue) call(event listenerEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &ListenerPanicError{Value: r}
+		}
+	}()
+
+	h := q.listener.handler
+
+	switch event.kind {
+	case listenerStatusEvent:
+		h.OnStatus(event.payload.(*PNStatus))
+	case listenerMessageEvent:
+		h.OnMessage(event.payload.(*PNMessage))
+	case listenerPresenceEvent:
+		h.OnPresence(event.payload.(*PNPresence))
+	case listenerSignalEvent:
+		h.OnSignal(event.payload.(*PNMessage))
+	case listenerUserEvent:
+		h.OnUserEvent(event.payload.(*PNUserEvent))
+	case listenerSpaceEvent:
+		h.OnSpaceEvent(event.payload.(*PNSpaceEvent))
+	case listenerMembershipEvent:
+		h.OnMembershipEvent(event.payload.(*PNMembershipEvent))
+	case listenerMessageActionsEvent:
+		h.OnMessageAction(event.payload.(*PNMessageActionsEvent))
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package pubnub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListenerFuncsIgnoresUnsetFuncs(t *testing.T) {
+	var f ListenerFuncs
+
+	assert.NotPanics(t, func() {
+		f.OnStatus(&PNStatus{})
+		f.OnMessage(&PNMessage{})
+		f.OnPresence(&PNPresence{})
+		f.OnSignal(&PNMessage{})
+		f.OnUserEvent(&PNUserEvent{})
+		f.OnSpaceEvent(&PNSpaceEvent{})
+		f.OnMembershipEvent(&PNMembershipEvent{})
+		f.OnMessageAction(&PNMessageActionsEvent{})
+	})
+}
+
+func TestEventListener(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+
+	messages := make(chan interface{}, 10)
+	signals := make(chan interface{}, 10)
+	statuses := make(chan StatusCategory, 10)
+
+	pn.AddListener(NewEventListener(ListenerFuncs{
+		Message: func(m *PNMessage) {
+			messages <- m.Message
+		},
+		Signal: func(m *PNMessage) {
+			signals <- m.Message
+		},
+		Status: func(s *PNStatus) {
+			statuses <- s.Category
+		},
+	}))
+
+	lm := pn.subscriptionManager.listenerManager
+	lm.announceStatus(&PNStatus{Category: PNConnectedCategory})
+	lm.announcePresence(&PNPresence{Event: "join"})
+	lm.announceMessage(&PNMessage{Message: "hello"})
+	lm.announceSignal(&PNMessage{Message: "typing"})
+
+	assert.Equal(PNConnectedCategory, <-statuses)
+	assert.Equal("hello", <-messages)
+	assert.Equal("typing", <-signals)
+}
+
+func TestEventListenerPanic(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+
+	messages := make(chan interface{}, 10)
+	statuses := make(chan *PNStatus, 10)
+
+	pn.AddListener(NewEventListener(ListenerFuncs{
+		Message: func(m *PNMessage) {
+			if m.Message == "boom" {
+				panic("boom")
+			}
+			messages <- m.Message
+		},
+		Status: func(s *PNStatus) {
+			statuses <- s
+		},
+	}))
+
+	lm := pn.subscriptionManager.listenerManager
+	lm.announceMessage(&PNMessage{Message: "boom"})
+	lm.announceMessage(&PNMessage{Message: "after"})
+
+	select {
+	case s := <-statuses:
+		assert.Equal(PNListenerPanicCategory, s.Category)
+		assert.True(s.Error)
+		if assert.IsType(&ListenerPanicError{}, s.ErrorData) {
+			assert.Equal("boom", s.ErrorData.(*ListenerPanicError).Value)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the panic status")
+	}
+
+	assert.Equal("after", <-messages)
+}
+
+func TestEventListenerPanicInStatus(t *testing.T) {
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+
+	messages := make(chan interface{}, 10)
+
+	pn.AddListener(NewEventListener(ListenerFuncs{
+		Message: func(m *PNMessage) {
+			if m.Message == "boom" {
+				panic("boom")
+			}
+			messages <- m.Message
+		},
+		Status: func(s *PNStatus) {
+			panic("status")
+		},
+	}))
+
+	lm := pn.subscriptionManager.listenerManager
+	lm.announceMessage(&PNMessage{Message: "boom"})
+	lm.announceStatus(&PNStatus{Category: PNConnectedCategory})
+	lm.announceMessage(&PNMessage{Message: "after"})
+
+	select {
+	case m := <-messages:
+		assert.Equal(t, "after", m)
+	case <-time.After(time.Second):
+		t.Fatal("the listener stopped after the panics")
+	}
+}
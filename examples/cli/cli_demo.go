@@ -53,8 +53,8 @@ func connect() {
 	}
 	//config.Log = log.New(ioutil.Discard, "", log.Ldate|log.Ltime|log.Lshortfile)
 	//config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
-	config.Log = infoLogger
-	config.Log.SetPrefix("PubNub :->  ")
+	infoLogger.SetPrefix("PubNub :->  ")
+	config.Log = pubnub.NewStdLogger(infoLogger, pubnub.PNLogDebug)
 	config.PublishKey = "pub-c-3ed95c83-12e6-4cda-9d69-c47ba2abb57e"   //"demo"   //"demo"
 	config.SubscribeKey = "sub-c-26a73b0a-c3f2-11e9-8b24-569e8a5c3af3" //"demo" //"sub-c-10b61350-bec7-11e9-a375-f698c1d99dce" //"demo" //
 	//config.SecretKey = //"pam"    //"demo"
@@ -166,8 +166,7 @@ func connect() {
 	config2.SubscribeRequestTimeout = 59
 	config2.UUID = "GlobalSubscriber"
 	config2.PNReconnectionPolicy = pubnub.PNLinearPolicy
-	config2.Log = infoLogger
-	config2.Log.SetPrefix("PubNub2:")
+	config2.Log = pubnub.NewStdLogger(log.New(f, "PubNub2:", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	pn2 := pubnub.NewPubNub(config2)
 	pn2.AddListener(listener)
@@ -77,6 +77,18 @@ func (b *fetchBuilder) Reverse(r bool) *fetchBuilder {
 	return b
 }
 
+// IncludeMeta sets whether the Meta of the messages is returned in the Fetch request.
+func (b *fetchBuilder) IncludeMeta(withMeta bool) *fetchBuilder {
+	b.opts.WithMeta = withMeta
+	return b
+}
+
+// IncludeUUID sets whether the UUID of the publishers is returned in the Fetch request.
+func (b *fetchBuilder) IncludeUUID(withUUID bool) *fetchBuilder {
+	b.opts.WithUUID = withUUID
+	return b
+}
+
 // QueryParam accepts a map, the keys and values of the map are passed as the query string parameters of the URL called by the API.
 func (b *fetchBuilder) QueryParam(queryParam map[string]string) *fetchBuilder {
 	b.opts.QueryParam = queryParam
@@ -118,6 +130,10 @@ type fetchOpts struct {
 	IncludeTimetoken bool
 	QueryParam       map[string]string
 
+	// default: false
+	WithMeta bool
+	WithUUID bool
+
 	// nil hacks
 	setStart bool
 	setEnd   bool
@@ -135,6 +151,10 @@ func (o *fetchOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *fetchOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *fetchOpts) context() Context {
 	return o.ctx
 }
@@ -177,13 +197,20 @@ func (o *fetchOpts) buildQuery() (*url.Values, error) {
 	}
 
 	q.Set("reverse", strconv.FormatBool(o.Reverse))
+	if o.WithMeta {
+		q.Set("include_meta", "true")
+	}
+	if o.WithUUID {
+		q.Set("include_uuid", "true")
+	}
+	o.pubnub.tokenManager.setAuthParam(q, PNChannels, o.Channels...)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *fetchOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *fetchOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *fetchOpts) buildBody() ([]byte, error) {
@@ -224,7 +251,7 @@ func (o *fetchOpts) fetchMessages(channels map[string]interface{}) map[string][]
 
 	for channel, histResponseSliceMap := range channels {
 		if histResponseMap, ok2 := histResponseSliceMap.([]interface{}); ok2 {
-			o.pubnub.Config.Log.Printf("Channel:%s, count:%d\n", channel, len(histResponseMap))
+			o.pubnub.Config.logger().Debug("fetch: parsing messages", "channel", channel, "count", len(histResponseMap))
 			items := make([]FetchResponseItem, len(histResponseMap))
 			count := 0
 
@@ -234,20 +261,20 @@ func (o *fetchOpts) fetchMessages(channels map[string]interface{}) map[string][]
 
 					histItem := FetchResponseItem{
 						Message:   msg,
+						Meta:      histResponse["meta"],
 						Timetoken: histResponse["timetoken"].(string),
 					}
+					histItem.UUID, _ = histResponse["uuid"].(string)
 					items[count] = histItem
-					o.pubnub.Config.Log.Printf("Channel:%s, count:%d %d\n", channel, count, len(items))
 					count++
 				} else {
-					o.pubnub.Config.Log.Printf("histResponse not a map %v\n", histResponse)
+					o.pubnub.Config.logger().Error("fetch: message is not an object, skipped", "channel", channel)
 					continue
 				}
 			}
 			messages[channel] = items
-			o.pubnub.Config.Log.Printf("Channel:%s, count:%d\n", channel, len(messages[channel]))
 		} else {
-			o.pubnub.Config.Log.Printf("histResponseSliceMap not an []interface %v\n", histResponseSliceMap)
+			o.pubnub.Config.logger().Error("fetch: messages are not an array, skipped", "channel", channel)
 			continue
 		}
 	}
@@ -270,16 +297,15 @@ func newFetchResponse(jsonBytes []byte, o *fetchOpts,
 	}
 
 	if result, ok := value.(map[string]interface{}); ok {
-		o.pubnub.Config.Log.Println(result["channels"])
 		if channels, ok1 := result["channels"].(map[string]interface{}); ok1 {
 			if channels != nil {
 				resp.Messages = o.fetchMessages(channels)
 			} else {
-				o.pubnub.Config.Log.Printf("type assertion to map failed %v\n", result)
+				o.pubnub.Config.logger().Error("fetch: channels are not an object", "body", string(jsonBytes))
 			}
 		}
 	} else {
-		o.pubnub.Config.Log.Printf("type assertion to map failed %v\n", value)
+		o.pubnub.Config.logger().Error("fetch: response is not an object", "body", string(jsonBytes))
 	}
 
 	return resp, status, nil
@@ -288,5 +314,7 @@ func newFetchResponse(jsonBytes []byte, o *fetchOpts,
 // FetchResponseItem contains the message and the associated timetoken.
 type FetchResponseItem struct {
 	Message   interface{}
+	Meta      interface{} // Set with IncludeMeta.
+	UUID      string      // The publisher, set with IncludeUUID.
 	Timetoken string
 }
@@ -118,6 +118,33 @@ func TestFetchQuery(t *testing.T) {
 	AssertSuccessFetchQuery(t, "%22test%22?max=25&reverse=false", channels)
 }
 
+func TestFetchIncludeMetaAndUUID(t *testing.T) {
+	assert := assert.New(t)
+
+	o := newFetchBuilder(pubnub)
+	o.Channels([]string{"test"})
+
+	query, _ := o.opts.buildQuery()
+	assert.Equal("", query.Get("include_meta"))
+	assert.Equal("", query.Get("include_uuid"))
+
+	o.IncludeMeta(true).IncludeUUID(true)
+
+	query, _ = o.opts.buildQuery()
+	assert.Equal("true", query.Get("include_meta"))
+	assert.Equal("true", query.Get("include_uuid"))
+
+	jsonString := []byte(`{"status": 200, "error": false, "error_message": "", "channels": {"test":[{"message":"my-message","meta":{"k":"v"},"uuid":"publisher","timetoken":"15229448184080121"}]}}`)
+
+	resp, _, err := newFetchResponse(jsonString, initFetchOpts(""), fakeResponseState)
+	assert.Nil(err)
+
+	item := resp.Messages["test"][0]
+	assert.Equal("my-message", item.Message)
+	assert.Equal(map[string]interface{}{"k": "v"}, item.Meta)
+	assert.Equal("publisher", item.UUID)
+}
+
 func initFetchOpts(cipher string) *fetchOpts {
 	pn := NewPubNub(NewDemoConfig())
 	pn.Config.CipherKey = cipher
@@ -133,6 +133,10 @@ func (o *fireOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *fireOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *fireOpts) context() Context {
 	return o.ctx
 }
@@ -169,8 +173,11 @@ func (o *fireOpts) buildPath() (string, error) {
 	var message []byte
 	var err error
 
-	if cipherKey := o.pubnub.Config.CipherKey; cipherKey != "" {
-		msg := utils.EncryptString(cipherKey, string(message))
+	if module := o.pubnub.Config.cryptoModule(""); module != nil {
+		msg, err := module.EncryptString(string(message))
+		if err != nil {
+			return "", err
+		}
 
 		o.Message = []byte(msg)
 	}
@@ -210,13 +217,14 @@ func (o *fireOpts) buildQuery() (*url.Values, error) {
 	}
 
 	q.Set("seqn", strconv.Itoa(o.pubnub.getPublishSequence()))
+	o.pubnub.tokenManager.setAuthParam(q, PNChannels, o.Channel)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *fireOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *fireOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *fireOpts) buildBody() ([]byte, error) {
@@ -238,8 +246,11 @@ func (o *fireOpts) buildBody() ([]byte, error) {
 			}
 		}
 
-		if cipherKey := o.pubnub.Config.CipherKey; cipherKey != "" {
-			enc := utils.EncryptString(cipherKey, string(msg))
+		if module := o.pubnub.Config.cryptoModule(""); module != nil {
+			enc, err := module.EncryptString(string(msg))
+			if err != nil {
+				return []byte{}, err
+			}
 			msg, err := utils.ValueAsString(enc)
 			if err != nil {
 				return []byte{}, err
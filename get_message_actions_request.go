@@ -0,0 +1,224 @@
+package pubnub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/sprucehealth/pubnub-go/pnerr"
+	"github.com/sprucehealth/pubnub-go/utils"
+)
+
+var emptyPNGetMessageActionsResponse *PNGetMessageActionsResponse
+
+const getMessageActionsPath = "/v1/message-actions/%s/channel/%s"
+
+const messageActionsLimit = 100
+
+type getMessageActionsBuilder struct {
+	opts *getMessageActionsOpts
+}
+
+func newGetMessageActionsBuilder(pubnub *PubNub) *getMessageActionsBuilder {
+	builder := getMessageActionsBuilder{
+		opts: &getMessageActionsOpts{
+			pubnub: pubnub,
+		},
+	}
+	builder.opts.Limit = messageActionsLimit
+
+	return &builder
+}
+
+func newGetMessageActionsBuilderWithContext(pubnub *PubNub,
+	context Context) *getMessageActionsBuilder {
+	builder := getMessageActionsBuilder{
+		opts: &getMessageActionsOpts{
+			pubnub: pubnub,
+			ctx:    context,
+		},
+	}
+	builder.opts.Limit = messageActionsLimit
+
+	return &builder
+}
+
+// Channel sets the Channel to fetch the message actions of.
+func (b *getMessageActionsBuilder) Channel(channel string) *getMessageActionsBuilder {
+	b.opts.Channel = channel
+
+	return b
+}
+
+// Start sets the action timetoken to fetch the actions before (exclusive).
+func (b *getMessageActionsBuilder) Start(timetoken string) *getMessageActionsBuilder {
+	b.opts.Start = timetoken
+
+	return b
+}
+
+// End sets the action timetoken to fetch the actions up to (inclusive).
+func (b *getMessageActionsBuilder) End(timetoken string) *getMessageActionsBuilder {
+	b.opts.End = timetoken
+
+	return b
+}
+
+// Limit sets the number of actions to return in the response. Default and max value is 100.
+func (b *getMessageActionsBuilder) Limit(limit int) *getMessageActionsBuilder {
+	b.opts.Limit = limit
+
+	return b
+}
+
+// QueryParam accepts a map, the keys and values of the map are passed as the query string parameters of the URL called by the API.
+func (b *getMessageActionsBuilder) QueryParam(queryParam map[string]string) *getMessageActionsBuilder {
+	b.opts.QueryParam = queryParam
+
+	return b
+}
+
+// Transport sets the Transport for the getMessageActions request.
+func (b *getMessageActionsBuilder) Transport(tr http.RoundTripper) *getMessageActionsBuilder {
+	b.opts.Transport = tr
+	return b
+}
+
+// Execute runs the getMessageActions request.
+func (b *getMessageActionsBuilder) Execute() (*PNGetMessageActionsResponse, StatusResponse, error) {
+	rawJSON, status, err := executeRequest(b.opts)
+	if err != nil {
+		return emptyPNGetMessageActionsResponse, status, err
+	}
+
+	return newPNGetMessageActionsResponse(rawJSON, b.opts, status)
+}
+
+type getMessageActionsOpts struct {
+	pubnub *PubNub
+
+	Channel    string
+	Start      string
+	End        string
+	Limit      int
+	QueryParam map[string]string
+
+	Transport http.RoundTripper
+
+	ctx Context
+}
+
+func (o *getMessageActionsOpts) config() Config {
+	return *o.pubnub.Config
+}
+
+func (o *getMessageActionsOpts) client() *http.Client {
+	return o.pubnub.GetClient()
+}
+
+func (o *getMessageActionsOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
+func (o *getMessageActionsOpts) context() Context {
+	return o.ctx
+}
+
+func (o *getMessageActionsOpts) validate() error {
+	if o.config().SubscribeKey == "" {
+		return newValidationError(o, StrMissingSubKey)
+	}
+
+	if o.Channel == "" {
+		return newValidationError(o, StrMissingChannel)
+	}
+
+	return nil
+}
+
+func (o *getMessageActionsOpts) buildPath() (string, error) {
+	return fmt.Sprintf(getMessageActionsPath,
+		o.pubnub.Config.SubscribeKey,
+		utils.URLEncode(o.Channel)), nil
+}
+
+func (o *getMessageActionsOpts) buildQuery() (*url.Values, error) {
+
+	q := defaultQuery(o.pubnub.Config.UUID, o.pubnub.telemetryManager)
+
+	if o.Start != "" {
+		q.Set("start", o.Start)
+	}
+
+	if o.End != "" {
+		q.Set("end", o.End)
+	}
+
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+
+	o.pubnub.tokenManager.setAuthParam(q, PNChannels, o.Channel)
+	SetQueryParam(q, o.QueryParam)
+
+	return q, nil
+}
+
+func (o *getMessageActionsOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
+}
+
+func (o *getMessageActionsOpts) buildBody() ([]byte, error) {
+	return []byte{}, nil
+}
+
+func (o *getMessageActionsOpts) httpMethod() string {
+	return "GET"
+}
+
+func (o *getMessageActionsOpts) isAuthRequired() bool {
+	return true
+}
+
+func (o *getMessageActionsOpts) requestTimeout() int {
+	return o.pubnub.Config.NonSubscribeRequestTimeout
+}
+
+func (o *getMessageActionsOpts) connectTimeout() int {
+	return o.pubnub.Config.ConnectTimeout
+}
+
+func (o *getMessageActionsOpts) operationType() OperationType {
+	return PNGetMessageActionsOperation
+}
+
+func (o *getMessageActionsOpts) telemetryManager() *TelemetryManager {
+	return o.pubnub.telemetryManager
+}
+
+// PNGetMessageActionsResponse is the Message Actions API Response for Get Message Actions
+type PNGetMessageActionsResponse struct {
+	Status int                        `json:"status"`
+	Data   []PNMessageActionsResponse `json:"data"`
+	More   PNGetMessageActionsMore    `json:"more"`
+}
+
+func newPNGetMessageActionsResponse(jsonBytes []byte, o *getMessageActionsOpts,
+	status StatusResponse) (*PNGetMessageActionsResponse, StatusResponse, error) {
+
+	resp := &PNGetMessageActionsResponse{}
+
+	err := json.Unmarshal(jsonBytes, &resp)
+	if err != nil {
+		e := pnerr.NewResponseParsingError("Error unmarshalling response",
+			ioutil.NopCloser(bytes.NewBufferString(string(jsonBytes))), err)
+
+		return emptyPNGetMessageActionsResponse, status, e
+	}
+
+	return resp, status, nil
+}
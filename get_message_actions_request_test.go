@@ -0,0 +1,103 @@
+package pubnub
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	h "github.com/sprucehealth/pubnub-go/tests/helpers"
+	"github.com/stretchr/testify/assert"
+)
+
+func AssertGetMessageActions(t *testing.T, checkQueryParam, testContext bool) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+
+	queryParam := map[string]string{
+		"q1": "v1",
+		"q2": "v2",
+	}
+
+	if !checkQueryParam {
+		queryParam = nil
+	}
+
+	o := newGetMessageActionsBuilder(pn)
+	if testContext {
+		o = newGetMessageActionsBuilderWithContext(pn, backgroundContext)
+	}
+
+	limit := 90
+	start := "15698453605345560"
+	end := "15698453474046033"
+
+	o.Channel("ch")
+	o.Start(start)
+	o.End(end)
+	o.Limit(limit)
+	o.QueryParam(queryParam)
+
+	path, err := o.opts.buildPath()
+	assert.Nil(err)
+
+	h.AssertPathsEqual(t,
+		fmt.Sprintf("/v1/message-actions/%s/channel/%s", pn.Config.SubscribeKey, "ch"),
+		path, []int{})
+
+	body, err := o.opts.buildBody()
+	assert.Nil(err)
+	assert.Empty(body)
+
+	if checkQueryParam {
+		u, _ := o.opts.buildQuery()
+		assert.Equal("v1", u.Get("q1"))
+		assert.Equal("v2", u.Get("q2"))
+		assert.Equal(strconv.Itoa(limit), u.Get("limit"))
+		assert.Equal(start, u.Get("start"))
+		assert.Equal(end, u.Get("end"))
+	}
+
+}
+
+func TestGetMessageActions(t *testing.T) {
+	AssertGetMessageActions(t, true, false)
+}
+
+func TestGetMessageActionsContext(t *testing.T) {
+	AssertGetMessageActions(t, true, true)
+}
+
+func TestGetMessageActionsResponseValueError(t *testing.T) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+	opts := &getMessageActionsOpts{
+		pubnub: pn,
+	}
+	jsonBytes := []byte(`s`)
+
+	_, _, err := newPNGetMessageActionsResponse(jsonBytes, opts, StatusResponse{})
+	assert.Equal("pubnub/parsing: Error unmarshalling response: {s}", err.Error())
+}
+
+// {"status": 200, "data": [{"messageTimetoken": "15698453474046033", "type": "reaction", "uuid": "pn-871b8325-a11f-48cb-9c15-64984790703e", "value": "smiley_face", "actionTimetoken": "15698453605345560"}], "more": {"url": "/v1/message-actions/sub_key/channel/ch?start=15698453605345560&limit=1", "start": "15698453605345560", "end": "15698453474046033", "limit": 1}}
+func TestGetMessageActionsResponseValuePass(t *testing.T) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+	opts := &getMessageActionsOpts{
+		pubnub: pn,
+	}
+	jsonBytes := []byte(`{"status": 200, "data": [{"messageTimetoken": "15698453474046033", "type": "reaction", "uuid": "pn-871b8325-a11f-48cb-9c15-64984790703e", "value": "smiley_face", "actionTimetoken": "15698453605345560"}], "more": {"url": "/v1/message-actions/sub_key/channel/ch?start=15698453605345560&limit=1", "start": "15698453605345560", "end": "15698453474046033", "limit": 1}}`)
+
+	r, _, err := newPNGetMessageActionsResponse(jsonBytes, opts, StatusResponse{})
+	assert.Equal(200, r.Status)
+	assert.Equal(1, len(r.Data))
+	assert.Equal("15698453474046033", r.Data[0].MessageTimetoken)
+	assert.Equal("reaction", r.Data[0].ActionType)
+	assert.Equal("smiley_face", r.Data[0].ActionValue)
+	assert.Equal("15698453605345560", r.Data[0].ActionTimetoken)
+	assert.Equal("pn-871b8325-a11f-48cb-9c15-64984790703e", r.Data[0].UUID)
+	assert.Equal("15698453605345560", r.More.Start)
+	assert.Equal(1, r.More.Limit)
+
+	assert.Nil(err)
+}
@@ -89,7 +89,15 @@ func (b *getStateBuilder) Execute() (
 		return emptyGetStateResp, status, err
 	}
 
-	return newGetStateResponse(rawJSON, status)
+	resp, status, err := newGetStateResponse(rawJSON, status)
+	if err != nil {
+		return resp, status, err
+	}
+	for ch, state := range resp.State {
+		resp.State[ch] = decryptState(b.opts.pubnub.Config, state)
+	}
+
+	return resp, status, nil
 }
 
 type getStateOpts struct {
@@ -112,6 +120,10 @@ func (o *getStateOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *getStateOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *getStateOpts) context() Context {
 	return o.ctx
 }
@@ -156,13 +168,14 @@ func (o *getStateOpts) buildQuery() (*url.Values, error) {
 	}
 
 	q.Set("channel-group", strings.Join(groups, ","))
+	o.pubnub.tokenManager.setChannelsAuthParam(q, o.Channels, o.ChannelGroups)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *getStateOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *getStateOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *getStateOpts) buildBody() ([]byte, error) {
@@ -18,7 +18,6 @@ import (
 type PNGrantType int
 
 const grantPath = "/v2/auth/grant/sub-key/%s"
-const grantV3Path = "/v3/auth/grant/sub-key/%s"
 const (
 	// PNReadEnabled Read Enabled. Applies to Subscribe, History, Presence, Objects
 	PNReadEnabled PNGrantType = 1 + iota
@@ -134,6 +133,8 @@ func (b *grantBuilder) ChannelGroups(groups []string) *grantBuilder {
 }
 
 // Users sets the Users for the Grant request.
+//
+// Deprecated: users are not supported by the legacy grant, use GrantToken instead.
 func (b *grantBuilder) Users(users []string) *grantBuilder {
 	b.opts.Users = users
 
@@ -141,6 +142,8 @@ func (b *grantBuilder) Users(users []string) *grantBuilder {
 }
 
 // Patterns sets the Patterns for the Grant request.
+//
+// Deprecated: patterns are not supported by the legacy grant and are ignored, use the pattern setters of GrantToken instead.
 func (b *grantBuilder) Patterns(pattern string, resourceTypes patterns) *grantBuilder {
 	// b.opts.Patterns = patterns
 
@@ -148,6 +151,8 @@ func (b *grantBuilder) Patterns(pattern string, resourceTypes patterns) *grantBu
 }
 
 // Spaces sets the Spaces for the Grant request.
+//
+// Deprecated: spaces are not supported by the legacy grant, use GrantToken instead.
 func (b *grantBuilder) Spaces(spaces []string) *grantBuilder {
 	b.opts.Spaces = spaces
 
@@ -215,6 +220,10 @@ func (o *grantOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *grantOpts) transport() http.RoundTripper {
+	return nil
+}
+
 func (o *grantOpts) context() Context {
 	return o.ctx
 }
@@ -291,8 +300,8 @@ func (o *grantOpts) buildQuery() (*url.Values, error) {
 	return q, nil
 }
 
-func (o *grantOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *grantOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *grantOpts) buildBody() ([]byte, error) {
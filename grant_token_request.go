@@ -0,0 +1,425 @@
+package pubnub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/sprucehealth/pubnub-go/pnerr"
+)
+
+const grantTokenPath = "/v3/pam/%s/grant"
+
+// Permission bits of a resource in a v3 token.
+const (
+	pnPermissionRead int64 = 1 << iota
+	pnPermissionWrite
+	pnPermissionManage
+	pnPermissionDelete
+	pnPermissionCreate
+)
+
+var emptyPNGrantTokenResponse *PNGrantTokenResponse
+
+// ChannelPermissions contains the permissions which can be granted on a channel.
+type ChannelPermissions struct {
+	Read   bool
+	Write  bool
+	Delete bool
+}
+
+// GroupPermissions contains the permissions which can be granted on a channel group.
+type GroupPermissions struct {
+	Read   bool
+	Manage bool
+}
+
+// UserSpacePermissions contains the permissions which can be granted on a user or a space.
+type UserSpacePermissions struct {
+	Read   bool
+	Write  bool
+	Manage bool
+	Delete bool
+	Create bool
+}
+
+func (p ChannelPermissions) bitmask() int64 {
+	return permissionsBitmask(p.Read, p.Write, false, p.Delete, false)
+}
+
+func (p GroupPermissions) bitmask() int64 {
+	return permissionsBitmask(p.Read, false, p.Manage, false, false)
+}
+
+func (p UserSpacePermissions) bitmask() int64 {
+	return permissionsBitmask(p.Read, p.Write, p.Manage, p.Delete, p.Create)
+}
+
+func permissionsBitmask(read, write, manage, del, create bool) int64 {
+	var sum int64
+	if read {
+		sum |= pnPermissionRead
+	}
+	if write {
+		sum |= pnPermissionWrite
+	}
+	if manage {
+		sum |= pnPermissionManage
+	}
+	if del {
+		sum |= pnPermissionDelete
+	}
+	if create {
+		sum |= pnPermissionCreate
+	}
+
+	return sum
+}
+
+type grantTokenBuilder struct {
+	opts *grantTokenOpts
+}
+
+func newGrantTokenBuilder(pubnub *PubNub) *grantTokenBuilder {
+	builder := grantTokenBuilder{
+		opts: &grantTokenOpts{
+			pubnub: pubnub,
+		},
+	}
+
+	return &builder
+}
+
+func newGrantTokenBuilderWithContext(pubnub *PubNub, context Context) *grantTokenBuilder {
+	builder := grantTokenBuilder{
+		opts: &grantTokenOpts{
+			pubnub: pubnub,
+			ctx:    context,
+		},
+	}
+
+	return &builder
+}
+
+// TTL in minutes for which the token is valid.
+//
+// Min: 1
+// Max: 43200
+func (b *grantTokenBuilder) TTL(ttl int) *grantTokenBuilder {
+	b.opts.TTL = ttl
+
+	return b
+}
+
+// Channels sets the permissions for each of the Channels in the token.
+func (b *grantTokenBuilder) Channels(channels map[string]ChannelPermissions) *grantTokenBuilder {
+	b.opts.Channels = channels
+
+	return b
+}
+
+// ChannelGroups sets the permissions for each of the ChannelGroups in the token.
+func (b *grantTokenBuilder) ChannelGroups(groups map[string]GroupPermissions) *grantTokenBuilder {
+	b.opts.ChannelGroups = groups
+
+	return b
+}
+
+// Users sets the permissions for each of the Users in the token.
+func (b *grantTokenBuilder) Users(users map[string]UserSpacePermissions) *grantTokenBuilder {
+	b.opts.Users = users
+
+	return b
+}
+
+// Spaces sets the permissions for each of the Spaces in the token.
+func (b *grantTokenBuilder) Spaces(spaces map[string]UserSpacePermissions) *grantTokenBuilder {
+	b.opts.Spaces = spaces
+
+	return b
+}
+
+// ChannelsPattern sets the permissions for the channels matching each of the regex patterns.
+func (b *grantTokenBuilder) ChannelsPattern(channels map[string]ChannelPermissions) *grantTokenBuilder {
+	b.opts.ChannelsPattern = channels
+
+	return b
+}
+
+// ChannelGroupsPattern sets the permissions for the channel groups matching each of the regex patterns.
+func (b *grantTokenBuilder) ChannelGroupsPattern(groups map[string]GroupPermissions) *grantTokenBuilder {
+	b.opts.ChannelGroupsPattern = groups
+
+	return b
+}
+
+// UsersPattern sets the permissions for the users matching each of the regex patterns.
+func (b *grantTokenBuilder) UsersPattern(users map[string]UserSpacePermissions) *grantTokenBuilder {
+	b.opts.UsersPattern = users
+
+	return b
+}
+
+// SpacesPattern sets the permissions for the spaces matching each of the regex patterns.
+func (b *grantTokenBuilder) SpacesPattern(spaces map[string]UserSpacePermissions) *grantTokenBuilder {
+	b.opts.SpacesPattern = spaces
+
+	return b
+}
+
+// Meta sets the Meta which is embedded in the token.
+func (b *grantTokenBuilder) Meta(meta map[string]interface{}) *grantTokenBuilder {
+	b.opts.Meta = meta
+
+	return b
+}
+
+// QueryParam accepts a map, the keys and values of the map are passed as the query string parameters of the URL called by the API.
+func (b *grantTokenBuilder) QueryParam(queryParam map[string]string) *grantTokenBuilder {
+	b.opts.QueryParam = queryParam
+
+	return b
+}
+
+// Transport sets the Transport for the GrantToken request.
+func (b *grantTokenBuilder) Transport(tr http.RoundTripper) *grantTokenBuilder {
+	b.opts.Transport = tr
+	return b
+}
+
+// Execute runs the GrantToken request.
+func (b *grantTokenBuilder) Execute() (*PNGrantTokenResponse, StatusResponse, error) {
+	rawJSON, status, err := executeRequest(b.opts)
+	if err != nil {
+		return emptyPNGrantTokenResponse, status, err
+	}
+
+	return newGrantTokenResponse(rawJSON, b.opts, status)
+}
+
+type grantTokenOpts struct {
+	pubnub *PubNub
+	ctx    Context
+
+	TTL                  int
+	Channels             map[string]ChannelPermissions
+	ChannelGroups        map[string]GroupPermissions
+	Users                map[string]UserSpacePermissions
+	Spaces               map[string]UserSpacePermissions
+	ChannelsPattern      map[string]ChannelPermissions
+	ChannelGroupsPattern map[string]GroupPermissions
+	UsersPattern         map[string]UserSpacePermissions
+	SpacesPattern        map[string]UserSpacePermissions
+	Meta                 map[string]interface{}
+	QueryParam           map[string]string
+
+	Transport http.RoundTripper
+}
+
+type grantTokenResources struct {
+	Channels map[string]int64 `json:"channels"`
+	Groups   map[string]int64 `json:"groups"`
+	Users    map[string]int64 `json:"users"`
+	Spaces   map[string]int64 `json:"spaces"`
+}
+
+type grantTokenPermissions struct {
+	Resources grantTokenResources    `json:"resources"`
+	Patterns  grantTokenResources    `json:"patterns"`
+	Meta      map[string]interface{} `json:"meta"`
+}
+
+type grantTokenBody struct {
+	TTL         int                   `json:"ttl"`
+	Permissions grantTokenPermissions `json:"permissions"`
+}
+
+func (o *grantTokenOpts) config() Config {
+	return *o.pubnub.Config
+}
+
+func (o *grantTokenOpts) client() *http.Client {
+	return o.pubnub.GetClient()
+}
+
+func (o *grantTokenOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
+func (o *grantTokenOpts) context() Context {
+	return o.ctx
+}
+
+func (o *grantTokenOpts) resourcesCount() int {
+	return len(o.Channels) + len(o.ChannelGroups) + len(o.Users) + len(o.Spaces) +
+		len(o.ChannelsPattern) + len(o.ChannelGroupsPattern) + len(o.UsersPattern) + len(o.SpacesPattern)
+}
+
+func (o *grantTokenOpts) validate() error {
+	if o.config().PublishKey == "" {
+		return newValidationError(o, StrMissingPubKey)
+	}
+
+	if o.config().SubscribeKey == "" {
+		return newValidationError(o, StrMissingSubKey)
+	}
+
+	if o.config().SecretKey == "" {
+		return newValidationError(o, StrMissingSecretKey)
+	}
+
+	if o.TTL <= 0 {
+		return newValidationError(o, StrInvalidTTL)
+	}
+
+	if o.resourcesCount() == 0 {
+		return newValidationError(o, StrMissingResources)
+	}
+
+	for _, p := range o.patterns() {
+		if _, err := regexp.Compile(p); err != nil {
+			return newValidationError(o, fmt.Sprintf("%s: %s", StrInvalidPattern, p))
+		}
+	}
+
+	return nil
+}
+
+func (o *grantTokenOpts) patterns() []string {
+	var p []string
+	for k := range o.ChannelsPattern {
+		p = append(p, k)
+	}
+	for k := range o.ChannelGroupsPattern {
+		p = append(p, k)
+	}
+	for k := range o.UsersPattern {
+		p = append(p, k)
+	}
+	for k := range o.SpacesPattern {
+		p = append(p, k)
+	}
+
+	return p
+}
+
+func (o *grantTokenOpts) buildPath() (string, error) {
+	return fmt.Sprintf(grantTokenPath, o.pubnub.Config.SubscribeKey), nil
+}
+
+func (o *grantTokenOpts) buildQuery() (*url.Values, error) {
+	q := defaultQuery(o.pubnub.Config.UUID, o.pubnub.telemetryManager)
+
+	SetQueryParam(q, o.QueryParam)
+
+	return q, nil
+}
+
+func (o *grantTokenOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
+}
+
+func (o *grantTokenOpts) buildBody() ([]byte, error) {
+	body := grantTokenBody{
+		TTL: o.TTL,
+		Permissions: grantTokenPermissions{
+			Resources: newGrantTokenResources(o.Channels, o.ChannelGroups, o.Users, o.Spaces),
+			Patterns:  newGrantTokenResources(o.ChannelsPattern, o.ChannelGroupsPattern, o.UsersPattern, o.SpacesPattern),
+			Meta:      o.Meta,
+		},
+	}
+
+	if body.Permissions.Meta == nil {
+		body.Permissions.Meta = make(map[string]interface{})
+	}
+
+	jsonEncBytes, errEnc := json.Marshal(body)
+	if errEnc != nil {
+		o.pubnub.Config.logger().Error("request body serialization failed", "error", errEnc)
+		return []byte{}, errEnc
+	}
+
+	return jsonEncBytes, nil
+}
+
+func newGrantTokenResources(channels map[string]ChannelPermissions, groups map[string]GroupPermissions,
+	users map[string]UserSpacePermissions, spaces map[string]UserSpacePermissions) grantTokenResources {
+	r := grantTokenResources{
+		Channels: make(map[string]int64, len(channels)),
+		Groups:   make(map[string]int64, len(groups)),
+		Users:    make(map[string]int64, len(users)),
+		Spaces:   make(map[string]int64, len(spaces)),
+	}
+
+	for k, v := range channels {
+		r.Channels[k] = v.bitmask()
+	}
+	for k, v := range groups {
+		r.Groups[k] = v.bitmask()
+	}
+	for k, v := range users {
+		r.Users[k] = v.bitmask()
+	}
+	for k, v := range spaces {
+		r.Spaces[k] = v.bitmask()
+	}
+
+	return r
+}
+
+func (o *grantTokenOpts) httpMethod() string {
+	return "POST"
+}
+
+func (o *grantTokenOpts) isAuthRequired() bool {
+	return true
+}
+
+func (o *grantTokenOpts) requestTimeout() int {
+	return o.pubnub.Config.NonSubscribeRequestTimeout
+}
+
+func (o *grantTokenOpts) connectTimeout() int {
+	return o.pubnub.Config.ConnectTimeout
+}
+
+func (o *grantTokenOpts) operationType() OperationType {
+	return PNAccessManagerGrantToken
+}
+
+func (o *grantTokenOpts) telemetryManager() *TelemetryManager {
+	return o.pubnub.telemetryManager
+}
+
+// PNGrantTokenData is the struct containing the token returned by the GrantToken request.
+type PNGrantTokenData struct {
+	Message string `json:"message"`
+	Token   string `json:"token"`
+}
+
+// PNGrantTokenResponse is the struct returned when the Execute function of GrantToken is called.
+type PNGrantTokenResponse struct {
+	Status  int              `json:"status"`
+	Data    PNGrantTokenData `json:"data"`
+	Service string           `json:"service"`
+}
+
+func newGrantTokenResponse(jsonBytes []byte, o *grantTokenOpts,
+	status StatusResponse) (*PNGrantTokenResponse, StatusResponse, error) {
+
+	resp := &PNGrantTokenResponse{}
+
+	err := json.Unmarshal(jsonBytes, &resp)
+	if err != nil {
+		e := pnerr.NewResponseParsingError("Error unmarshalling response",
+			ioutil.NopCloser(bytes.NewBufferString(string(jsonBytes))), err)
+
+		return emptyPNGrantTokenResponse, status, e
+	}
+
+	return resp, status, nil
+}
@@ -0,0 +1,150 @@
+package pubnub
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	h "github.com/sprucehealth/pubnub-go/tests/helpers"
+	"github.com/stretchr/testify/assert"
+)
+
+func AssertGrantToken(t *testing.T, checkQueryParam, testContext bool) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+
+	queryParam := map[string]string{
+		"q1": "v1",
+		"q2": "v2",
+	}
+
+	if !checkQueryParam {
+		queryParam = nil
+	}
+
+	o := newGrantTokenBuilder(pn)
+	if testContext {
+		o = newGrantTokenBuilderWithContext(pn, backgroundContext)
+	}
+
+	o.TTL(60)
+	o.Channels(map[string]ChannelPermissions{
+		"ch1": {Read: true, Write: true, Delete: true},
+	})
+	o.ChannelGroups(map[string]GroupPermissions{
+		"cg1": {Read: true, Manage: true},
+	})
+	o.Users(map[string]UserSpacePermissions{
+		"user1": {Read: true, Write: true, Manage: true, Delete: true, Create: true},
+	})
+	o.Spaces(map[string]UserSpacePermissions{
+		"space1": {Read: true, Write: true},
+	})
+	o.ChannelsPattern(map[string]ChannelPermissions{
+		"^ch-.*$": {Read: true},
+	})
+	o.Meta(map[string]interface{}{
+		"role": "client",
+	})
+	o.QueryParam(queryParam)
+
+	path, err := o.opts.buildPath()
+	assert.Nil(err)
+
+	h.AssertPathsEqual(t,
+		fmt.Sprintf("/v3/pam/%s/grant", pn.Config.SubscribeKey),
+		path, []int{})
+
+	body, err := o.opts.buildBody()
+	assert.Nil(err)
+
+	expectedBody := `{"ttl":60,"permissions":{"resources":{"channels":{"ch1":11},"groups":{"cg1":5},"users":{"user1":31},"spaces":{"space1":3}},"patterns":{"channels":{"^ch-.*$":1},"groups":{},"users":{},"spaces":{}},"meta":{"role":"client"}}}`
+
+	assert.Equal(expectedBody, string(body))
+	assert.Equal("POST", o.opts.httpMethod())
+
+	if checkQueryParam {
+		u, _ := o.opts.buildQuery()
+		assert.Equal("v1", u.Get("q1"))
+		assert.Equal("v2", u.Get("q2"))
+	}
+}
+
+func TestGrantToken(t *testing.T) {
+	AssertGrantToken(t, true, false)
+}
+
+func TestGrantTokenContext(t *testing.T) {
+	AssertGrantToken(t, true, true)
+}
+
+func TestGrantTokenValidate(t *testing.T) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+
+	o := newGrantTokenBuilder(pn)
+	assert.Contains(o.opts.validate().Error(), StrInvalidTTL)
+
+	o.TTL(60)
+	assert.Contains(o.opts.validate().Error(), StrMissingResources)
+
+	o.UsersPattern(map[string]UserSpacePermissions{
+		"user-[": {Read: true},
+	})
+	assert.Contains(o.opts.validate().Error(), StrInvalidPattern)
+
+	o.UsersPattern(map[string]UserSpacePermissions{
+		"^user-.*$": {Read: true},
+	})
+	assert.Nil(o.opts.validate())
+
+	pn.Config.SecretKey = ""
+	assert.Contains(o.opts.validate().Error(), StrMissingSecretKey)
+}
+
+func TestGrantTokenSignature(t *testing.T) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+
+	o := newGrantTokenBuilder(pn)
+	o.TTL(60)
+	o.Channels(map[string]ChannelPermissions{
+		"ch1": {Read: true},
+	})
+
+	u, err := buildURL(o.opts)
+	assert.Nil(err)
+
+	signature := u.Query().Get("signature")
+	assert.True(strings.HasPrefix(signature, "v2."), signature)
+	assert.False(strings.HasSuffix(signature, "="), signature)
+	assert.NotEmpty(u.Query().Get("timestamp"))
+}
+
+func TestGrantTokenResponseValueError(t *testing.T) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+	opts := &grantTokenOpts{
+		pubnub: pn,
+	}
+	jsonBytes := []byte(`s`)
+
+	_, _, err := newGrantTokenResponse(jsonBytes, opts, StatusResponse{})
+	assert.Equal("pubnub/parsing: Error unmarshalling response: {s}", err.Error())
+}
+
+func TestGrantTokenResponseValuePass(t *testing.T) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+	opts := &grantTokenOpts{
+		pubnub: pn,
+	}
+	jsonBytes := []byte(`{"status":200,"data":{"message":"Success","token":"p0F2AkF0Gl2CEiRDdHRsGQWgQ3Jlc6REY2hhbqBDZ3JwoEN1c3KgQ3NwY6BDcGF0pERjaGFuoENncnCgQ3VzcqBDc3BjoERtZXRhoENzaWdYIA"},"service":"Access Manager"}`)
+
+	r, _, err := newGrantTokenResponse(jsonBytes, opts, StatusResponse{})
+	assert.Nil(err)
+	assert.Equal(200, r.Status)
+	assert.Equal("Success", r.Data.Message)
+	assert.Equal("p0F2AkF0Gl2CEiRDdHRsGQWgQ3Jlc6REY2hhbqBDZ3JwoEN1c3KgQ3NwY6BDcGF0pERjaGFuoENncnCgQ3VzcqBDc3BjoERtZXRhoENzaWdYIA", r.Data.Token)
+	assert.Equal("Access Manager", r.Service)
+}
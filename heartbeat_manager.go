@@ -1,7 +1,6 @@
 package pubnub
 
 import (
-	"fmt"
 	"sync"
 	"time"
 )
@@ -45,20 +44,29 @@ func (m *HeartbeatManager) startHeartbeatTimer(runIndependentOfSubscribe bool) {
 	if hbRunning && !runIndependentOfSubscribe {
 		return
 	}
+	if !runIndependentOfSubscribe {
+		// the loop stopped meanwhile, before the heartbeats were marked as running
+		defer func() {
+			if !m.pubnub.subscriptionManager.connection.running() {
+				m.stopSubscribeHeartbeat()
+			}
+		}()
+	}
 	m.stopHeartbeat(runIndependentOfSubscribe, true)
 
 	m.Lock()
 	m.hbRunning = true
 	m.Unlock()
 
-	m.pubnub.Config.Log.Println("heartbeat: new timer", m.pubnub.Config.HeartbeatInterval)
+	m.pubnub.Config.logger().Debug("heartbeat: new timer", "interval", m.pubnub.Config.HeartbeatInterval)
 	if m.pubnub.Config.PresenceTimeout <= 0 && m.pubnub.Config.HeartbeatInterval <= 0 {
 		return
 	}
 
 	m.hbLoopMutex.Lock()
 	m.Lock()
-	m.hbDone = make(chan bool)
+	doneCh := make(chan bool)
+	m.hbDone = doneCh
 	m.hbTimer = time.NewTicker(time.Duration(m.pubnub.Config.HeartbeatInterval) * time.Second)
 	m.Unlock()
 
@@ -66,18 +74,20 @@ func (m *HeartbeatManager) startHeartbeatTimer(runIndependentOfSubscribe bool) {
 		m.performHeartbeatLoop()
 	}
 
-	go func() {
+	m.pubnub.routines.spawn(func() {
 		defer m.hbLoopMutex.Unlock()
 		defer func() {
 			m.Lock()
-			m.hbDone = nil
+			if m.hbDone == doneCh {
+				m.hbDone = nil
+			}
+			m.hbTimer.Stop()
 			m.Unlock()
 		}()
 
 		for {
 			m.RLock()
 			timerCh := m.hbTimer.C
-			doneCh := m.hbDone
 			m.RUnlock()
 
 			select {
@@ -92,7 +102,7 @@ func (m *HeartbeatManager) startHeartbeatTimer(runIndependentOfSubscribe bool) {
 
 					if reqSentAt > 0 {
 						timediff := int64(m.pubnub.Config.HeartbeatInterval) - (timeNow - reqSentAt)
-						m.pubnub.Config.Log.Println(fmt.Sprintf("heartbeat timediff: %d", timediff))
+						m.pubnub.Config.logger().Debug("heartbeat: time since last subscribe", "seconds", timediff)
 						m.pubnub.subscriptionManager.hbDataMutex.Lock()
 						m.pubnub.subscriptionManager.requestSentAt = 0
 						m.pubnub.subscriptionManager.hbDataMutex.Unlock()
@@ -101,9 +111,17 @@ func (m *HeartbeatManager) startHeartbeatTimer(runIndependentOfSubscribe bool) {
 							m.hbTimer.Stop()
 							m.Unlock()
 
-							m.pubnub.Config.Log.Println(fmt.Sprintf("heartbeat sleeping timediff: %d", timediff))
-							time.Sleep(time.Duration(timediff) * time.Second)
-							m.pubnub.Config.Log.Println("heartbeat sleep end")
+							m.pubnub.Config.logger().Debug("heartbeat: sleeping", "seconds", timediff)
+							select {
+							case <-time.After(time.Duration(timediff) * time.Second):
+							case <-doneCh:
+								m.pubnub.Config.logger().Debug("heartbeat: loop stopped while sleeping")
+								return
+							case <-m.ctx.Done():
+								m.pubnub.Config.logger().Debug("heartbeat: loop stopped by the PubNub context")
+								return
+							}
+							m.pubnub.Config.logger().Debug("heartbeat: sleep end")
 							m.Lock()
 							m.hbTimer = time.NewTicker(time.Duration(m.pubnub.Config.HeartbeatInterval) * time.Second)
 							m.Unlock()
@@ -112,11 +130,14 @@ func (m *HeartbeatManager) startHeartbeatTimer(runIndependentOfSubscribe bool) {
 					m.performHeartbeatLoop()
 				}
 			case <-doneCh:
-				m.pubnub.Config.Log.Println("heartbeat: loop after stop")
+				m.pubnub.Config.logger().Debug("heartbeat: loop after stop")
+				return
+			case <-m.ctx.Done():
+				m.pubnub.Config.logger().Debug("heartbeat: loop stopped by the PubNub context")
 				return
 			}
 		}
-	}()
+	})
 }
 
 func (m *HeartbeatManager) stopHeartbeat(runIndependentOfSubscribe bool, skipRuncheck bool) {
@@ -129,17 +150,18 @@ func (m *HeartbeatManager) stopHeartbeat(runIndependentOfSubscribe bool, skipRun
 			return
 		}
 	}
-	m.pubnub.Config.Log.Println("heartbeat: loop: stopping...")
+	m.pubnub.Config.logger().Debug("heartbeat: loop stopping")
 
 	m.Lock()
 	if m.hbTimer != nil {
 		m.hbTimer.Stop()
-		m.pubnub.Config.Log.Println("heartbeat: loop: timer stopped")
+		m.pubnub.Config.logger().Debug("heartbeat: loop timer stopped")
 	}
 
 	if m.hbDone != nil {
-		m.hbDone <- true
-		m.pubnub.Config.Log.Println("heartbeat: loop: done channel stopped")
+		close(m.hbDone)
+		m.hbDone = nil
+		m.pubnub.Config.logger().Debug("heartbeat: loop done channel closed")
 	}
 	m.hbRunning = false
 	m.Unlock()
@@ -148,6 +170,18 @@ func (m *HeartbeatManager) stopHeartbeat(runIndependentOfSubscribe bool, skipRun
 	m.pubnub.subscriptionManager.hbDataMutex.Unlock()
 }
 
+// stopSubscribeHeartbeat stops the heartbeats sent for the subscribed channels, the ones of the
+// channels set with Presence keep running.
+func (m *HeartbeatManager) stopSubscribeHeartbeat() {
+	m.RLock()
+	presence := len(m.heartbeatChannels) > 0 || len(m.heartbeatGroups) > 0
+	m.RUnlock()
+
+	if !presence {
+		m.stopHeartbeat(false, true)
+	}
+}
+
 func (m *HeartbeatManager) prepareList(subItem map[string]*SubscriptionItem) []string {
 	response := []string{}
 
@@ -165,22 +199,24 @@ func (m *HeartbeatManager) performHeartbeatLoop() error {
 	presenceGroups := m.prepareList(m.heartbeatGroups)
 	stateStorage = m.state
 	queryParam := m.queryParam
-	m.pubnub.Config.Log.Println("performHeartbeatLoop: count presenceChannels, presenceGroups", len(presenceChannels), len(presenceGroups))
+	m.pubnub.Config.logger().Debug("heartbeat: presence channels", "channels", len(presenceChannels), "groups", len(presenceGroups))
 	m.RUnlock()
 
 	if (len(presenceChannels) == 0) && (len(presenceGroups) == 0) {
-		m.pubnub.Config.Log.Println("performHeartbeatLoop: count presenceChannels, presenceGroups nil")
+		m.pubnub.Config.logger().Debug("heartbeat: no presence channels")
 		presenceChannels = m.pubnub.subscriptionManager.stateManager.prepareChannelList(false)
 		presenceGroups = m.pubnub.subscriptionManager.stateManager.prepareGroupList(false)
 		stateStorage = m.pubnub.subscriptionManager.stateManager.createStatePayload()
 		queryParam = nil
 
-		m.pubnub.Config.Log.Println("performHeartbeatLoop: count sub presenceChannels, presenceGroups", len(presenceChannels), len(presenceGroups))
+		m.pubnub.Config.logger().Debug("heartbeat: subscribed channels", "channels", len(presenceChannels), "groups", len(presenceGroups))
 	}
 
 	if len(presenceChannels) <= 0 && len(presenceGroups) <= 0 {
-		m.pubnub.Config.Log.Println("heartbeat: no channels left")
-		go m.stopHeartbeat(true, true)
+		m.pubnub.Config.logger().Debug("heartbeat: no channels left")
+		m.pubnub.routines.spawn(func() {
+			m.stopHeartbeat(true, true)
+		})
 		return nil
 	}
 
@@ -199,7 +235,7 @@ func (m *HeartbeatManager) performHeartbeatLoop() error {
 			Error:     true,
 			ErrorData: err,
 		}
-		m.pubnub.Config.Log.Println("performHeartbeatLoop: err", err, pnStatus)
+		m.pubnub.Config.logger().Error("heartbeat failed", "category", pnStatus.Category, "error", err)
 
 		m.pubnub.subscriptionManager.listenerManager.announceStatus(pnStatus)
 
@@ -212,7 +248,7 @@ func (m *HeartbeatManager) performHeartbeatLoop() error {
 		Operation:  PNHeartBeatOperation,
 		StatusCode: status.StatusCode,
 	}
-	m.pubnub.Config.Log.Println("performHeartbeatLoop: err", err, pnStatus)
+	m.pubnub.Config.logger().Debug("heartbeat sent", "category", pnStatus.Category)
 
 	m.pubnub.subscriptionManager.listenerManager.announceStatus(pnStatus)
 
@@ -104,6 +104,10 @@ func (o *heartbeatOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *heartbeatOpts) transport() http.RoundTripper {
+	return nil
+}
+
 func (o *heartbeatOpts) context() Context {
 	return o.ctx
 }
@@ -138,7 +142,16 @@ func (o *heartbeatOpts) buildQuery() (*url.Values, error) {
 	}
 
 	if o.State != nil {
-		state, err := utils.ValueAsString(o.State)
+		stateValue := o.State
+		if s, ok := stateValue.(map[string]interface{}); ok {
+			encState, err := encryptChannelsState(o.pubnub.Config, s)
+			if err != nil {
+				return &url.Values{}, err
+			}
+			stateValue = encState
+		}
+
+		state, err := utils.ValueAsString(stateValue)
 		if err != nil {
 			return &url.Values{}, err
 		}
@@ -147,13 +160,14 @@ func (o *heartbeatOpts) buildQuery() (*url.Values, error) {
 			q.Set("state", string(state))
 		}
 	}
+	o.pubnub.tokenManager.setChannelsAuthParam(q, o.Channels, o.ChannelGroups)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *heartbeatOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *heartbeatOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *heartbeatOpts) buildBody() ([]byte, error) {
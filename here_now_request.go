@@ -114,6 +114,10 @@ func (o *hereNowOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *hereNowOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *hereNowOpts) context() Context {
 	return o.ctx
 }
@@ -162,13 +166,14 @@ func (o *hereNowOpts) buildQuery() (*url.Values, error) {
 		q.Set("disable-uuids", "0")
 	}
 
+	o.pubnub.tokenManager.setChannelsAuthParam(q, o.Channels, o.ChannelGroups)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *hereNowOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *hereNowOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *hereNowOpts) buildBody() ([]byte, error) {
@@ -106,6 +106,10 @@ func (o *historyDeleteOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *historyDeleteOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *historyDeleteOpts) context() Context {
 	return o.ctx
 }
@@ -126,8 +130,8 @@ func (o *historyDeleteOpts) validate() error {
 	return nil
 }
 
-func (o *historyDeleteOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *historyDeleteOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *historyDeleteOpts) buildPath() (string, error) {
@@ -147,6 +151,7 @@ func (o *historyDeleteOpts) buildQuery() (*url.Values, error) {
 		q.Set("end", strconv.FormatInt(o.End, 10))
 	}
 
+	o.pubnub.tokenManager.setAuthParam(q, PNChannels, o.Channel)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
@@ -140,6 +140,10 @@ func (o *historyOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *historyOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *historyOpts) context() Context {
 	return o.ctx
 }
@@ -182,13 +186,14 @@ func (o *historyOpts) buildQuery() (*url.Values, error) {
 	q.Set("reverse", strconv.FormatBool(o.Reverse))
 	q.Set("include_token", strconv.FormatBool(o.IncludeTimetoken))
 
+	o.pubnub.tokenManager.setAuthParam(q, PNChannels, o.Channel)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *historyOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *historyOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *historyOpts) buildBody() ([]byte, error) {
@@ -233,7 +238,7 @@ type HistoryResponseItem struct {
 }
 
 func logAndCreateNewResponseParsingError(o *historyOpts, err error, jsonBody string, message string) *pnerr.ResponseParsingError {
-	o.pubnub.Config.Log.Println(err.Error())
+	o.pubnub.Config.logger().Error("history: response parsing failed", "error", err)
 	e := pnerr.NewResponseParsingError(message,
 		ioutil.NopCloser(bytes.NewBufferString(jsonBody)), err)
 	return e
@@ -251,7 +256,6 @@ func getHistoryItemsWithoutTimetoken(historyResponseRaw []byte, o *historyOpts,
 	items := make([]HistoryResponseItem, len(historyResponseItems))
 
 	for i, v := range historyResponseItems {
-		o.pubnub.Config.Log.Println(v)
 		items[i].Message, _ = parseCipherInterface(v, o.pubnub.Config)
 	}
 	return items, nil
@@ -264,10 +268,8 @@ func getHistoryItemsWithTimetoken(historyResponseItems []HistoryResponseItem, o
 
 	for i, v := range historyResponseItems {
 		if v.Message != nil {
-			o.pubnub.Config.Log.Println(v.Message)
 			items[i].Message, _ = parseCipherInterface(v.Message, o.pubnub.Config)
 
-			o.pubnub.Config.Log.Println(v.Timetoken)
 			items[i].Timetoken = v.Timetoken
 		} else {
 			b = true
@@ -297,9 +299,7 @@ func newHistoryResponse(jsonBytes []byte, o *historyOpts,
 	}
 
 	if historyResponseRaw != nil && len(historyResponseRaw) > 2 {
-		o.pubnub.Config.Log.Println("M", string(historyResponseRaw[0]))
-		o.pubnub.Config.Log.Println("T1", string(historyResponseRaw[1]))
-		o.pubnub.Config.Log.Println("T2", string(historyResponseRaw[2]))
+		o.pubnub.Config.logger().Debug("history: response", "body", string(jsonBytes))
 
 		var historyResponseItems []HistoryResponseItem
 		var items []HistoryResponseItem
@@ -307,7 +307,7 @@ func newHistoryResponse(jsonBytes []byte, o *historyOpts,
 		err1 := json.Unmarshal(historyResponseRaw[0], &historyResponseItems)
 		var e *pnerr.ResponseParsingError
 		if err1 != nil {
-			o.pubnub.Config.Log.Println(err1.Error())
+			o.pubnub.Config.logger().Debug("history: messages without timetokens", "error", err1)
 
 			items, e = getHistoryItemsWithoutTimetoken(historyResponseRaw[0], o, err1, jsonBytes)
 			if e != nil {
@@ -321,9 +321,9 @@ func newHistoryResponse(jsonBytes []byte, o *historyOpts,
 		}
 		if items != nil {
 			resp.Messages = items
-			o.pubnub.Config.Log.Printf("returning []interface, %v\n", items)
+			o.pubnub.Config.logger().Debug("history: messages parsed", "count", len(items))
 		} else {
-			o.pubnub.Config.Log.Println("items nil")
+			o.pubnub.Config.logger().Debug("history: no messages")
 		}
 
 		startTimetoken, err := strconv.ParseInt(string(historyResponseRaw[1]), 10, 64)
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	h "github.com/sprucehealth/pubnub-go/tests/helpers"
+	"github.com/sprucehealth/pubnub-go/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -375,6 +376,24 @@ func TestHistoryEncrypt(t *testing.T) {
 	pnconfig.CipherKey = ""
 }
 
+func TestHistoryEncryptRandomIV(t *testing.T) {
+	assert := assert.New(t)
+	pnconfig.CipherKey = "testCipher"
+	pubnub = NewPubNub(pnconfig)
+
+	encrypted, err := utils.EncryptStringWithRandomIV("testCipher", `"hey"`)
+	assert.Nil(err)
+
+	jsonString := []byte(fmt.Sprintf(`[["%s"],14991775432719844,14991868111600528]`, encrypted))
+
+	resp, _, err := newHistoryResponse(jsonString, initHistoryOpts(), fakeResponseState)
+	assert.Nil(err)
+
+	messages := resp.Messages
+	assert.Equal("hey", messages[0].Message)
+	pnconfig.CipherKey = ""
+}
+
 func TestHistoryEncryptSlice(t *testing.T) {
 	assert := assert.New(t)
 	pnconfig.CipherKey = "testCipher"
@@ -93,8 +93,8 @@ func (o *leaveOpts) buildPath() (string, error) {
 		channels), nil
 }
 
-func (o *leaveOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *leaveOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *leaveOpts) buildQuery() (*url.Values, error) {
@@ -104,6 +104,7 @@ func (o *leaveOpts) buildQuery() (*url.Values, error) {
 		channelGroup := utils.JoinChannels(o.ChannelGroups)
 		q.Set("channel-group", string(channelGroup))
 	}
+	o.pubnub.tokenManager.setChannelsAuthParam(q, o.Channels, o.ChannelGroups)
 	SetQueryParam(q, o.QueryParam)
 	return q, nil
 }
@@ -112,6 +113,10 @@ func (o *leaveOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *leaveOpts) transport() http.RoundTripper {
+	return nil
+}
+
 func (o *leaveOpts) config() Config {
 	return *o.pubnub.Config
 }
@@ -132,6 +137,10 @@ func (o *leaveOpts) validate() error {
 	return nil
 }
 
+func (o *leaveOpts) requestTimeout() int {
+	return o.pubnub.Config.NonSubscribeRequestTimeout
+}
+
 func (o *leaveOpts) operationType() OperationType {
 	return PNUnsubscribeOperation
 }
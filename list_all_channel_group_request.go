@@ -85,6 +85,10 @@ func (o *allChannelGroupOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *allChannelGroupOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *allChannelGroupOpts) context() Context {
 	return o.ctx
 }
@@ -109,12 +113,13 @@ func (o *allChannelGroupOpts) buildPath() (string, error) {
 
 func (o *allChannelGroupOpts) buildQuery() (*url.Values, error) {
 	q := defaultQuery(o.pubnub.Config.UUID, o.pubnub.telemetryManager)
+	o.pubnub.tokenManager.setAuthParam(q, PNGroups, o.ChannelGroup)
 	SetQueryParam(q, o.QueryParam)
 	return q, nil
 }
 
-func (o *allChannelGroupOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *allChannelGroupOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *allChannelGroupOpts) buildBody() ([]byte, error) {
@@ -119,6 +119,10 @@ func (o *listPushProvisionsRequestOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *listPushProvisionsRequestOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *listPushProvisionsRequestOpts) context() Context {
 	return o.ctx
 }
@@ -157,8 +161,8 @@ func (o *listPushProvisionsRequestOpts) buildQuery() (*url.Values, error) {
 	return q, nil
 }
 
-func (o *listPushProvisionsRequestOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *listPushProvisionsRequestOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *listPushProvisionsRequestOpts) buildBody() ([]byte, error) {
@@ -1,11 +1,22 @@
 package pubnub
 
 import (
+	"encoding/json"
 	"sync"
+	"sync/atomic"
 )
 
-//
+// Listener holds the channels the events of the subscriptions are sent to, or the EventHandler
+// they are passed to, see NewEventListener. The events are queued for each channel of the listener
+// and sent in the order they were received, a channel which isn't read doesn't hold up the others,
+// see NewListenerWithQueue. As each channel has its own queue, the events of different channels,
+// for ex. a status and the messages received around it, may be read in another order; the
+// EventHandler of a listener is called in the order they were received. The events of a channel
+// set to nil aren't queued.
 type Listener struct {
+	// dropped is first to be 64-bit aligned for the atomic operations.
+	dropped uint64
+
 	Status          chan *PNStatus
 	Message         chan *PNMessage
 	Presence        chan *PNPresence
@@ -13,8 +24,16 @@ type Listener struct {
 	UserEvent       chan *PNUserEvent
 	SpaceEvent      chan *PNSpaceEvent
 	MembershipEvent chan *PNMembershipEvent
+	MessageAction   chan *PNMessageActionsEvent
+
+	queueSize      int
+	overflowPolicy ListenerOverflowPolicy
+	handler        EventHandler
+	filter         func(event listenerEvent) bool
 }
 
+// NewListener returns a Listener whose queue is set by the ListenerQueueSize and the
+// ListenerOverflowPolicy of the Config.
 func NewListener() *Listener {
 	return &Listener{
 		Status:          make(chan *PNStatus),
@@ -24,170 +43,208 @@ func NewListener() *Listener {
 		UserEvent:       make(chan *PNUserEvent),
 		SpaceEvent:      make(chan *PNSpaceEvent),
 		MembershipEvent: make(chan *PNMembershipEvent),
+		MessageAction:   make(chan *PNMessageActionsEvent),
 	}
 }
 
+// NewListenerWithQueue returns a Listener which queues up to size events for each of its channels,
+// the policy is applied to the events received while the queue of their channel is full.
+func NewListenerWithQueue(size int, policy ListenerOverflowPolicy) *Listener {
+	l := NewListener()
+	l.queueSize = size
+	l.overflowPolicy = policy
+
+	return l
+}
+
+// hasChannel returns true if the channel of the events of the kind is set.
+func (l *Listener) hasChannel(kind listenerEventKind) bool {
+	switch kind {
+	case listenerStatusEvent:
+		return l.Status != nil
+	case listenerMessageEvent:
+		return l.Message != nil
+	case listenerPresenceEvent:
+		return l.Presence != nil
+	case listenerSignalEvent:
+		return l.Signal != nil
+	case listenerUserEvent:
+		return l.UserEvent != nil
+	case listenerSpaceEvent:
+		return l.SpaceEvent != nil
+	case listenerMembershipEvent:
+		return l.MembershipEvent != nil
+	case listenerMessageActionsEvent:
+		return l.MessageAction != nil
+	}
+
+	return false
+}
+
+// copy returns a listener sending the events to the same channels or handler, with the same queue.
+func (l *Listener) copy() *Listener {
+	return &Listener{
+		Status:          l.Status,
+		Message:         l.Message,
+		Presence:        l.Presence,
+		Signal:          l.Signal,
+		UserEvent:       l.UserEvent,
+		SpaceEvent:      l.SpaceEvent,
+		MembershipEvent: l.MembershipEvent,
+		MessageAction:   l.MessageAction,
+		queueSize:       l.queueSize,
+		overflowPolicy:  l.overflowPolicy,
+		handler:         l.handler,
+	}
+}
+
+// Dropped returns the number of events dropped as the queue of the listener was full.
+func (l *Listener) Dropped() uint64 {
+	return atomic.LoadUint64(&l.dropped)
+}
+
 type ListenerManager struct {
 	sync.RWMutex
-	ctx                  Context
-	listeners            map[*Listener]bool
-	exitListener         chan bool
-	exitListenerAnnounce chan bool
-	pubnub               *PubNub
+	ctx          Context
+	listeners    map[*Listener]bool
+	queues       map[*Listener]*listenerQueues
+	exitListener chan bool
+	pubnub       *PubNub
 }
 
 func newListenerManager(ctx Context, pn *PubNub) *ListenerManager {
 	return &ListenerManager{
-		listeners:            make(map[*Listener]bool, 2),
-		ctx:                  ctx,
-		exitListener:         make(chan bool),
-		exitListenerAnnounce: make(chan bool),
-		pubnub:               pn,
+		listeners:    make(map[*Listener]bool, 2),
+		queues:       make(map[*Listener]*listenerQueues, 2),
+		ctx:          ctx,
+		exitListener: make(chan bool),
+		pubnub:       pn,
 	}
 }
 
 func (m *ListenerManager) addListener(listener *Listener) {
 	m.Lock()
+	defer m.Unlock()
+
+	if m.listeners[listener] {
+		return
+	}
 
+	qs := newListenerQueues(listener, m.pubnub.Config)
 	m.listeners[listener] = true
+	m.queues[listener] = qs
+
+	exit := m.exitListener
+	for _, q := range qs.all {
+		q := q
+		m.pubnub.routines.spawn(func() {
+			q.run(exit)
+		})
+	}
+}
+
+// addFilteredListener adds the listener with its events filtered, and returns it. A copy of the
+// listener is added instead when it's already added or filtered, for its other uses to keep
+// receiving their events.
+func (m *ListenerManager) addFilteredListener(listener *Listener, filter func(event listenerEvent) bool) *Listener {
+	m.Lock()
+	if m.listeners[listener] || listener.filter != nil {
+		listener = listener.copy()
+	}
+	listener.filter = filter
 	m.Unlock()
+
+	m.addListener(listener)
+
+	return listener
 }
 
 func (m *ListenerManager) removeListener(listener *Listener) {
+	m.Lock()
+	defer m.Unlock()
+
+	if q, ok := m.queues[listener]; ok {
+		q.stop()
+		delete(m.queues, listener)
+	}
 	delete(m.listeners, listener)
 }
 
 func (m *ListenerManager) removeAllListeners() {
 	m.Lock()
-	m.pubnub.Config.Log.Println("in removeAllListeners")
-	for l := range m.listeners {
+	m.pubnub.Config.logger().Debug("removing all listeners")
+	for l, q := range m.queues {
+		q.stop()
+		delete(m.queues, l)
 		delete(m.listeners, l)
 	}
 	m.Unlock()
 }
 
-func (m *ListenerManager) announceStatus(status *PNStatus) {
-	go func() {
-		m.RLock()
-		m.pubnub.Config.Log.Println("announceStatus lock")
-	AnnounceStatusLabel:
-		for l := range m.listeners {
-			select {
-			case <-m.exitListener:
-				m.pubnub.Config.Log.Println("announceStatus exitListener")
-				break AnnounceStatusLabel
-			case l.Status <- status:
-			}
+func (m *ListenerManager) getListeners() map[*Listener]bool {
+	m.RLock()
+	defer m.RUnlock()
+
+	listeners := make(map[*Listener]bool, len(m.listeners))
+	for l := range m.listeners {
+		listeners[l] = true
+	}
+
+	return listeners
+}
+
+// announce queues the event for each listener. The lock is released before queueing
+// as the queues can block, for the listeners to be removed meanwhile.
+func (m *ListenerManager) announce(event listenerEvent) {
+	m.RLock()
+	queues := make([]*listenerQueue, 0, len(m.queues))
+	for l, qs := range m.queues {
+		if q := qs.queue(event.kind); q != nil && (l.filter == nil || l.filter(event)) {
+			queues = append(queues, q)
 		}
-		m.pubnub.Config.Log.Println("announceStatus unlock")
-		m.RUnlock()
-		m.pubnub.Config.Log.Println("announceStatus exit")
-	}()
+	}
+	m.RUnlock()
+
+	for _, q := range queues {
+		q.push(event)
+	}
+}
+
+func (m *ListenerManager) announceStatus(status *PNStatus) {
+	m.pubnub.Config.logger().Debug("announcing status", "category", status.Category)
+	m.announce(listenerEvent{kind: listenerStatusEvent, payload: status})
 }
 
 func (m *ListenerManager) announceMessage(message *PNMessage) {
-	go func() {
-		m.RLock()
-	AnnounceMessageLabel:
-		for l := range m.listeners {
-			select {
-			case <-m.exitListenerAnnounce:
-				m.pubnub.Config.Log.Println("announceMessage exitListenerAnnounce")
-				break AnnounceMessageLabel
-			case l.Message <- message:
-			}
-		}
-		m.RUnlock()
-	}()
+	m.announce(listenerEvent{kind: listenerMessageEvent, payload: message})
 }
 
 func (m *ListenerManager) announceSignal(message *PNMessage) {
-	go func() {
-		m.RLock()
-	AnnounceSignalLabel:
-		for l := range m.listeners {
-			select {
-			case <-m.exitListener:
-				m.pubnub.Config.Log.Println("announceSignal exitListener")
-				break AnnounceSignalLabel
-
-			case l.Signal <- message:
-			}
-		}
-		m.RUnlock()
-	}()
+	m.announce(listenerEvent{kind: listenerSignalEvent, payload: message})
 }
 
 func (m *ListenerManager) announceUserEvent(message *PNUserEvent) {
-	go func() {
-		m.RLock()
-	AnnounceUserEventLabel:
-		for l := range m.listeners {
-			select {
-			case <-m.exitListener:
-				m.pubnub.Config.Log.Println("announceUserEvent exitListener")
-				break AnnounceUserEventLabel
-
-			case l.UserEvent <- message:
-				m.pubnub.Config.Log.Println("l.UserEvent", message)
-			}
-		}
-		m.RUnlock()
-	}()
+	m.pubnub.Config.logger().Debug("announcing user event", "event", message.Event, "user_id", message.UserID)
+	m.announce(listenerEvent{kind: listenerUserEvent, payload: message})
 }
 
 func (m *ListenerManager) announceSpaceEvent(message *PNSpaceEvent) {
-	go func() {
-		m.RLock()
-	AnnounceSpaceEventLabel:
-		for l := range m.listeners {
-			m.pubnub.Config.Log.Println("l.SpaceEvent", l)
-			select {
-			case <-m.exitListener:
-				m.pubnub.Config.Log.Println("announceSpaceEvent exitListener")
-				break AnnounceSpaceEventLabel
-
-			case l.SpaceEvent <- message:
-				m.pubnub.Config.Log.Println("l.SpaceEvent", message)
-			}
-		}
-		m.RUnlock()
-	}()
+	m.pubnub.Config.logger().Debug("announcing space event", "event", message.Event, "space_id", message.SpaceID)
+	m.announce(listenerEvent{kind: listenerSpaceEvent, payload: message})
 }
 
 func (m *ListenerManager) announceMembershipEvent(message *PNMembershipEvent) {
-	go func() {
-		m.RLock()
-	AnnounceMembershipEvent:
-		for l := range m.listeners {
-			select {
-			case <-m.exitListener:
-				m.pubnub.Config.Log.Println("announceMembershipEvent exitListener")
-				break AnnounceMembershipEvent
-
-			case l.MembershipEvent <- message:
-				m.pubnub.Config.Log.Println("l.MembershipEvent", message)
-			}
-		}
-		m.RUnlock()
-	}()
+	m.pubnub.Config.logger().Debug("announcing membership event", "event", message.Event, "user_id", message.UserID, "space_id", message.SpaceID)
+	m.announce(listenerEvent{kind: listenerMembershipEvent, payload: message})
+}
+
+func (m *ListenerManager) announceMessageActionsEvent(message *PNMessageActionsEvent) {
+	m.pubnub.Config.logger().Debug("announcing message actions event", "event", message.Event, "channel", message.Channel)
+	m.announce(listenerEvent{kind: listenerMessageActionsEvent, payload: message})
 }
 
 func (m *ListenerManager) announcePresence(presence *PNPresence) {
-	go func() {
-		m.RLock()
-	AnnouncePresenceLabel:
-		for l := range m.listeners {
-			select {
-			case <-m.exitListener:
-				m.pubnub.Config.Log.Println("announcePresence exitListener")
-				break AnnouncePresenceLabel
-
-			case l.Presence <- presence:
-			}
-		}
-		m.RUnlock()
-	}()
+	m.announce(listenerEvent{kind: listenerPresenceEvent, payload: presence})
 }
 
 // PNStatus is the status struct
@@ -206,6 +263,17 @@ type PNStatus struct {
 	AffectedChannelGroups []string
 }
 
+// PNPublishMetadata is the metadata of a publish received by Subscribe.
+type PNPublishMetadata struct {
+	Shard                string
+	Flags                int
+	SubscribeKey         string
+	SequenceNumber       int64 // The seqn of the publish, incremented by each publish of the publisher.
+	Region               int   // The region of the publish timetoken.
+	OriginationTimetoken int64 // The timetoken of the message in the region it was published in, for the replicated messages.
+	OriginationRegion    int
+}
+
 // PNMessage is the Message Response for Subscribe
 type PNMessage struct {
 	Message           interface{}
@@ -216,6 +284,14 @@ type PNMessage struct {
 	Subscription      string
 	Publisher         string
 	Timetoken         int64
+	Replayed          bool              // True for the messages fetched after a reconnection, see Config.CatchUpOnReconnect.
+	PublishMetadata   PNPublishMetadata // Only the SubscribeKey is known for the replayed messages.
+	Raw               json.RawMessage   // The JSON of the Message, decrypted.
+}
+
+// Decode unmarshals the JSON of the message into v.
+func (m *PNMessage) Decode(v interface{}) error {
+	return decodeRaw(m.Raw, m.Message, v)
 }
 
 // PNPresence is the Message Response for Presence
@@ -235,6 +311,30 @@ type PNPresence struct {
 	Leave             []string
 	Timeout           []string
 	HereNowRefresh    bool
+	Occupants         []HereNowOccupantsData // The occupants of the channel, for the PNHereNowEvent events only.
+	PublishMetadata   PNPublishMetadata
+	Raw               json.RawMessage // The JSON of the presence event.
+}
+
+// PNHereNowEvent is the Event of the PNPresence announced with the occupants returned by HereNow,
+// after an interval presence event with HereNowRefresh set, see Config.RefreshHereNowOnInterval.
+const PNHereNowEvent = "here-now"
+
+// Decode unmarshals the JSON of the presence event into v.
+func (p *PNPresence) Decode(v interface{}) error {
+	return decodeRaw(p.Raw, nil, v)
+}
+
+// decodeRaw unmarshals the raw JSON into v, or the value encoded when there is no JSON.
+func decodeRaw(raw json.RawMessage, value interface{}, v interface{}) error {
+	if raw == nil {
+		var err error
+		if raw, err = json.Marshal(value); err != nil {
+			return err
+		}
+	}
+
+	return json.Unmarshal(raw, v)
 }
 
 // PNUserEvent is the Response for an User Event
@@ -287,3 +387,13 @@ type PNMembershipEvent struct {
 	Channel           string
 	Subscription      string
 }
+
+// PNMessageActionsEvent is the Response for a Message Actions Event
+type PNMessageActionsEvent struct {
+	Event             PNMessageActionsEventType
+	Data              PNMessageActionsResponse
+	SubscribedChannel string
+	ActualChannel     string
+	Channel           string
+	Subscription      string
+}
@@ -0,0 +1,359 @@
+package pubnub
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type listenerEventKind int
+
+const (
+	listenerStatusEvent listenerEventKind = iota
+	listenerMessageEvent
+	listenerPresenceEvent
+	listenerSignalEvent
+	listenerUserEvent
+	listenerSpaceEvent
+	listenerMembershipEvent
+	listenerMessageActionsEvent
+)
+
+const listenerEventKinds = int(listenerMessageActionsEvent) + 1
+
+type listenerEvent struct {
+	kind    listenerEventKind
+	payload interface{}
+}
+
+// listenerQueue holds the events of a listener until they are read from its channels,
+// in the order they were announced. The queue is bounded, the overflow policy decides
+// what is done with the events announced while it is full.
+type listenerQueue struct {
+	sync.Mutex
+
+	listener *Listener
+	logger   Logger
+	size     int
+	policy   ListenerOverflowPolicy
+	events   []listenerEvent
+
+	// status is the queue the status of an overflow is queued on, the queue itself unless the
+	// listener has a queue for each event kind, nil when the listener has no Status channel.
+	status *listenerQueue
+	// overflowQueued is set while the status of an overflow is queued, to emit a single
+	// status for the events dropped until the listener reads it.
+	overflowQueued bool
+
+	ready    chan struct{}
+	space    chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newListenerQueue(listener *Listener, config *Config) *listenerQueue {
+	size := listener.queueSize
+	if size <= 0 {
+		size = config.ListenerQueueSize
+	}
+	if size <= 0 {
+		size = 1
+	}
+
+	policy := listener.overflowPolicy
+	if policy == 0 {
+		policy = config.ListenerOverflowPolicy
+	}
+
+	q := &listenerQueue{
+		listener: listener,
+		logger:   config.logger(),
+		size:     size,
+		policy:   policy,
+		ready:    make(chan struct{}, 1),
+		space:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	q.status = q
+
+	return q
+}
+
+// listenerQueues are the queues of a listener. A listener reading channels has a queue for each
+// of its channels, for a channel which isn't read not to hold up the events of the others, the
+// events of the kinds whose channel is nil aren't queued. A listener with an EventHandler has a
+// single queue, its handler is called with the events in the order they were announced.
+type listenerQueues struct {
+	byKind [listenerEventKinds]*listenerQueue
+	all    []*listenerQueue
+}
+
+func newListenerQueues(listener *Listener, config *Config) *listenerQueues {
+	qs := &listenerQueues{}
+
+	if listener.handler != nil {
+		q := newListenerQueue(listener, config)
+		for kind := range qs.byKind {
+			qs.byKind[kind] = q
+		}
+		qs.all = append(qs.all, q)
+
+		return qs
+	}
+
+	for kind := range qs.byKind {
+		if listener.hasChannel(listenerEventKind(kind)) {
+			q := newListenerQueue(listener, config)
+			qs.byKind[kind] = q
+			qs.all = append(qs.all, q)
+		}
+	}
+	for _, q := range qs.all {
+		q.status = qs.byKind[listenerStatusEvent]
+	}
+
+	return qs
+}
+
+// queue returns the queue of the events of the kind, nil if they aren't delivered to the listener.
+func (qs *listenerQueues) queue(kind listenerEventKind) *listenerQueue {
+	return qs.byKind[kind]
+}
+
+func (qs *listenerQueues) stop() {
+	for _, q := range qs.all {
+		q.stop()
+	}
+}
+
+func notify(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+// push queues the event, it waits for the listener to read the queued events when the queue
+// is full and the policy is PNOverflowBlock. With the other policies, the events dropped are
+// reported by a PNListenerQueueOverflowCategory status.
+func (q *listenerQueue) push(event listenerEvent) {
+	q.Lock()
+
+	for q.full() {
+		switch q.policy {
+		case PNOverflowDropOldest:
+			q.dropOldest()
+			q.Unlock()
+			q.overflowed()
+			q.Lock()
+		case PNOverflowDropNewest, PNOverflowEmitStatus:
+			atomic.AddUint64(&q.listener.dropped, 1)
+			q.Unlock()
+			q.overflowed()
+			return
+		default:
+			q.Unlock()
+			select {
+			case <-q.space:
+			case <-q.done:
+				return
+			}
+			q.Lock()
+		}
+	}
+
+	q.events = append(q.events, event)
+	q.Unlock()
+
+	notify(q.ready)
+}
+
+// full returns true if the queue holds its size of events, the queued overflow status aside.
+// The queue is locked by the caller.
+func (q *listenerQueue) full() bool {
+	n := len(q.events)
+	if q.overflowQueued {
+		n--
+	}
+
+	return n >= q.size
+}
+
+// dropOldest drops the oldest queued event but the overflow status, the queue is locked by the caller.
+func (q *listenerQueue) dropOldest() {
+	i := 0
+	if q.overflowQueued && isOverflowStatus(q.events[0]) {
+		i = 1
+	}
+
+	copy(q.events[i:], q.events[i+1:])
+	q.events[len(q.events)-1] = listenerEvent{}
+	q.events = q.events[:len(q.events)-1]
+	atomic.AddUint64(&q.listener.dropped, 1)
+}
+
+// overflowed queues the overflow status on the status queue, unless the listener has no Status channel.
+func (q *listenerQueue) overflowed() {
+	status := q.status
+	if status == nil {
+		return
+	}
+
+	status.Lock()
+	status.queueOverflowStatus()
+	status.Unlock()
+
+	notify(status.ready)
+}
+
+// queueOverflowStatus queues the status of an overflow unless one is already queued, the queue
+// is locked by the caller. The status is queued after the events already queued, even when the
+// queue is full.
+func (q *listenerQueue) queueOverflowStatus() {
+	if q.overflowQueued {
+		return
+	}
+
+	q.overflowQueued = true
+	q.events = append(q.events, listenerEvent{
+		kind: listenerStatusEvent,
+		payload: &PNStatus{
+			Category:  PNListenerQueueOverflowCategory,
+			Operation: PNSubscribeOperation,
+			Error:     true,
+		},
+	})
+}
+
+func isOverflowStatus(event listenerEvent) bool {
+	status, ok := event.payload.(*PNStatus)
+	return ok && status.Category == PNListenerQueueOverflowCategory
+}
+
+// pop returns the oldest queued event, it waits for an event to be queued when the queue is empty.
+// false is returned once the queue is stopped or the exit channel is closed.
+func (q *listenerQueue) pop(exit <-chan bool) (listenerEvent, bool) {
+	for {
+		q.Lock()
+		if len(q.events) > 0 {
+			event := q.events[0]
+			q.events[0] = listenerEvent{}
+			q.events = q.events[1:]
+
+			if isOverflowStatus(event) {
+				q.overflowQueued = false
+			}
+			q.Unlock()
+
+			notify(q.space)
+			return event, true
+		}
+		q.Unlock()
+
+		select {
+		case <-q.ready:
+		case <-q.done:
+			return listenerEvent{}, false
+		case <-exit:
+			return listenerEvent{}, false
+		}
+	}
+}
+
+// run sends the queued events to the channels of the listener until the queue is stopped.
+func (q *listenerQueue) run(exit <-chan bool) {
+	for {
+		event, ok := q.pop(exit)
+		if !ok {
+			return
+		}
+
+		if !q.deliver(event, exit) {
+			return
+		}
+	}
+}
+
+func (q *listenerQueue) deliver(event listenerEvent, exit <-chan bool) bool {
+	l := q.listener
+
+	if l.handler != nil {
+		q.handle(event)
+		return true
+	}
+
+	switch event.kind {
+	case listenerStatusEvent:
+		select {
+		case l.Status <- event.payload.(*PNStatus):
+			return true
+		case <-q.done:
+		case <-exit:
+		}
+	case listenerMessageEvent:
+		select {
+		case l.Message <- event.payload.(*PNMessage):
+			return true
+		case <-q.done:
+		case <-exit:
+		}
+	case listenerPresenceEvent:
+		select {
+		case l.Presence <- event.payload.(*PNPresence):
+			return true
+		case <-q.done:
+		case <-exit:
+		}
+	case listenerSignalEvent:
+		select {
+		case l.Signal <- event.payload.(*PNMessage):
+			return true
+		case <-q.done:
+		case <-exit:
+		}
+	case listenerUserEvent:
+		select {
+		case l.UserEvent <- event.payload.(*PNUserEvent):
+			return true
+		case <-q.done:
+		case <-exit:
+		}
+	case listenerSpaceEvent:
+		select {
+		case l.SpaceEvent <- event.payload.(*PNSpaceEvent):
+			return true
+		case <-q.done:
+		case <-exit:
+		}
+	case listenerMembershipEvent:
+		select {
+		case l.MembershipEvent <- event.payload.(*PNMembershipEvent):
+			return true
+		case <-q.done:
+		case <-exit:
+		}
+	case listenerMessageActionsEvent:
+		select {
+		case l.MessageAction <- event.payload.(*PNMessageActionsEvent):
+			return true
+		case <-q.done:
+		case <-exit:
+		}
+	}
+
+	return false
+}
+
+// queued returns the number of queued events.
+func (q *listenerQueue) queued() int {
+	q.Lock()
+	defer q.Unlock()
+
+	return len(q.events)
+}
+
+// stop drops the queued events and stops the delivery to the listener.
+func (q *listenerQueue) stop() {
+	q.stopOnce.Do(func() {
+		close(q.done)
+	})
+}
@@ -0,0 +1,332 @@
+package pubnub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func messageEvent(i int) listenerEvent {
+	return listenerEvent{kind: listenerMessageEvent, payload: &PNMessage{Message: i}}
+}
+
+func popMessages(t *testing.T, q *listenerQueue, count int) []interface{} {
+	var messages []interface{}
+
+	for i := 0; i < count; i++ {
+		event, ok := q.pop(nil)
+		if !ok {
+			t.Fatal("queue stopped")
+		}
+
+		switch p := event.payload.(type) {
+		case *PNMessage:
+			messages = append(messages, p.Message)
+		case *PNStatus:
+			messages = append(messages, p.Category)
+		}
+	}
+
+	return messages
+}
+
+func TestListenerQueueDropOldest(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewListenerWithQueue(3, PNOverflowDropOldest)
+	q := newListenerQueue(l, NewConfig())
+
+	for i := 0; i < 5; i++ {
+		q.push(messageEvent(i))
+	}
+
+	assert.Equal(uint64(2), l.Dropped())
+	assert.Equal([]interface{}{2, PNListenerQueueOverflowCategory, 3, 4}, popMessages(t, q, 4))
+	assert.Equal(0, q.queued())
+}
+
+func TestListenerQueueDropNewest(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewListenerWithQueue(3, PNOverflowDropNewest)
+	q := newListenerQueue(l, NewConfig())
+
+	for i := 0; i < 5; i++ {
+		q.push(messageEvent(i))
+	}
+
+	assert.Equal(uint64(2), l.Dropped())
+	assert.Equal([]interface{}{0, 1, 2, PNListenerQueueOverflowCategory}, popMessages(t, q, 4))
+	assert.Equal(0, q.queued())
+}
+
+func TestListenerQueueEmitStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewListenerWithQueue(2, PNOverflowEmitStatus)
+	q := newListenerQueue(l, NewConfig())
+
+	for i := 0; i < 5; i++ {
+		q.push(messageEvent(i))
+	}
+
+	assert.Equal(uint64(3), l.Dropped())
+	assert.Equal([]interface{}{0, 1, PNListenerQueueOverflowCategory}, popMessages(t, q, 3))
+
+	q.push(messageEvent(5))
+	q.push(messageEvent(6))
+	q.push(messageEvent(7))
+
+	assert.Equal(uint64(4), l.Dropped())
+	assert.Equal([]interface{}{5, 6, PNListenerQueueOverflowCategory}, popMessages(t, q, 3))
+}
+
+func TestListenerQueueBlock(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewListenerWithQueue(1, PNOverflowBlock)
+	q := newListenerQueue(l, NewConfig())
+	q.push(messageEvent(0))
+
+	pushed := make(chan bool)
+	go func() {
+		q.push(messageEvent(1))
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("push did not block on a full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.Equal([]interface{}{0}, popMessages(t, q, 1))
+	<-pushed
+	assert.Equal([]interface{}{1}, popMessages(t, q, 1))
+	assert.Equal(uint64(0), l.Dropped())
+}
+
+func TestListenerQueueStopUnblocksPush(t *testing.T) {
+	l := NewListenerWithQueue(1, PNOverflowBlock)
+	q := newListenerQueue(l, NewConfig())
+	q.push(messageEvent(0))
+
+	pushed := make(chan bool)
+	go func() {
+		q.push(messageEvent(1))
+		close(pushed)
+	}()
+
+	q.stop()
+
+	select {
+	case <-pushed:
+	case <-time.After(time.Second):
+		t.Fatal("push still blocked after stop")
+	}
+
+	_, ok := q.pop(nil)
+	assert.True(t, ok)
+	_, ok = q.pop(nil)
+	assert.False(t, ok)
+}
+
+func TestListenerQueueConfigDefaults(t *testing.T) {
+	assert := assert.New(t)
+
+	config := NewConfig()
+	config.ListenerQueueSize = 7
+	config.ListenerOverflowPolicy = PNOverflowDropNewest
+
+	q := newListenerQueue(NewListener(), config)
+	assert.Equal(7, q.size)
+	assert.Equal(PNOverflowDropNewest, q.policy)
+
+	q = newListenerQueue(NewListenerWithQueue(2, PNOverflowEmitStatus), config)
+	assert.Equal(2, q.size)
+	assert.Equal(PNOverflowEmitStatus, q.policy)
+
+	q = newListenerQueue(NewListener(), NewConfig())
+	assert.Equal(PNOverflowBlock, q.policy)
+}
+
+func TestListenerManagerOrderedDelivery(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+
+	l := NewListenerWithQueue(100, PNOverflowBlock)
+	pn.AddListener(l)
+
+	const count = 500
+	go func() {
+		for i := 0; i < count; i++ {
+			pn.subscriptionManager.listenerManager.announceMessage(&PNMessage{Message: i})
+			if i%100 == 0 {
+				pn.subscriptionManager.listenerManager.announceStatus(&PNStatus{Category: PNConnectedCategory})
+			}
+		}
+	}()
+
+	for i := 0; i < count; {
+		select {
+		case m := <-l.Message:
+			assert.Equal(i, m.Message)
+			i++
+		case <-l.Status:
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the messages")
+		}
+	}
+	assert.Equal(uint64(0), l.Dropped())
+}
+
+func TestListenerManagerUnreadStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+
+	l := NewListenerWithQueue(100, PNOverflowDropOldest)
+	pn.AddListener(l)
+
+	// the Status channel isn't read, the statuses beyond the size of its queue are dropped
+	const count = 50
+	announced := make(chan bool)
+	go func() {
+		lm := pn.subscriptionManager.listenerManager
+		for i := 0; i < count; i++ {
+			lm.announceStatus(&PNStatus{Category: PNConnectedCategory})
+			lm.announceStatus(&PNStatus{Category: PNConnectedCategory})
+			lm.announceStatus(&PNStatus{Category: PNConnectedCategory})
+			lm.announceMessage(&PNMessage{Message: i})
+		}
+		close(announced)
+	}()
+
+	for i := 0; i < count; i++ {
+		select {
+		case m := <-l.Message:
+			assert.Equal(i, m.Message)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for message %d", i)
+		}
+	}
+
+	select {
+	case <-announced:
+	case <-time.After(time.Second):
+		t.Fatal("announcement blocked by the unread Status channel")
+	}
+	// one of the statuses may be held by the delivery instead of its queue
+	dropped := l.Dropped()
+	assert.True(dropped == count*3-100 || dropped == count*3-101, "dropped %d", dropped)
+}
+
+func TestListenerManagerNilChannel(t *testing.T) {
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+
+	l := NewListenerWithQueue(1, PNOverflowBlock)
+	l.Status = nil
+	pn.AddListener(l)
+
+	lm := pn.subscriptionManager.listenerManager
+	lm.announceStatus(&PNStatus{Category: PNConnectedCategory})
+	lm.announceStatus(&PNStatus{Category: PNConnectedCategory})
+	lm.announceMessage(&PNMessage{Message: 1})
+
+	select {
+	case m := <-l.Message:
+		assert.Equal(t, 1, m.Message)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the message")
+	}
+}
+
+func TestListenerManagerEmitStatusOnStatusChannel(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+
+	l := NewListenerWithQueue(2, PNOverflowEmitStatus)
+	pn.AddListener(l)
+
+	// the first message is held by the delivery to the unread Message channel
+	lm := pn.subscriptionManager.listenerManager
+	for i := 0; i < 5; i++ {
+		lm.announceMessage(&PNMessage{Message: i})
+	}
+
+	select {
+	case s := <-l.Status:
+		assert.Equal(PNListenerQueueOverflowCategory, s.Category)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the overflow status")
+	}
+	assert.True(l.Dropped() > 0)
+}
+
+func TestListenerManagerDropOldestStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+
+	l := NewListenerWithQueue(2, PNOverflowDropOldest)
+	pn.AddListener(l)
+
+	lm := pn.subscriptionManager.listenerManager
+	for i := 0; i < 5; i++ {
+		lm.announceMessage(&PNMessage{Message: i})
+	}
+
+	select {
+	case s := <-l.Status:
+		assert.Equal(PNListenerQueueOverflowCategory, s.Category)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the overflow status")
+	}
+	assert.True(l.Dropped() > 0)
+
+	// the newest messages were kept
+	for {
+		select {
+		case m := <-l.Message:
+			if m.Message == 4 {
+				return
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the last message")
+		}
+	}
+}
+
+func TestListenerManagerRemoveBlockedListener(t *testing.T) {
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+
+	l := NewListenerWithQueue(1, PNOverflowBlock)
+	pn.AddListener(l)
+
+	announced := make(chan bool)
+	go func() {
+		for i := 0; i < 5; i++ {
+			pn.subscriptionManager.listenerManager.announceMessage(&PNMessage{Message: i})
+		}
+		close(announced)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	pn.RemoveListener(l)
+
+	select {
+	case <-announced:
+	case <-time.After(time.Second):
+		t.Fatal("announcement blocked after the listener was removed")
+	}
+	assert.Empty(t, pn.GetListeners())
+}
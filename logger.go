@@ -0,0 +1,210 @@
+package pubnub
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// LogLevel is the min severity of the entries written by NewStdLogger.
+type LogLevel int
+
+const (
+	// PNLogDebug logs the requests and the steps of the subscribe and heartbeat loops.
+	PNLogDebug LogLevel = 1 + iota
+	// PNLogInfo logs the lifecycle of the PubNub instance.
+	PNLogInfo
+	// PNLogWarn logs the retries, the reconnections and the adjusted config values.
+	PNLogWarn
+	// PNLogError logs the failed requests and the responses which can not be parsed.
+	PNLogError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case PNLogDebug:
+		return "DEBUG"
+	case PNLogInfo:
+		return "INFO"
+	case PNLogWarn:
+		return "WARN"
+	case PNLogError:
+		return "ERROR"
+	}
+
+	return fmt.Sprintf("LogLevel(%d)", int(l))
+}
+
+// Logger is the structured logger of the SDK. The keyvals alternate the keys, which are strings,
+// and the values of the fields of the entry.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+// redactedValue replaces the values of the sensitive fields.
+const redactedValue = "[REDACTED]"
+
+// redactedKeys are the fields which are never logged unless Config.DisableLogRedaction is set.
+var redactedKeys = map[string]bool{
+	"auth":         true,
+	"body":         true,
+	"cipher_key":   true,
+	"message":      true,
+	"meta":         true,
+	"payload":      true,
+	"secret_key":   true,
+	"signature":    true,
+	"signed_input": true,
+	"state":        true,
+	"token":        true,
+}
+
+var (
+	redactQueryRegexp   = regexp.MustCompile(`\b(auth|signature|token|state|meta)=[^&\s"]*`)
+	redactMessageRegexp = regexp.MustCompile(`(/(?:publish|signal)/[^/\s"]*/[^/\s"]*/0/[^/\s"]*/[^/\s"]*/)[^?\s"]*`)
+)
+
+// redactString removes the credentials and the published messages from the URLs in the v.
+func redactString(v string) string {
+	v = redactQueryRegexp.ReplaceAllString(v, "$1="+redactedValue)
+	return redactMessageRegexp.ReplaceAllString(v, "${1}"+redactedValue)
+}
+
+// redactField returns the value of the field as it can be logged.
+func redactField(key string, value interface{}) interface{} {
+	if redactedKeys[key] {
+		return redactedValue
+	}
+
+	switch v := value.(type) {
+	case nil, bool, int, int8, int64, uint, float64, time.Duration, LogLevel, StatusCategory, []string:
+		return v
+	case string:
+		return redactString(v)
+	}
+
+	return redactString(fmt.Sprint(value))
+}
+
+// redactingLogger redacts the fields of the entries before they reach the Logger of the Config.
+type redactingLogger struct {
+	logger   Logger
+	disabled bool
+}
+
+func (r redactingLogger) fields(keyvals []interface{}) []interface{} {
+	if r.disabled {
+		return keyvals
+	}
+
+	redacted := make([]interface{}, len(keyvals))
+	for i := 0; i < len(keyvals); i += 2 {
+		key, _ := keyvals[i].(string)
+		redacted[i] = keyvals[i]
+
+		if i+1 < len(keyvals) {
+			redacted[i+1] = redactField(key, keyvals[i+1])
+		}
+	}
+
+	return redacted
+}
+
+func (r redactingLogger) Debug(msg string, keyvals ...interface{}) {
+	r.logger.Debug(msg, r.fields(keyvals)...)
+}
+
+func (r redactingLogger) Info(msg string, keyvals ...interface{}) {
+	r.logger.Info(msg, r.fields(keyvals)...)
+}
+
+func (r redactingLogger) Warn(msg string, keyvals ...interface{}) {
+	r.logger.Warn(msg, r.fields(keyvals)...)
+}
+
+func (r redactingLogger) Error(msg string, keyvals ...interface{}) {
+	r.logger.Error(msg, r.fields(keyvals)...)
+}
+
+// nopLogger is the Logger of the Config which does not set one.
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keyvals ...interface{}) {}
+func (nopLogger) Info(msg string, keyvals ...interface{})  {}
+func (nopLogger) Warn(msg string, keyvals ...interface{})  {}
+func (nopLogger) Error(msg string, keyvals ...interface{}) {}
+
+// stdLoggerCallDepth skips the stdLogger and the redactingLogger frames, for log.Lshortfile
+// to report the caller in the SDK.
+const stdLoggerCallDepth = 4
+
+type stdLogger struct {
+	logger *log.Logger
+	level  LogLevel
+}
+
+// NewStdLogger returns a Logger which writes the entries of the level and above to the l,
+// as a line of the form `LEVEL msg key=value key=value`.
+func NewStdLogger(l *log.Logger, level LogLevel) Logger {
+	return &stdLogger{
+		logger: l,
+		level:  level,
+	}
+}
+
+func (s *stdLogger) output(level LogLevel, msg string, keyvals []interface{}) {
+	if level < s.level {
+		return
+	}
+
+	var b bytes.Buffer
+	b.WriteString(level.String())
+	b.WriteByte(' ')
+	b.WriteString(msg)
+
+	for i := 0; i < len(keyvals); i += 2 {
+		b.WriteByte(' ')
+		b.WriteString(fmt.Sprint(keyvals[i]))
+		b.WriteByte('=')
+
+		if i+1 < len(keyvals) {
+			b.WriteString(formatLogValue(keyvals[i+1]))
+		} else {
+			b.WriteString("MISSING")
+		}
+	}
+
+	s.logger.Output(stdLoggerCallDepth, b.String())
+}
+
+// formatLogValue quotes the values which would not be read back as a single field.
+func formatLogValue(value interface{}) string {
+	v := fmt.Sprint(value)
+	if v == "" || strings.ContainsAny(v, " =\"\n\t") {
+		return fmt.Sprintf("%q", v)
+	}
+
+	return v
+}
+
+func (s *stdLogger) Debug(msg string, keyvals ...interface{}) {
+	s.output(PNLogDebug, msg, keyvals)
+}
+
+func (s *stdLogger) Info(msg string, keyvals ...interface{}) {
+	s.output(PNLogInfo, msg, keyvals)
+}
+
+func (s *stdLogger) Warn(msg string, keyvals ...interface{}) {
+	s.output(PNLogWarn, msg, keyvals)
+}
+
+func (s *stdLogger) Error(msg string, keyvals ...interface{}) {
+	s.output(PNLogError, msg, keyvals)
+}
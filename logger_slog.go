@@ -0,0 +1,36 @@
+//go:build go1.21
+// +build go1.21
+
+package pubnub
+
+import (
+	"log/slog"
+)
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger returns a Logger which writes the entries to the l, the keyvals becoming the attributes
+// of the records. The level of the entries is filtered by the handler of the l.
+func NewSlogLogger(l *slog.Logger) Logger {
+	return &slogLogger{
+		logger: l,
+	}
+}
+
+func (s *slogLogger) Debug(msg string, keyvals ...interface{}) {
+	s.logger.Debug(msg, keyvals...)
+}
+
+func (s *slogLogger) Info(msg string, keyvals ...interface{}) {
+	s.logger.Info(msg, keyvals...)
+}
+
+func (s *slogLogger) Warn(msg string, keyvals ...interface{}) {
+	s.logger.Warn(msg, keyvals...)
+}
+
+func (s *slogLogger) Error(msg string, keyvals ...interface{}) {
+	s.logger.Error(msg, keyvals...)
+}
@@ -0,0 +1,35 @@
+//go:build go1.21
+// +build go1.21
+
+package pubnub
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogLogger(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	config := NewConfig()
+	config.Log = NewSlogLogger(slog.New(handler))
+
+	config.logger().Debug("skipped")
+	config.logger().Warn("retrying request", "attempt", 2, "url", "https://ps.pndsn.com/time/0?auth=secret")
+
+	assert.Equal("level=WARN msg=\"retrying request\" attempt=2 url=\"https://ps.pndsn.com/time/0?auth=[REDACTED]\"\n", buf.String())
+}
@@ -0,0 +1,117 @@
+package pubnub
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdLoggerFormat(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	logger := NewStdLogger(log.New(&buf, "", 0), PNLogDebug)
+
+	logger.Info("request sent", "operation", "Publish", "status_code", 200, "error", "not found", "empty", "")
+	logger.Warn("odd fields", "attempt")
+
+	assert.Equal("INFO request sent operation=Publish status_code=200 error=\"not found\" empty=\"\"\n"+
+		"WARN odd fields attempt=MISSING\n", buf.String())
+}
+
+func TestStdLoggerLevel(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	logger := NewStdLogger(log.New(&buf, "", 0), PNLogWarn)
+
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+
+	assert.Equal("WARN warn\nERROR error\n", buf.String())
+}
+
+func TestLogLevelString(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("DEBUG", PNLogDebug.String())
+	assert.Equal("ERROR", PNLogError.String())
+	assert.Equal("LogLevel(9)", LogLevel(9).String())
+}
+
+func TestRedactingLogger(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	config := NewConfig()
+	config.Log = NewStdLogger(log.New(&buf, "", 0), PNLogDebug)
+
+	config.logger().Debug("sending request",
+		"url", "https://ps.pndsn.com/publish/pub/sub/0/ch/0/%22hello%22?auth=secret&pnsdk=go&signature=abc&uuid=u",
+		"message", "hello",
+		"cipher_key", "enigma",
+		"channels", []string{"ch"})
+
+	out := buf.String()
+	assert.NotContains(out, "hello")
+	assert.NotContains(out, "secret")
+	assert.NotContains(out, "abc")
+	assert.NotContains(out, "enigma")
+	assert.Contains(out, "/publish/pub/sub/0/ch/0/[REDACTED]?auth=[REDACTED]&pnsdk=go&signature=[REDACTED]&uuid=u")
+	assert.Contains(out, "channels=[ch]")
+}
+
+func TestRedactingLoggerDisabled(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	config := NewConfig()
+	config.Log = NewStdLogger(log.New(&buf, "", 0), PNLogDebug)
+	config.DisableLogRedaction = true
+
+	config.logger().Debug("sending request", "url", "https://ps.pndsn.com/time/0?auth=secret", "message", "hello")
+
+	assert.Equal("DEBUG sending request url=\"https://ps.pndsn.com/time/0?auth=secret\" message=hello\n", buf.String())
+}
+
+func TestConfigLoggerDefault(t *testing.T) {
+	config := NewConfig()
+
+	assert.NotPanics(t, func() {
+		config.logger().Error("failed", "error", "boom")
+	})
+}
+
+func TestPublishLogsRedacted(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	config := NewConfig()
+	config.PublishKey = "pub"
+	config.SubscribeKey = "sub"
+	config.AuthKey = "my-auth-key"
+	config.Log = NewStdLogger(log.New(&buf, "", 0), PNLogDebug)
+
+	pn := NewPubNub(config)
+	pn.SetClient(&http.Client{
+		Transport: &sequenceTransport{
+			responses: []func(req *http.Request) (*http.Response, error){
+				respondWith(200, `[1,"Sent","15000000000000000"]`, nil),
+			},
+		},
+	})
+
+	_, _, err := pn.Publish().Channel("ch").Message("top secret").Execute()
+	assert.Nil(err)
+
+	out := buf.String()
+	assert.True(strings.Contains(out, "DEBUG sending request operation=Publish"), out)
+	assert.NotContains(out, "top")
+	assert.NotContains(out, "my-auth-key")
+}
@@ -0,0 +1,24 @@
+package pubnub
+
+// MessageAction is the input struct used in the Add Message Action request.
+type MessageAction struct {
+	ActionType  string `json:"type"`
+	ActionValue string `json:"value"`
+}
+
+// PNMessageActionsResponse is the Message Actions API Response struct of a single action.
+type PNMessageActionsResponse struct {
+	ActionType       string `json:"type"`
+	ActionValue      string `json:"value"`
+	ActionTimetoken  string `json:"actionTimetoken"`
+	MessageTimetoken string `json:"messageTimetoken"`
+	UUID             string `json:"uuid"`
+}
+
+// PNGetMessageActionsMore is the struct used when the Get Message Actions response has more results.
+type PNGetMessageActionsMore struct {
+	URL   string `json:"url"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Limit int    `json:"limit"`
+}
@@ -111,6 +111,10 @@ func (o *messageCountsOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *messageCountsOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *messageCountsOpts) context() Context {
 	return o.ctx
 }
@@ -158,13 +162,14 @@ func (o *messageCountsOpts) buildQuery() (*url.Values, error) {
 		q.Set("channelsTimetoken", "")
 	}
 
+	o.pubnub.tokenManager.setAuthParam(q, PNChannels, o.Channels...)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *messageCountsOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *messageCountsOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *messageCountsOpts) buildBody() ([]byte, error) {
@@ -218,7 +223,6 @@ func newMessageCountsResponse(jsonBytes []byte, o *messageCountsOpts,
 	}
 
 	if result, ok := value.(map[string]interface{}); ok {
-		o.pubnub.Config.Log.Println(result["channels"])
 		if channels, ok1 := result["channels"].(map[string]interface{}); ok1 {
 			if channels != nil {
 				resp.Channels = make(map[string]int)
@@ -226,13 +230,13 @@ func newMessageCountsResponse(jsonBytes []byte, o *messageCountsOpts,
 					resp.Channels[ch] = int(v.(float64))
 				}
 			} else {
-				o.pubnub.Config.Log.Printf("type assertion to map failed %v\n", result)
+				o.pubnub.Config.logger().Error("message counts: channels are not an object", "body", string(jsonBytes))
 			}
 		} else {
-			o.pubnub.Config.Log.Println("Assertion failed", reflect.TypeOf(result["channels"]))
+			o.pubnub.Config.logger().Error("message counts: unexpected channels type", "type", reflect.TypeOf(result["channels"]))
 		}
 	} else {
-		o.pubnub.Config.Log.Printf("type assertion to map failed %v\n", value)
+		o.pubnub.Config.logger().Error("message counts: response is not an object", "body", string(jsonBytes))
 	}
 
 	return resp, status, nil
@@ -128,6 +128,10 @@ func (o *createSpaceOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *createSpaceOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *createSpaceOpts) context() Context {
 	return o.ctx
 }
@@ -152,13 +156,14 @@ func (o *createSpaceOpts) buildQuery() (*url.Values, error) {
 	if o.Include != nil {
 		q.Set("include", string(utils.JoinChannels(o.Include)))
 	}
+	o.pubnub.tokenManager.setAuthParam(q, PNSpaces, o.ID)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *createSpaceOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *createSpaceOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *createSpaceOpts) buildBody() ([]byte, error) {
@@ -172,7 +177,7 @@ func (o *createSpaceOpts) buildBody() ([]byte, error) {
 	jsonEncBytes, errEnc := json.Marshal(b)
 
 	if errEnc != nil {
-		o.pubnub.Config.Log.Printf("ERROR: Serialization error: %s\n", errEnc.Error())
+		o.pubnub.Config.logger().Error("request body serialization failed", "error", errEnc)
 		return []byte{}, errEnc
 	}
 	return jsonEncBytes, nil
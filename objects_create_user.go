@@ -144,6 +144,10 @@ func (o *createUserOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *createUserOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *createUserOpts) context() Context {
 	return o.ctx
 }
@@ -169,13 +173,14 @@ func (o *createUserOpts) buildQuery() (*url.Values, error) {
 		q.Set("include", string(utils.JoinChannels(o.Include)))
 	}
 
+	o.pubnub.tokenManager.setAuthParam(q, PNUsers, o.ID)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *createUserOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *createUserOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *createUserOpts) buildBody() ([]byte, error) {
@@ -191,7 +196,7 @@ func (o *createUserOpts) buildBody() ([]byte, error) {
 	jsonEncBytes, errEnc := json.Marshal(b)
 
 	if errEnc != nil {
-		o.pubnub.Config.Log.Printf("ERROR: Serialization error: %s\n", errEnc.Error())
+		o.pubnub.Config.logger().Error("request body serialization failed", "error", errEnc)
 		return []byte{}, errEnc
 	}
 	return jsonEncBytes, nil
@@ -87,6 +87,10 @@ func (o *deleteSpaceOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *deleteSpaceOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *deleteSpaceOpts) context() Context {
 	return o.ctx
 }
@@ -108,13 +112,14 @@ func (o *deleteSpaceOpts) buildQuery() (*url.Values, error) {
 
 	q := defaultQuery(o.pubnub.Config.UUID, o.pubnub.telemetryManager)
 
+	o.pubnub.tokenManager.setAuthParam(q, PNSpaces, o.ID)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *deleteSpaceOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *deleteSpaceOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *deleteSpaceOpts) buildBody() ([]byte, error) {
@@ -88,6 +88,10 @@ func (o *deleteUserOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *deleteUserOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *deleteUserOpts) context() Context {
 	return o.ctx
 }
@@ -109,13 +113,14 @@ func (o *deleteUserOpts) buildQuery() (*url.Values, error) {
 
 	q := defaultQuery(o.pubnub.Config.UUID, o.pubnub.telemetryManager)
 
+	o.pubnub.tokenManager.setAuthParam(q, PNUsers, o.ID)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *deleteUserOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *deleteUserOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *deleteUserOpts) buildBody() ([]byte, error) {
@@ -131,6 +131,10 @@ func (o *getMembersOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *getMembersOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *getMembersOpts) context() Context {
 	return o.ctx
 }
@@ -172,13 +176,14 @@ func (o *getMembersOpts) buildQuery() (*url.Values, error) {
 		q.Set("end", o.End)
 	}
 
+	o.pubnub.tokenManager.setAuthParam(q, PNSpaces, o.ID)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *getMembersOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *getMembersOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *getMembersOpts) buildBody() ([]byte, error) {
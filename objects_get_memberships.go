@@ -131,6 +131,10 @@ func (o *getMembershipsOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *getMembershipsOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *getMembershipsOpts) context() Context {
 	return o.ctx
 }
@@ -172,13 +176,14 @@ func (o *getMembershipsOpts) buildQuery() (*url.Values, error) {
 		q.Set("end", o.End)
 	}
 
+	o.pubnub.tokenManager.setAuthParam(q, PNUsers, o.ID)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *getMembershipsOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *getMembershipsOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *getMembershipsOpts) buildBody() ([]byte, error) {
@@ -97,6 +97,10 @@ func (o *getSpaceOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *getSpaceOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *getSpaceOpts) context() Context {
 	return o.ctx
 }
@@ -122,13 +126,14 @@ func (o *getSpaceOpts) buildQuery() (*url.Values, error) {
 		q.Set("include", string(utils.JoinChannels(o.Include)))
 	}
 
+	o.pubnub.tokenManager.setAuthParam(q, PNSpaces, o.ID)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *getSpaceOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *getSpaceOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *getSpaceOpts) buildBody() ([]byte, error) {
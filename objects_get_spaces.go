@@ -125,6 +125,10 @@ func (o *getSpacesOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *getSpacesOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *getSpacesOpts) context() Context {
 	return o.ctx
 }
@@ -166,13 +170,14 @@ func (o *getSpacesOpts) buildQuery() (*url.Values, error) {
 		q.Set("end", o.End)
 	}
 
+	o.pubnub.tokenManager.setAuthParam(q, PNSpaces)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *getSpacesOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *getSpacesOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *getSpacesOpts) buildBody() ([]byte, error) {
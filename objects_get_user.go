@@ -97,6 +97,10 @@ func (o *getUserOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *getUserOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *getUserOpts) context() Context {
 	return o.ctx
 }
@@ -122,13 +126,14 @@ func (o *getUserOpts) buildQuery() (*url.Values, error) {
 		q.Set("include", string(utils.JoinChannels(o.Include)))
 	}
 
+	o.pubnub.tokenManager.setAuthParam(q, PNUsers, o.ID)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *getUserOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *getUserOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *getUserOpts) buildBody() ([]byte, error) {
@@ -125,6 +125,10 @@ func (o *getUsersOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *getUsersOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *getUsersOpts) context() Context {
 	return o.ctx
 }
@@ -166,13 +170,14 @@ func (o *getUsersOpts) buildQuery() (*url.Values, error) {
 		q.Set("end", o.End)
 	}
 
+	o.pubnub.tokenManager.setAuthParam(q, PNUsers)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *getUsersOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *getUsersOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *getUsersOpts) buildBody() ([]byte, error) {
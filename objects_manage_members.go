@@ -152,6 +152,10 @@ func (o *manageMembersOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *manageMembersOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *manageMembersOpts) context() Context {
 	return o.ctx
 }
@@ -193,13 +197,14 @@ func (o *manageMembersOpts) buildQuery() (*url.Values, error) {
 		q.Set("end", o.End)
 	}
 
+	o.pubnub.tokenManager.setAuthParam(q, PNSpaces, o.SpaceID)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *manageMembersOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *manageMembersOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 // PNMembersInputChangeSet is the Objects API input to add, remove or update members
@@ -219,7 +224,7 @@ func (o *manageMembersOpts) buildBody() ([]byte, error) {
 	jsonEncBytes, errEnc := json.Marshal(b)
 
 	if errEnc != nil {
-		o.pubnub.Config.Log.Printf("ERROR: Serialization error: %s\n", errEnc.Error())
+		o.pubnub.Config.logger().Error("request body serialization failed", "error", errEnc)
 		return []byte{}, errEnc
 	}
 	return jsonEncBytes, nil
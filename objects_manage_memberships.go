@@ -152,6 +152,10 @@ func (o *manageMembershipsOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *manageMembershipsOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *manageMembershipsOpts) context() Context {
 	return o.ctx
 }
@@ -192,13 +196,14 @@ func (o *manageMembershipsOpts) buildQuery() (*url.Values, error) {
 		q.Set("end", o.End)
 	}
 
+	o.pubnub.tokenManager.setAuthParam(q, PNUsers, o.UserID)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *manageMembershipsOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *manageMembershipsOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 // PNMembershipsInputChangeSet is the Objects API input to add, remove or update membership
@@ -218,7 +223,7 @@ func (o *manageMembershipsOpts) buildBody() ([]byte, error) {
 	jsonEncBytes, errEnc := json.Marshal(b)
 
 	if errEnc != nil {
-		o.pubnub.Config.Log.Printf("ERROR: Serialization error: %s\n", errEnc.Error())
+		o.pubnub.Config.logger().Error("request body serialization failed", "error", errEnc)
 		return []byte{}, errEnc
 	}
 	return jsonEncBytes, nil
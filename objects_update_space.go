@@ -128,6 +128,10 @@ func (o *updateSpaceOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *updateSpaceOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *updateSpaceOpts) context() Context {
 	return o.ctx
 }
@@ -153,13 +157,14 @@ func (o *updateSpaceOpts) buildQuery() (*url.Values, error) {
 		q.Set("include", string(utils.JoinChannels(o.Include)))
 	}
 
+	o.pubnub.tokenManager.setAuthParam(q, PNSpaces, o.ID)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *updateSpaceOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *updateSpaceOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *updateSpaceOpts) buildBody() ([]byte, error) {
@@ -173,7 +178,7 @@ func (o *updateSpaceOpts) buildBody() ([]byte, error) {
 	jsonEncBytes, errEnc := json.Marshal(b)
 
 	if errEnc != nil {
-		o.pubnub.Config.Log.Printf("ERROR: Serialization error: %s\n", errEnc.Error())
+		o.pubnub.Config.logger().Error("request body serialization failed", "error", errEnc)
 		return []byte{}, errEnc
 	}
 	return jsonEncBytes, nil
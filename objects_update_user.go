@@ -144,6 +144,10 @@ func (o *updateUserOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *updateUserOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *updateUserOpts) context() Context {
 	return o.ctx
 }
@@ -168,13 +172,14 @@ func (o *updateUserOpts) buildQuery() (*url.Values, error) {
 	if o.Include != nil {
 		q.Set("include", string(utils.JoinChannels(o.Include)))
 	}
+	o.pubnub.tokenManager.setAuthParam(q, PNUsers, o.ID)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *updateUserOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *updateUserOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *updateUserOpts) buildBody() ([]byte, error) {
@@ -190,7 +195,7 @@ func (o *updateUserOpts) buildBody() ([]byte, error) {
 	jsonEncBytes, errEnc := json.Marshal(b)
 
 	if errEnc != nil {
-		o.pubnub.Config.Log.Printf("ERROR: Serialization error: %s\n", errEnc.Error())
+		o.pubnub.Config.logger().Error("request body serialization failed", "error", errEnc)
 		return []byte{}, errEnc
 	}
 	return jsonEncBytes, nil
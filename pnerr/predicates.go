@@ -0,0 +1,103 @@
+package pnerr
+
+import (
+	"context"
+	"net"
+	"net/url"
+)
+
+// origErrer is implemented by the errors which wrap another error.
+type origErrer interface {
+	OrigErr() error
+}
+
+// unwrapper is implemented by the errors wrapped with fmt.Errorf("%w") since go1.13.
+type unwrapper interface {
+	Unwrap() error
+}
+
+// causes returns the err and the errors it wraps, outermost first.
+func causes(err error) []error {
+	var errs []error
+
+	for err != nil {
+		errs = append(errs, err)
+
+		switch e := err.(type) {
+		case origErrer:
+			err = e.OrigErr()
+		case unwrapper:
+			err = e.Unwrap()
+		case *url.Error:
+			err = e.Err
+		default:
+			err = nil
+		}
+	}
+
+	return errs
+}
+
+// AsServerError returns the ServerError in the err chain, or nil.
+func AsServerError(err error) *ServerError {
+	for _, e := range causes(err) {
+		switch se := e.(type) {
+		case *ServerError:
+			return se
+		case ServerError:
+			return &se
+		}
+	}
+
+	return nil
+}
+
+// StatusCode returns the status code of the ServerError in the err chain, or 0.
+func StatusCode(err error) int {
+	if se := AsServerError(err); se != nil {
+		return se.StatusCode
+	}
+
+	return 0
+}
+
+// IsAccessDenied returns true if the request was denied by Access Manager (403).
+func IsAccessDenied(err error) bool {
+	return StatusCode(err) == 403
+}
+
+// IsBadRequest returns true if the request was rejected as malformed (400).
+func IsBadRequest(err error) bool {
+	return StatusCode(err) == 400
+}
+
+// IsRateLimited returns true if the request was rejected as too many requests were sent (429).
+func IsRateLimited(err error) bool {
+	return StatusCode(err) == 429
+}
+
+// IsTimeout returns true if the request timed out, either on the client or on the server (408).
+func IsTimeout(err error) bool {
+	if StatusCode(err) == 408 {
+		return true
+	}
+
+	for _, e := range causes(err) {
+		if ne, ok := e.(net.Error); ok && ne.Timeout() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsCancelled returns true if the request was cancelled by its context.
+func IsCancelled(err error) bool {
+	for _, e := range causes(err) {
+		if e == context.Canceled {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,107 @@
+package pnerr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type timeoutError struct{}
+
+func (e timeoutError) Error() string   { return "i/o timeout" }
+func (e timeoutError) Timeout() bool   { return true }
+func (e timeoutError) Temporary() bool { return true }
+
+// wrappedError wraps an error with another message, like fmt.Errorf("%w") does.
+type wrappedError struct {
+	msg string
+	err error
+}
+
+func (e wrappedError) Error() string { return e.msg }
+func (e wrappedError) Unwrap() error { return e.err }
+
+func newTestServerError(statusCode int, body string) *ServerError {
+	return NewServerError(statusCode, ioutil.NopCloser(bytes.NewBufferString(body)))
+}
+
+func TestServerErrorServicePayload(t *testing.T) {
+	assert := assert.New(t)
+
+	e := newTestServerError(403, `{"message":"Forbidden","payload":{"channels":["ch1","ch2"],"channel-groups":["cg1"]},"error":true,"service":"Access Manager","status":403}`)
+
+	assert.Equal(403, e.StatusCode)
+	assert.Equal("Forbidden", e.Message)
+	assert.Equal("Access Manager", e.Service)
+	assert.Equal([]string{"ch1", "ch2"}, e.Channels)
+	assert.Equal([]string{"cg1"}, e.ChannelGroups)
+}
+
+func TestServerErrorObjectsPayload(t *testing.T) {
+	assert := assert.New(t)
+
+	e := newTestServerError(409, `{"status":409,"error":{"message":"User with the ID already exists.","source":"objects"}}`)
+
+	assert.Equal("User with the ID already exists.", e.Message)
+	assert.Empty(e.Service)
+}
+
+func TestServerErrorNotJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	e := newTestServerError(502, "<html>Bad Gateway</html>")
+
+	assert.Equal([]byte("<html>Bad Gateway</html>"), e.Body)
+	assert.Empty(e.Message)
+	assert.Nil(e.Channels)
+}
+
+func TestPredicates(t *testing.T) {
+	assert := assert.New(t)
+
+	denied := newTestServerError(403, `{"message":"Forbidden"}`)
+	assert.True(IsAccessDenied(denied))
+	assert.True(IsAccessDenied(*denied))
+	assert.False(IsRateLimited(denied))
+	assert.False(IsTimeout(denied))
+	assert.Equal(403, StatusCode(denied))
+
+	assert.True(IsRateLimited(newTestServerError(429, "")))
+	assert.True(IsBadRequest(newTestServerError(400, "")))
+	assert.True(IsTimeout(newTestServerError(408, "")))
+
+	assert.Equal(0, StatusCode(errors.New("403 Forbidden")))
+	assert.False(IsAccessDenied(errors.New("403 Forbidden")))
+	assert.Nil(AsServerError(nil))
+}
+
+func TestPredicatesWrapped(t *testing.T) {
+	assert := assert.New(t)
+
+	timeout := NewConnectionError("Failed to execute request",
+		&url.Error{Op: "Get", URL: "https://ps.pndsn.com", Err: timeoutError{}})
+	assert.True(IsTimeout(timeout))
+	assert.False(IsCancelled(timeout))
+
+	cancelled := NewConnectionError("Failed to execute request",
+		&url.Error{Op: "Get", URL: "https://ps.pndsn.com", Err: context.Canceled})
+	assert.True(IsCancelled(cancelled))
+	assert.False(IsTimeout(cancelled))
+
+	// the cancellation is matched whatever the messages of the errors wrapping it
+	assert.True(IsCancelled(NewConnectionError("aborted", wrappedError{"subscribe stopped", context.Canceled})))
+	assert.False(IsCancelled(errors.New("context canceled")))
+	assert.False(IsCancelled(context.DeadlineExceeded))
+
+	parsing := NewResponseParsingError("Error", nil, newTestServerError(403, ""))
+	assert.True(IsAccessDenied(parsing))
+
+	// the error message alone does not make it a ServerError
+	assert.False(IsAccessDenied(fmt.Errorf("wrapped: %s", newTestServerError(403, ""))))
+}
@@ -1,9 +1,11 @@
 package pnerr
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
 )
 
 // Error validating type or value of passed in params.
@@ -51,9 +53,23 @@ func NewBuildRequestError(msg string) *BuildRequestError {
 // Server response has error code, for ex.:
 // - BadRequest (400) - wrong params generated by SDK
 // - Access Denied (403) - insufficient PAM permissions
+// - Too Many Requests (429) - rate limited
+//
+// The fields of the PubNub service error payload are set when the body is JSON.
 type ServerError struct {
 	StatusCode int
 	Body       []byte
+
+	// Message is the error message returned by the service.
+	Message string
+	// Service is the name of the service which returned the error, for ex. "Access Manager".
+	Service string
+	// Channels and ChannelGroups are the resources the error applies to, for ex. the
+	// channels which are not granted on an Access Denied error.
+	Channels      []string
+	ChannelGroups []string
+	// Header is the header of the response, for ex. with the Retry-After of a 429.
+	Header http.Header
 }
 
 func (e ServerError) Error() string {
@@ -65,10 +81,46 @@ func (e ServerError) Error() string {
 func NewServerError(statusCode int, body io.ReadCloser) *ServerError {
 	bodyString, _ := ioutil.ReadAll(body)
 
-	return &ServerError{
+	e := &ServerError{
 		StatusCode: statusCode,
 		Body:       bodyString,
 	}
+	e.parseServiceError()
+
+	return e
+}
+
+// serviceError is the JSON error envelope of the PubNub services, the error
+// is either a bool or an object with the message.
+type serviceError struct {
+	Message string          `json:"message"`
+	Service string          `json:"service"`
+	Error   json.RawMessage `json:"error"`
+	Payload struct {
+		Channels      []string `json:"channels"`
+		ChannelGroups []string `json:"channel-groups"`
+	} `json:"payload"`
+}
+
+func (e *ServerError) parseServiceError() {
+	var se serviceError
+	if err := json.Unmarshal(e.Body, &se); err != nil {
+		return
+	}
+
+	e.Message = se.Message
+	e.Service = se.Service
+	e.Channels = se.Payload.Channels
+	e.ChannelGroups = se.Payload.ChannelGroups
+
+	if e.Message == "" && len(se.Error) > 0 {
+		var detail struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(se.Error, &detail); err == nil {
+			e.Message = detail.Message
+		}
+	}
 }
 
 // Something wrong with network connection.
@@ -82,6 +134,10 @@ func (e ConnectionError) Error() string {
 		e.OrigError.Error())
 }
 
+func (e ConnectionError) OrigErr() error {
+	return e.OrigError
+}
+
 func NewConnectionError(msg string, origError error) *ConnectionError {
 	return &ConnectionError{
 		message:   msg,
@@ -100,6 +156,10 @@ func (e ResponseParsingError) Error() string {
 	return fmt.Sprintf("pubnub/parsing: %s: %s", e.message, e.Body)
 }
 
+func (e ResponseParsingError) OrigErr() error {
+	return e.OrigError
+}
+
 func NewResponseParsingError(msg string,
 	body io.ReadCloser, origError error) *ResponseParsingError {
 
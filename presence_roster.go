@@ -0,0 +1,308 @@
+package pubnub
+
+import (
+	"sort"
+	"sync"
+)
+
+// PresenceRosterChange is passed to the callbacks of PresenceRoster.OnChange.
+type PresenceRosterChange struct {
+	Channel string
+	UUID    string
+	// Event is "join", "leave", "timeout" or "state-change". The joins and leaves found by
+	// Sync are "join" and "leave".
+	Event string
+	State map[string]interface{}
+}
+
+// PresenceRoster keeps the occupants of channels and their state. It's bootstrapped with HereNow
+// by Sync, then kept up to date by the presence events of the channels, which must be subscribed
+// with presence, and synchronized again after a reconnection.
+type PresenceRoster struct {
+	sync.RWMutex
+
+	pubnub   *PubNub
+	listener *Listener
+	channels map[string]map[string]map[string]interface{}
+	onChange []func(PresenceRosterChange)
+}
+
+// NewPresenceRoster creates a roster of the channels, and adds its listener to the pn.
+// Sync is to be called to bootstrap it, and Close to remove the listener.
+func NewPresenceRoster(pn *PubNub, channels []string) *PresenceRoster {
+	r := &PresenceRoster{
+		pubnub:   pn,
+		channels: make(map[string]map[string]map[string]interface{}, len(channels)),
+	}
+	for _, ch := range channels {
+		r.channels[ch] = make(map[string]map[string]interface{})
+	}
+
+	r.listener = NewEventListener(ListenerFuncs{
+		Presence: r.applyPresence,
+		Status: func(s *PNStatus) {
+			if s.Category == PNReconnectedCategory {
+				pn.routines.spawn(r.resync)
+			}
+		},
+	})
+	pn.AddListener(r.listener)
+
+	return r
+}
+
+// OnChange adds a callback called for each change of the roster, from the goroutine delivering
+// the presence events or calling Sync.
+func (r *PresenceRoster) OnChange(callback func(PresenceRosterChange)) {
+	r.Lock()
+	r.onChange = append(r.onChange, callback)
+	r.Unlock()
+}
+
+// Sync replaces the occupants of the channels with the ones returned by HereNow.
+func (r *PresenceRoster) Sync() error {
+	return r.SyncWithContext(r.pubnub.ctx)
+}
+
+// SyncWithContext is Sync with a context for the HereNow request.
+func (r *PresenceRoster) SyncWithContext(ctx Context) error {
+	channels := r.Channels()
+	res, _, err := r.pubnub.HereNowWithContext(ctx).
+		Channels(channels).
+		IncludeUUIDs(true).
+		IncludeState(true).
+		Execute()
+	if err != nil {
+		return err
+	}
+
+	// the channels without occupants are omitted from the response of several channels
+	occupants := make(map[string][]HereNowOccupantsData, len(res.Channels))
+	for _, ch := range res.Channels {
+		occupants[ch.ChannelName] = ch.Occupants
+	}
+	for _, ch := range channels {
+		r.replace(ch, occupants[ch])
+	}
+
+	return nil
+}
+
+func (r *PresenceRoster) resync() {
+	if err := r.Sync(); err != nil {
+		r.pubnub.Config.logger().Error("presence roster sync failed", "error", err)
+	}
+}
+
+// Close removes the listener of the roster, it's not updated anymore.
+func (r *PresenceRoster) Close() {
+	r.pubnub.RemoveListener(r.listener)
+}
+
+// Channels returns the channels of the roster.
+func (r *PresenceRoster) Channels() []string {
+	r.RLock()
+	defer r.RUnlock()
+
+	channels := make([]string, 0, len(r.channels))
+	for ch := range r.channels {
+		channels = append(channels, ch)
+	}
+	sort.Strings(channels)
+
+	return channels
+}
+
+// Occupants returns the occupants of the channel and their state, sorted by UUID.
+func (r *PresenceRoster) Occupants(channel string) []HereNowOccupantsData {
+	r.RLock()
+	defer r.RUnlock()
+
+	return r.occupantsLocked(channel)
+}
+
+// Occupancy returns the number of occupants of the channel.
+func (r *PresenceRoster) Occupancy(channel string) int {
+	r.RLock()
+	defer r.RUnlock()
+
+	return len(r.channels[channel])
+}
+
+// IsPresent returns true if the uuid is an occupant of the channel.
+func (r *PresenceRoster) IsPresent(channel, uuid string) bool {
+	r.RLock()
+	defer r.RUnlock()
+
+	_, ok := r.channels[channel][uuid]
+	return ok
+}
+
+// Snapshot returns the occupants of all the channels of the roster.
+func (r *PresenceRoster) Snapshot() map[string][]HereNowOccupantsData {
+	r.RLock()
+	defer r.RUnlock()
+
+	snapshot := make(map[string][]HereNowOccupantsData, len(r.channels))
+	for ch := range r.channels {
+		snapshot[ch] = r.occupantsLocked(ch)
+	}
+
+	return snapshot
+}
+
+func (r *PresenceRoster) occupantsLocked(channel string) []HereNowOccupantsData {
+	occupants := make([]HereNowOccupantsData, 0, len(r.channels[channel]))
+	for uuid, state := range r.channels[channel] {
+		occupants = append(occupants, HereNowOccupantsData{
+			UUID:  uuid,
+			State: copyRosterState(state),
+		})
+	}
+	sort.Slice(occupants, func(i, j int) bool {
+		return occupants[i].UUID < occupants[j].UUID
+	})
+
+	return occupants
+}
+
+func copyRosterState(state map[string]interface{}) map[string]interface{} {
+	if state == nil {
+		return nil
+	}
+
+	c := make(map[string]interface{}, len(state))
+	for k, v := range state {
+		c[k] = v
+	}
+	return c
+}
+
+// applyPresence updates the roster with the presence event of one of its channels.
+func (r *PresenceRoster) applyPresence(p *PNPresence) {
+	if p.Event == PNHereNowEvent {
+		r.replace(p.Channel, p.Occupants)
+		return
+	}
+
+	state, _ := p.State.(map[string]interface{})
+
+	r.Lock()
+	occupants, ok := r.channels[p.Channel]
+	if !ok {
+		r.Unlock()
+		return
+	}
+
+	var changes []PresenceRosterChange
+	join := func(uuid string, state map[string]interface{}) {
+		occupants[uuid] = state
+		changes = append(changes, PresenceRosterChange{Channel: p.Channel, UUID: uuid, Event: "join", State: copyRosterState(state)})
+	}
+	leave := func(uuid, event string) {
+		if _, ok := occupants[uuid]; ok {
+			delete(occupants, uuid)
+			changes = append(changes, PresenceRosterChange{Channel: p.Channel, UUID: uuid, Event: event})
+		}
+	}
+
+	switch p.Event {
+	case "join":
+		join(p.UUID, state)
+	case "leave", "timeout":
+		leave(p.UUID, p.Event)
+	case "state-change":
+		occupants[p.UUID] = state
+		changes = append(changes, PresenceRosterChange{Channel: p.Channel, UUID: p.UUID, Event: p.Event, State: copyRosterState(state)})
+	case "interval":
+		for _, uuid := range p.Join {
+			if _, ok := occupants[uuid]; !ok {
+				join(uuid, nil)
+			}
+		}
+		for _, uuid := range p.Leave {
+			leave(uuid, "leave")
+		}
+		for _, uuid := range p.Timeout {
+			leave(uuid, "timeout")
+		}
+	}
+	callbacks := r.onChange
+	r.Unlock()
+
+	notifyRoster(callbacks, changes)
+}
+
+// replace sets the occupants of the channel, the differences with the previous ones are
+// notified as joins, leaves and state changes.
+func (r *PresenceRoster) replace(channel string, occupants []HereNowOccupantsData) {
+	r.Lock()
+	previous, ok := r.channels[channel]
+	if !ok {
+		r.Unlock()
+		return
+	}
+
+	current := make(map[string]map[string]interface{}, len(occupants))
+	var changes []PresenceRosterChange
+	for _, o := range occupants {
+		current[o.UUID] = o.State
+		if state, ok := previous[o.UUID]; !ok {
+			changes = append(changes, PresenceRosterChange{Channel: channel, UUID: o.UUID, Event: "join", State: copyRosterState(o.State)})
+		} else if !rosterStatesEqual(state, o.State) {
+			changes = append(changes, PresenceRosterChange{Channel: channel, UUID: o.UUID, Event: "state-change", State: copyRosterState(o.State)})
+		}
+	}
+	for uuid := range previous {
+		if _, ok := current[uuid]; !ok {
+			changes = append(changes, PresenceRosterChange{Channel: channel, UUID: uuid, Event: "leave"})
+		}
+	}
+	r.channels[channel] = current
+	callbacks := r.onChange
+	r.Unlock()
+
+	notifyRoster(callbacks, changes)
+}
+
+func rosterStatesEqual(a, b map[string]interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || !jsonValuesEqual(v, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonValuesEqual compares the values decoded from JSON, the maps and slices included.
+func jsonValuesEqual(a, b interface{}) bool {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		return ok && rosterStatesEqual(av, bv)
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonValuesEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+func notifyRoster(callbacks []func(PresenceRosterChange), changes []PresenceRosterChange) {
+	for _, change := range changes {
+		for _, callback := range callbacks {
+			callback(change)
+		}
+	}
+}
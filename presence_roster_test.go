@@ -0,0 +1,160 @@
+package pubnub
+
+import (
+	"testing"
+
+	"github.com/sprucehealth/pubnub-go/pubnubtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresenceRosterApplyPresence(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+
+	r := NewPresenceRoster(pn, []string{"room"})
+	defer r.Close()
+
+	var changes []PresenceRosterChange
+	r.OnChange(func(c PresenceRosterChange) {
+		changes = append(changes, c)
+	})
+
+	r.applyPresence(&PNPresence{Event: "join", Channel: "room", UUID: "a", State: map[string]interface{}{"mood": "happy"}})
+	r.applyPresence(&PNPresence{Event: "join", Channel: "room", UUID: "b"})
+	r.applyPresence(&PNPresence{Event: "join", Channel: "other", UUID: "c"})
+	r.applyPresence(&PNPresence{Event: "state-change", Channel: "room", UUID: "b", State: map[string]interface{}{"mood": "sad"}})
+	r.applyPresence(&PNPresence{Event: "leave", Channel: "room", UUID: "a"})
+	r.applyPresence(&PNPresence{Event: "interval", Channel: "room", Join: []string{"d", "e"}, Timeout: []string{"b"}})
+
+	assert.Equal([]HereNowOccupantsData{{UUID: "d"}, {UUID: "e"}}, r.Occupants("room"))
+	assert.Equal(2, r.Occupancy("room"))
+	assert.False(r.IsPresent("other", "c"))
+	assert.Equal([]PresenceRosterChange{
+		{Channel: "room", UUID: "a", Event: "join", State: map[string]interface{}{"mood": "happy"}},
+		{Channel: "room", UUID: "b", Event: "join"},
+		{Channel: "room", UUID: "b", Event: "state-change", State: map[string]interface{}{"mood": "sad"}},
+		{Channel: "room", UUID: "a", Event: "leave"},
+		{Channel: "room", UUID: "d", Event: "join"},
+		{Channel: "room", UUID: "e", Event: "join"},
+		{Channel: "room", UUID: "b", Event: "timeout"},
+	}, changes)
+
+	changes = nil
+	r.applyPresence(&PNPresence{Event: PNHereNowEvent, Channel: "room", Occupants: []HereNowOccupantsData{
+		{UUID: "e", State: map[string]interface{}{}},
+		{UUID: "f", State: map[string]interface{}{"mood": "busy"}},
+	}})
+
+	assert.Equal(map[string][]HereNowOccupantsData{
+		"room": {
+			{UUID: "e", State: map[string]interface{}{}},
+			{UUID: "f", State: map[string]interface{}{"mood": "busy"}},
+		},
+	}, r.Snapshot())
+	assert.Equal([]PresenceRosterChange{
+		{Channel: "room", UUID: "f", Event: "join", State: map[string]interface{}{"mood": "busy"}},
+		{Channel: "room", UUID: "d", Event: "leave"},
+	}, changes)
+}
+
+func TestPresenceRoster(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	first := newTestServerPubNub(srv, "first")
+	defer first.Destroy()
+	first.Subscribe().Channels([]string{"room"}).State(map[string]interface{}{"mood": "happy"}).Execute()
+	waitUntil(t, func() bool {
+		return len(srv.Occupants("room")) == 1
+	})
+
+	pn := newTestServerPubNub(srv, "subscriber")
+	defer pn.Destroy()
+
+	r := NewPresenceRoster(pn, []string{"room"})
+	defer r.Close()
+	pn.Subscribe().Channels([]string{"room"}).WithPresence(true).Execute()
+
+	assert.Nil(r.Sync())
+	waitUntil(t, func() bool {
+		return r.IsPresent("room", "first")
+	})
+	// the join of second is received once the subscribe loop has a timetoken
+	waitUntil(t, func() bool {
+		return pn.ConnectionState() == PNReceivingState
+	})
+	assert.Equal(map[string]interface{}{"mood": "happy"}, r.Occupants("room")[0].State)
+
+	second := newTestServerPubNub(srv, "second")
+	defer second.Destroy()
+	second.Subscribe().Channels([]string{"room"}).Execute()
+	waitUntil(t, func() bool {
+		return r.IsPresent("room", "second")
+	})
+
+	first.UnsubscribeAll()
+	waitUntil(t, func() bool {
+		return !r.IsPresent("room", "first")
+	})
+}
+
+func TestPresenceRosterResyncAfterReconnection(t *testing.T) {
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	other := newTestServerPubNub(srv, "other")
+	defer other.Destroy()
+	other.Subscribe().Channels([]string{"room"}).Execute()
+	waitUntil(t, func() bool {
+		return len(srv.Occupants("room")) == 1
+	})
+
+	pn := newTestServerPubNub(srv, "subscriber")
+	defer pn.Destroy()
+
+	r := NewPresenceRoster(pn, []string{"room"})
+	defer r.Close()
+
+	pn.subscriptionManager.listenerManager.announceStatus(&PNStatus{Category: PNReconnectedCategory})
+	waitUntil(t, func() bool {
+		return r.IsPresent("room", "other")
+	})
+}
+
+func TestPresenceRosterSyncEmptiedChannel(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	other := newTestServerPubNub(srv, "other")
+	defer other.Destroy()
+	other.Subscribe().Channels([]string{"a", "b"}).Execute()
+	waitUntil(t, func() bool {
+		return len(srv.Occupants("a")) == 1 && len(srv.Occupants("b")) == 1
+	})
+
+	pn := newTestServerPubNub(srv, "subscriber")
+	defer pn.Destroy()
+
+	r := NewPresenceRoster(pn, []string{"a", "b"})
+	defer r.Close()
+	assert.Nil(r.Sync())
+	assert.True(r.IsPresent("a", "other"))
+	assert.True(r.IsPresent("b", "other"))
+
+	// b empties while the roster doesn't receive the presence events, it's omitted by HereNow
+	other.Unsubscribe().Channels([]string{"b"}).Execute()
+	waitUntil(t, func() bool {
+		return len(srv.Occupants("b")) == 0
+	})
+
+	assert.Nil(r.Sync())
+	assert.True(r.IsPresent("a", "other"))
+	assert.False(r.IsPresent("b", "other"))
+	assert.Equal(0, r.Occupancy("b"))
+}
@@ -18,6 +18,9 @@ import (
 const publishGetPath = "/publish/%s/%s/0/%s/%s/%s"
 const publishPostPath = "/publish/%s/%s/0/%s/%s"
 
+// dedupeKeyMetaField is the field of the Meta which carries the DedupeKey of the message.
+const dedupeKeyMetaField = "pn_dedupe_key"
+
 var emptyPublishResponse *PublishResponse
 
 type publishOpts struct {
@@ -28,6 +31,8 @@ type publishOpts struct {
 	Message interface{}
 	Meta    interface{}
 
+	DedupeKey string
+
 	UsePost        bool
 	ShouldStore    bool
 	Serialize      bool
@@ -145,6 +150,17 @@ func (b *publishBuilder) Meta(meta interface{}) *publishBuilder {
 	return b
 }
 
+// DedupeKey sets a key, unique to the message, which is added to the Meta as pn_dedupe_key.
+// Publish requests with a DedupeKey are retried according to the RequestRetryPolicy of the
+// Config. A retried publish gets a new timetoken, the subscribers of this SDK drop the messages
+// whose key they received already, within the DedupCacheSize and DedupCacheTTL of their Config.
+// The other subscribers, and the history, get the duplicates.
+func (b *publishBuilder) DedupeKey(key string) *publishBuilder {
+	b.opts.DedupeKey = key
+
+	return b
+}
+
 // UsePost sends the Publish request using HTTP POST.
 func (b *publishBuilder) UsePost(post bool) *publishBuilder {
 	b.opts.UsePost = post
@@ -211,6 +227,10 @@ func (o *publishOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *publishOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *publishOpts) context() Context {
 	return o.ctx
 }
@@ -232,51 +252,83 @@ func (o *publishOpts) validate() error {
 		return newValidationError(o, StrMissingMessage)
 	}
 
+	if o.DedupeKey != "" && o.Meta != nil {
+		if _, ok := o.Meta.(map[string]interface{}); !ok {
+			return newValidationError(o, StrInvalidMetaWithDedupeKey)
+		}
+	}
+
 	return nil
 }
 
-func (o *publishOpts) encryptProcessing(cipherKey string) (string, error) {
+// isRetryable allows the RequestRetryPolicy to retry the publish when the subscribers can drop the duplicates
+// by their pn_dedupe_key.
+func (o *publishOpts) isRetryable() bool {
+	return o.DedupeKey != ""
+}
+
+func (o *publishOpts) meta() interface{} {
+	if o.DedupeKey == "" {
+		return o.Meta
+	}
+
+	meta := map[string]interface{}{}
+	if m, ok := o.Meta.(map[string]interface{}); ok {
+		for k, v := range m {
+			meta[k] = v
+		}
+	}
+	meta[dedupeKeyMetaField] = o.DedupeKey
+
+	return meta
+}
+
+func (o *publishOpts) encryptProcessing(module *utils.CryptoModule) (string, error) {
 	var msg string
 	var errJSONMarshal error
 
-	o.pubnub.Config.Log.Println("EncryptString: encrypting", fmt.Sprintf("%s", o.Message))
+	o.pubnub.Config.logger().Debug("encrypting message", "message", o.Message)
 	if o.pubnub.Config.DisablePNOtherProcessing {
-		if msg, errJSONMarshal = utils.SerializeEncryptAndSerialize(o.Message, cipherKey, o.Serialize); errJSONMarshal != nil {
-			o.pubnub.Config.Log.Printf("error in serializing: %v\n", errJSONMarshal)
+		if msg, errJSONMarshal = utils.SerializeEncryptAndSerializeWithModule(o.Message, module, o.Serialize); errJSONMarshal != nil {
+			o.pubnub.Config.logger().Error("message serialization failed", "error", errJSONMarshal)
 			return "", errJSONMarshal
 		}
 	} else {
 		//encrypt pn_other only
-		o.pubnub.Config.Log.Println("encrypt pn_other only", "reflect.TypeOf(data).Kind()", reflect.TypeOf(o.Message).Kind(), o.Message)
+		o.pubnub.Config.logger().Debug("encrypting pn_other only", "kind", reflect.TypeOf(o.Message).Kind())
 		switch v := o.Message.(type) {
 		case map[string]interface{}:
 
 			msgPart, ok := v["pn_other"].(string)
 
 			if ok {
-				o.pubnub.Config.Log.Println(ok, msgPart)
-				encMsg, errJSONMarshal := utils.SerializeAndEncrypt(msgPart, cipherKey, o.Serialize)
+				encMsg, errJSONMarshal := utils.SerializeAndEncryptWithModule(msgPart, module, o.Serialize)
 				if errJSONMarshal != nil {
-					o.pubnub.Config.Log.Printf("error in serializing: %v\n", errJSONMarshal)
+					o.pubnub.Config.logger().Error("message serialization failed", "error", errJSONMarshal)
 					return "", errJSONMarshal
 				}
-				v["pn_other"] = encMsg
-				jsonEncBytes, errEnc := json.Marshal(v)
+				// the message of the caller is left as is, the body is built again when the publish is retried
+				encrypted := make(map[string]interface{}, len(v))
+				for k, val := range v {
+					encrypted[k] = val
+				}
+				encrypted["pn_other"] = encMsg
+				jsonEncBytes, errEnc := json.Marshal(encrypted)
 				if errEnc != nil {
-					o.pubnub.Config.Log.Printf("ERROR: Publish error: %s\n", errEnc.Error())
+					o.pubnub.Config.logger().Error("message encryption failed", "error", errEnc)
 					return "", errEnc
 				}
 				msg = string(jsonEncBytes)
 			} else {
-				if msg, errJSONMarshal = utils.SerializeEncryptAndSerialize(o.Message, cipherKey, o.Serialize); errJSONMarshal != nil {
-					o.pubnub.Config.Log.Printf("error in serializing: %v\n", errJSONMarshal)
+				if msg, errJSONMarshal = utils.SerializeEncryptAndSerializeWithModule(o.Message, module, o.Serialize); errJSONMarshal != nil {
+					o.pubnub.Config.logger().Error("message serialization failed", "error", errJSONMarshal)
 					return "", errJSONMarshal
 				}
 			}
 			break
 		default:
-			if msg, errJSONMarshal = utils.SerializeEncryptAndSerialize(o.Message, cipherKey, o.Serialize); errJSONMarshal != nil {
-				o.pubnub.Config.Log.Printf("error in serializing: %v\n", errJSONMarshal)
+			if msg, errJSONMarshal = utils.SerializeEncryptAndSerializeWithModule(o.Message, module, o.Serialize); errJSONMarshal != nil {
+				o.pubnub.Config.logger().Error("message serialization failed", "error", errJSONMarshal)
 				return "", errJSONMarshal
 			}
 
@@ -298,21 +350,17 @@ func (o *publishOpts) buildPath() (string, error) {
 	var msg string
 	var errJSONMarshal error
 
-	cipherKey := o.cipherKey
-	if cipherKey == "" {
-		cipherKey = o.pubnub.Config.CipherKey
-	}
-	if cipherKey != "" {
-		if msg, errJSONMarshal = o.encryptProcessing(cipherKey); errJSONMarshal != nil {
+	if module := o.pubnub.Config.cryptoModule(o.cipherKey); module != nil {
+		if msg, errJSONMarshal = o.encryptProcessing(module); errJSONMarshal != nil {
 			return "", errJSONMarshal
 		}
 
-		o.pubnub.Config.Log.Println("EncryptString: encrypted", msg)
+		o.pubnub.Config.logger().Debug("message encrypted", "message", msg)
 	} else {
 		if o.Serialize {
 			jsonEncBytes, errEnc := json.Marshal(o.Message)
 			if errEnc != nil {
-				o.pubnub.Config.Log.Printf("ERROR: Publish error: %s\n", errEnc.Error())
+				o.pubnub.Config.logger().Error("message encryption failed", "error", errEnc)
 				return "", errEnc
 			}
 			msg = string(jsonEncBytes)
@@ -336,8 +384,8 @@ func (o *publishOpts) buildPath() (string, error) {
 func (o *publishOpts) buildQuery() (*url.Values, error) {
 	q := defaultQuery(o.pubnub.Config.UUID, o.pubnub.telemetryManager)
 
-	if o.Meta != nil {
-		meta, err := utils.ValueAsString(o.Meta)
+	if m := o.meta(); m != nil {
+		meta, err := utils.ValueAsString(m)
 		if err != nil {
 			return &url.Values{}, err
 		}
@@ -360,31 +408,27 @@ func (o *publishOpts) buildQuery() (*url.Values, error) {
 	}
 
 	seqn := strconv.Itoa(o.pubnub.getPublishSequence())
-	o.pubnub.Config.Log.Println("seqn:", seqn)
+	o.pubnub.Config.logger().Debug("publish sequence", "seqn", seqn)
 	q.Set("seqn", seqn)
 
+	o.pubnub.tokenManager.setAuthParam(q, PNChannels, o.Channel)
 	SetQueryParam(q, o.QueryParam)
 
 	if o.DoNotReplicate == true {
 		q.Set("norep", "true")
 	}
-	o.pubnub.Config.Log.Println(q)
 
 	return q, nil
 }
 
-func (o *publishOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *publishOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *publishOpts) buildBody() ([]byte, error) {
 	if o.UsePost {
-		cipherKey := o.cipherKey
-		if cipherKey == "" {
-			cipherKey = o.pubnub.Config.CipherKey
-		}
-		if cipherKey != "" {
-			msg, errJSONMarshal := o.encryptProcessing(cipherKey)
+		if module := o.pubnub.Config.cryptoModule(o.cipherKey); module != nil {
+			msg, errJSONMarshal := o.encryptProcessing(module)
 			if errJSONMarshal != nil {
 				return []byte{}, errJSONMarshal
 			}
@@ -393,7 +437,7 @@ func (o *publishOpts) buildBody() ([]byte, error) {
 		if o.Serialize {
 			jsonEncBytes, errEnc := json.Marshal(o.Message)
 			if errEnc != nil {
-				o.pubnub.Config.Log.Printf("ERROR: Publish error: %s\n", errEnc.Error())
+				o.pubnub.Config.logger().Error("message encryption failed", "error", errEnc)
 				return []byte{}, errEnc
 			}
 			return jsonEncBytes, nil
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"log"
 	"net/url"
+	"strings"
 	"testing"
 
 	h "github.com/sprucehealth/pubnub-go/tests/helpers"
+	"github.com/sprucehealth/pubnub-go/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -410,6 +412,28 @@ func TestPublishStore(t *testing.T) {
 	assert.Empty(body)
 }
 
+func TestPublishDedupeKey(t *testing.T) {
+	assert := assert.New(t)
+
+	opts := &publishOpts{
+		Channel:   "ch",
+		Message:   "hey",
+		Meta:      map[string]interface{}{"a": "b"},
+		DedupeKey: "msg-1",
+		pubnub:    pubnub,
+	}
+
+	assert.Nil(opts.validate())
+
+	query, err := opts.buildQuery()
+	assert.Nil(err)
+	assert.Equal(`{"a":"b","pn_dedupe_key":"msg-1"}`, query.Get("meta"))
+	assert.Equal(map[string]interface{}{"a": "b"}, opts.Meta)
+
+	opts.Meta = "not a map"
+	assert.Equal("pubnub/validation: pubnub: \x03: Meta must be a map[string]interface{} when a Dedupe Key is set", opts.validate().Error())
+}
+
 func TestPublishEncrypt(t *testing.T) {
 	assert := assert.New(t)
 
@@ -430,6 +454,67 @@ func TestPublishEncrypt(t *testing.T) {
 	pnconfig.CipherKey = ""
 }
 
+func TestPublishEncryptRandomIV(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	pn.Config.CipherKey = "enigma"
+	pn.Config.UseRandomInitializationVector = true
+
+	opts := &publishOpts{
+		Channel: "ch",
+		Message: "hey",
+		pubnub:  pn,
+	}
+
+	path1, err := opts.buildPath()
+	assert.Nil(err)
+	path2, err := opts.buildPath()
+	assert.Nil(err)
+	assert.NotEqual(path1, path2)
+
+	prefix := "/publish/demo/demo/0/ch/0/"
+	assert.True(strings.HasPrefix(path1, prefix))
+
+	encrypted, err := url.PathUnescape(strings.TrimPrefix(path1, prefix))
+	assert.Nil(err)
+
+	decrypted, err := utils.DecryptString("enigma", strings.Trim(encrypted, "\""))
+	assert.Nil(err)
+	assert.Equal("hey", decrypted)
+}
+
+func TestPublishEncryptCryptor(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	pn.Config.CipherKey = "enigma"
+	pn.Config.Cryptor = testXORCryptor{}
+
+	opts := &publishOpts{
+		Channel: "ch",
+		Message: "hey",
+		pubnub:  pn,
+	}
+
+	path, err := opts.buildPath()
+	assert.Nil(err)
+
+	encrypted, err := url.PathUnescape(strings.TrimPrefix(path, "/publish/demo/demo/0/ch/0/"))
+	assert.Nil(err)
+
+	decrypted, err := utils.NewCryptoModule(testXORCryptor{}).DecryptString(strings.Trim(encrypted, "\""))
+	assert.Nil(err)
+	assert.Equal("hey", decrypted)
+
+	// the cipher key passed with PublishWithCipherKey takes precedence over the cryptor
+	opts.cipherKey = "testCipher"
+	path, err = opts.buildPath()
+	assert.Nil(err)
+	assert.Equal(
+		"/publish/demo/demo/0/ch/0/%22%2Bc52pEK3TCTpuEjEFzukRw%3D%3D%22", path)
+}
+
 func TestPublishEncryptPNOther(t *testing.T) {
 	assert := assert.New(t)
 
@@ -457,6 +542,39 @@ func TestPublishEncryptPNOther(t *testing.T) {
 	pn.Config.CipherKey = ""
 }
 
+func TestPublishEncryptPNOtherRetried(t *testing.T) {
+	assert := assert.New(t)
+
+	config := NewDemoConfig()
+	config.CipherKey = "enigma"
+	pn := NewPubNub(config)
+	defer pn.Destroy()
+
+	s := map[string]interface{}{
+		"not_other": "1234",
+		"pn_other":  "yay!",
+	}
+
+	opts := &publishOpts{
+		Channel:   "ch",
+		Message:   s,
+		pubnub:    pn,
+		Serialize: true,
+		UsePost:   true,
+		DedupeKey: "key",
+	}
+
+	// the body is built for every attempt of a retried publish
+	body, err := opts.buildBody()
+	assert.Nil(err)
+	retried, err := opts.buildBody()
+	assert.Nil(err)
+
+	assert.Equal(`{"not_other":"1234","pn_other":"Wi24KS4pcTzvyuGOHubiXg=="}`, string(body))
+	assert.Equal(string(body), string(retried))
+	assert.Equal("yay!", s["pn_other"])
+}
+
 func TestPublishEncryptPNOtherDisable(t *testing.T) {
 	assert := assert.New(t)
 	pn := NewPubNub(NewDemoConfig())
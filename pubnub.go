@@ -1,9 +1,6 @@
 package pubnub
 
 import (
-	"fmt"
-	"io/ioutil"
-	"log"
 	"net/http"
 	"runtime"
 	"sync"
@@ -40,6 +37,22 @@ const (
 	StrChannelsTimetoken = "Missing Channels Timetoken"
 	// StrChannelsTimetokenLength shows Length of Channels Timetoken message
 	StrChannelsTimetokenLength = "Length of Channels Timetoken and Channels do not match"
+	// StrMissingMessageTimetoken shows Missing Message Timetoken message
+	StrMissingMessageTimetoken = "Missing Message Timetoken"
+	// StrMissingMessageActionType shows Missing Message Action Type message
+	StrMissingMessageActionType = "Missing Message Action Type"
+	// StrMissingMessageActionValue shows Missing Message Action Value message
+	StrMissingMessageActionValue = "Missing Message Action Value"
+	// StrMissingActionTimetoken shows Missing Action Timetoken message
+	StrMissingActionTimetoken = "Missing Action Timetoken"
+	// StrInvalidTTL shows Invalid TTL message
+	StrInvalidTTL = "Invalid TTL"
+	// StrMissingResources shows Missing Resources message
+	StrMissingResources = "Missing Resources"
+	// StrInvalidPattern shows Invalid Pattern message
+	StrInvalidPattern = "Invalid Pattern"
+	// StrInvalidMetaWithDedupeKey shows Invalid Meta with Dedupe Key message
+	StrInvalidMetaWithDedupeKey = "Meta must be a map[string]interface{} when a Dedupe Key is set"
 )
 
 // PubNub No server connection will be established when you create a new PubNub object.
@@ -52,11 +65,13 @@ type PubNub struct {
 	publishSequenceMutex sync.RWMutex
 	subscriptionManager  *SubscriptionManager
 	telemetryManager     *TelemetryManager
+	tokenManager         *tokenManager
 	heartbeatManager     *HeartbeatManager
 	client               *http.Client
 	subscribeClient      *http.Client
 	requestWorkers       *RequestWorkers
-	jobQueue             chan *JobQItem
+	routines             routineGroup
+	destroyOnce          sync.Once
 	ctx                  Context
 	cancel               func()
 }
@@ -230,6 +245,30 @@ func (pn *PubNub) SignalWithContext(ctx Context) *signalBuilder {
 	return newSignalBuilderWithContext(pn, ctx)
 }
 
+func (pn *PubNub) AddMessageAction() *addMessageActionBuilder {
+	return newAddMessageActionBuilder(pn)
+}
+
+func (pn *PubNub) AddMessageActionWithContext(ctx Context) *addMessageActionBuilder {
+	return newAddMessageActionBuilderWithContext(pn, ctx)
+}
+
+func (pn *PubNub) GetMessageActions() *getMessageActionsBuilder {
+	return newGetMessageActionsBuilder(pn)
+}
+
+func (pn *PubNub) GetMessageActionsWithContext(ctx Context) *getMessageActionsBuilder {
+	return newGetMessageActionsBuilderWithContext(pn, ctx)
+}
+
+func (pn *PubNub) RemoveMessageAction() *removeMessageActionBuilder {
+	return newRemoveMessageActionBuilder(pn)
+}
+
+func (pn *PubNub) RemoveMessageActionWithContext(ctx Context) *removeMessageActionBuilder {
+	return newRemoveMessageActionBuilderWithContext(pn, ctx)
+}
+
 func (pn *PubNub) SetState() *setStateBuilder {
 	return newSetStateBuilder(pn)
 }
@@ -246,6 +285,34 @@ func (pn *PubNub) GrantWithContext(ctx Context) *grantBuilder {
 	return newGrantBuilderWithContext(pn, ctx)
 }
 
+func (pn *PubNub) GrantToken() *grantTokenBuilder {
+	return newGrantTokenBuilder(pn)
+}
+
+func (pn *PubNub) GrantTokenWithContext(ctx Context) *grantTokenBuilder {
+	return newGrantTokenBuilderWithContext(pn, ctx)
+}
+
+// SetToken stores a token returned by GrantToken. The token is sent as the auth param
+// of the requests accessing the resources it grants, in place of Config.AuthKey.
+func (pn *PubNub) SetToken(token string) {
+	if err := pn.tokenManager.storeToken(token); err != nil {
+		pn.Config.logger().Error("invalid token", "error", err)
+	}
+}
+
+// SetTokens stores each of the tokens, see SetToken.
+func (pn *PubNub) SetTokens(tokens []string) {
+	for _, token := range tokens {
+		pn.SetToken(token)
+	}
+}
+
+// GetTokens returns the tokens stored with SetToken.
+func (pn *PubNub) GetTokens() []string {
+	return pn.tokenManager.getTokens()
+}
+
 func (pn *PubNub) Unsubscribe() *unsubscribeBuilder {
 	return newUnsubscribeBuilder(pn)
 }
@@ -345,10 +412,48 @@ func (pn *PubNub) GetSubscribedGroups() []string {
 	return pn.subscriptionManager.getSubscribedGroups()
 }
 
+// ConnectionState returns the state of the subscribe loop.
+func (pn *PubNub) ConnectionState() ConnectionState {
+	return pn.subscriptionManager.connection.current()
+}
+
+// WatchConnectionState returns a channel receiving the changes of the ConnectionState, in order,
+// and the func to call to stop watching them. The oldest changes are dropped when the channel
+// isn't read, the last one is always received.
+func (pn *PubNub) WatchConnectionState() (<-chan ConnectionStateChange, func()) {
+	return pn.subscriptionManager.connection.watch()
+}
+
+// DuplicateMessages returns the number of received messages dropped because they were
+// received already, see Config.DedupCacheSize.
+func (pn *PubNub) DuplicateMessages() uint64 {
+	return pn.subscriptionManager.dedupCache.duplicateCount()
+}
+
 func (pn *PubNub) UnsubscribeAll() {
 	pn.subscriptionManager.unsubscribeAll()
 }
 
+// Disconnect stops the subscribe long-poll, the heartbeats and the reconnections, e.g. while the
+// app is in the background. The subscriptions and the timetoken are kept for Reconnect to resume
+// from, a subscription change reconnects too.
+func (pn *PubNub) Disconnect() {
+	pn.subscriptionManager.pause()
+}
+
+// Reconnect resumes the subscribe loop from its timetoken right away, after Disconnect or a
+// network failure, without waiting for the reconnection policy delay.
+func (pn *PubNub) Reconnect() {
+	pn.subscriptionManager.resume()
+}
+
+// NetworkChanged is a hint that the network changed, e.g. after a wake from sleep or a switch of
+// network interface. The pending subscribe long-poll, which may never complete on the previous
+// network, is aborted and sent again from the timetoken.
+func (pn *PubNub) NetworkChanged() {
+	pn.subscriptionManager.networkChanged()
+}
+
 func (pn *PubNub) ListPushProvisions() *listPushProvisionsRequestBuilder {
 	return newListPushProvisionsRequestBuilder(pn)
 }
@@ -461,31 +566,71 @@ func (pn *PubNub) DeleteMessagesWithContext(ctx Context) *historyDeleteBuilder {
 	return newHistoryDeleteBuilderWithContext(pn, ctx)
 }
 
+// RequestQueueStats returns the number of busy request workers, the number of requests waiting for
+// one and the wait times by operation.
+func (pn *PubNub) RequestQueueStats() RequestQueueStats {
+	return pn.requestWorkers.stats()
+}
+
+// Destroy stops the subscribe loop, the heartbeats and the request workers without waiting for
+// them, see Close. Calling it more than once has no effect.
 func (pn *PubNub) Destroy() {
-	pn.requestWorkers.Close()
+	pn.destroyOnce.Do(pn.destroy)
+}
 
-	close(pn.jobQueue)
-	pn.Config.Log.Println("Calling Destroy")
+func (pn *PubNub) destroy() {
+	pn.Config.logger().Info("destroying")
 	pn.cancel()
+	pn.requestWorkers.Close()
 
 	if pn.subscriptionManager != nil {
 		pn.subscriptionManager.Destroy()
-		pn.Config.Log.Println("after subscription manager Destroy")
+		pn.Config.logger().Debug("subscription manager destroyed")
 	}
 
-	pn.Config.Log.Println("calling subscriptionManager Destroy")
 	if pn.heartbeatManager != nil {
 		pn.heartbeatManager.Destroy()
-		pn.Config.Log.Println("after heartbeat manager Destroy")
+		pn.Config.logger().Debug("heartbeat manager destroyed")
 	}
 
-	pn.Config.Log.Println("After Destroy")
-	pn.Config.Log.Println("calling RemoveAllListeners")
 	pn.subscriptionManager.RemoveAllListeners()
-	pn.Config.Log.Println("after RemoveAllListeners")
+	pn.Config.logger().Info("destroyed")
 
 }
 
+// Close unsubscribes from all the channels and groups, with a leave unless
+// Config.SuppressLeaveEvents is set, waits for the requests queued on the request workers such as
+// the publishes, then destroys the instance and waits for all its goroutines to exit. The ctx
+// bounds the wait, its error is returned if it's done before, and the requests still being sent
+// are then cancelled. The requests queued after Close fail with ErrClosed.
+func (pn *PubNub) Close(ctx Context) error {
+	pn.Config.logger().Info("closing")
+
+	if !pn.subscriptionManager.stateManager.isEmpty() {
+		select {
+		case err := <-pn.subscriptionManager.unsubscribeAll():
+			if err != nil {
+				pn.Config.logger().Warn("leave failed while closing", "error", err)
+			}
+		case <-ctx.Done():
+		}
+	}
+
+	err := pn.requestWorkers.drain(ctx)
+	pn.Destroy()
+	if waitErr := pn.routines.wait(ctx); err == nil {
+		err = waitErr
+	}
+
+	if err != nil {
+		pn.Config.logger().Warn("closed before all the goroutines exited", "running", pn.routines.running(), "error", err)
+		return err
+	}
+
+	pn.Config.logger().Info("closed")
+	return nil
+}
+
 func (pn *PubNub) getPublishSequence() int {
 	pn.publishSequenceMutex.Lock()
 	defer pn.publishSequenceMutex.Unlock()
@@ -502,10 +647,8 @@ func (pn *PubNub) getPublishSequence() int {
 func NewPubNub(pnconf *Config) *PubNub {
 	ctx, cancel := contextWithCancel(backgroundContext)
 
-	if pnconf.Log == nil {
-		pnconf.Log = log.New(ioutil.Discard, "", log.Ldate|log.Ltime|log.Lshortfile)
-	}
-	pnconf.Log.Println(fmt.Sprintf("PubNub Go v4 SDK: %s\npnconf: %v\n%s\n%s\n%s", Version, pnconf, runtime.Version(), runtime.GOARCH, runtime.GOOS))
+	pnconf.logger().Info("PubNub Go v4 SDK", "version", Version, "go", runtime.Version(),
+		"arch", runtime.GOARCH, "os", runtime.GOOS, "origin", pnconf.Origin, "uuid", pnconf.UUID)
 
 	pn := &PubNub{
 		Config:              pnconf,
@@ -516,21 +659,21 @@ func NewPubNub(pnconf *Config) *PubNub {
 
 	pn.subscriptionManager = newSubscriptionManager(pn, ctx)
 	pn.heartbeatManager = newHeartbeatManager(pn, ctx)
-	pn.telemetryManager = newTelemetryManager(pnconf.MaximumLatencyDataAge, ctx)
-	pn.jobQueue = make(chan *JobQItem)
+	pn.telemetryManager = newTelemetryManager(pnconf.MaximumLatencyDataAge, ctx, &pn.routines)
+	pn.tokenManager = newTokenManager()
 	pn.requestWorkers = pn.newNonSubQueueProcessor(pnconf.MaxWorkers, ctx)
 
 	return pn
 }
 
 func (pn *PubNub) newNonSubQueueProcessor(maxWorkers int, ctx Context) *RequestWorkers {
-	workers := make(chan chan *JobQItem, maxWorkers)
-
-	pn.Config.Log.Printf("Init RequestWorkers: workers %d", maxWorkers)
+	pn.Config.logger().Debug("initializing request workers", "workers", maxWorkers,
+		"queue_size", pn.Config.RequestQueueSize)
 
 	p := &RequestWorkers{
-		Workers:    workers,
 		MaxWorkers: maxWorkers,
+		queueSize:  pn.Config.RequestQueueSize,
+		fullPolicy: pn.Config.RequestQueueFullPolicy,
 	}
 	p.Start(pn, ctx)
 	return p
@@ -1,8 +1,17 @@
 package pubnub
 
 import (
+	"context"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/sprucehealth/pubnub-go/pnerr"
+	"github.com/sprucehealth/pubnub-go/pubnubtest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,3 +39,142 @@ func TestDemoInitializer(t *testing.T) {
 	assert.Equal("demo", demo.Config.SubscribeKey)
 	assert.Equal("demo", demo.Config.SecretKey)
 }
+
+// packageGoroutines returns the stacks of the goroutines running functions of the package, by
+// goroutine ID. The goroutines of the tests are left out.
+func packageGoroutines() map[string]string {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	goroutines := make(map[string]string)
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		if strings.Contains(stack, "sprucehealth/pubnub-go.") && !strings.Contains(stack, "sprucehealth/pubnub-go.Test") {
+			goroutines[strings.Fields(stack)[1]] = stack
+		}
+	}
+	return goroutines
+}
+
+// exitingGoroutine returns true if the goroutine runs no function of the package but those of a
+// routineGroup, as it's exiting once its function returned and it was counted done.
+func exitingGoroutine(stack string) bool {
+	for _, line := range strings.Split(stack, "\n") {
+		if strings.Contains(line, "sprucehealth/pubnub-go.") && !strings.Contains(line, "(*routineGroup)") {
+			return false
+		}
+	}
+	return true
+}
+
+// slowPublishTransport delays the publishes, for them to be in flight while closing.
+type slowPublishTransport struct {
+	transport http.RoundTripper
+	started   int32
+}
+
+func (t *slowPublishTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.Contains(req.URL.String(), "/publish/") {
+		atomic.AddInt32(&t.started, 1)
+		time.Sleep(200 * time.Millisecond)
+	}
+	return t.transport.RoundTrip(req)
+}
+
+func TestClose(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	before := packageGoroutines()
+
+	pn := newTestServerPubNub(srv, "closer")
+	transport := &slowPublishTransport{transport: srv.Client().Transport}
+	pn.SetClient(&http.Client{Transport: transport})
+	pn.AddListener(NewListener())
+
+	pn.Subscribe().Channels([]string{"ch"}).WithPresence(true).Execute()
+	waitUntil(t, func() bool {
+		return len(srv.Occupants("ch")) == 1
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := pn.Publish().Channel("ch").Message("bye").Execute()
+			errs <- err
+		}()
+	}
+	waitUntil(t, func() bool {
+		return atomic.LoadInt32(&transport.started) == 3
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(pn.Close(ctx))
+
+	// the publishes in flight completed, the leave was sent
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.Nil(err)
+	}
+	assert.Len(srv.Occupants("ch"), 0)
+	assert.Equal(0, pn.routines.running())
+
+	_, _, err := pn.Publish().Channel("ch").Message("late").Execute()
+	if assert.IsType(&pnerr.ConnectionError{}, err) {
+		assert.Equal(ErrClosed, err.(*pnerr.ConnectionError).OrigError)
+	}
+
+	// all the goroutines of the instance were waited for by Close
+	var leaked []string
+	for id, stack := range packageGoroutines() {
+		if _, ok := before[id]; !ok && !exitingGoroutine(stack) {
+			leaked = append(leaked, stack)
+		}
+	}
+	if len(leaked) > 0 {
+		t.Fatalf("%d goroutines leaked:\n\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+	}
+
+	pn.Destroy()
+	assert.Nil(pn.Close(ctx))
+}
+
+func TestCloseContextDone(t *testing.T) {
+	assert := assert.New(t)
+
+	config := NewConfig()
+	config.PublishKey = "pub"
+	config.SubscribeKey = "sub"
+	pn := NewPubNub(config)
+
+	block := make(chan struct{})
+	pn.routines.spawn(func() {
+		<-block
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(context.DeadlineExceeded, pn.Close(ctx))
+
+	close(block)
+	assert.Nil(pn.Close(context.Background()))
+}
+
+func TestDestroyTwice(t *testing.T) {
+	pn := NewPubNub(NewDemoConfig())
+	pn.Destroy()
+	pn.Destroy()
+}
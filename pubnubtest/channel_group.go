@@ -0,0 +1,83 @@
+package pubnubtest
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// groupChannelsLocked returns the channels of the groups, the presence groups are skipped.
+func (s *Server) groupChannelsLocked(groups []string) []string {
+	var channels []string
+
+	for _, g := range groups {
+		if strings.HasSuffix(g, presenceSuffix) {
+			continue
+		}
+
+		for ch := range s.groups[g] {
+			channels = append(channels, ch)
+		}
+	}
+	sort.Strings(channels)
+
+	return channels
+}
+
+func (s *Server) routeChannelGroup(w http.ResponseWriter, req *http.Request, segments []string) {
+	group := segments[0]
+	q := req.URL.Query()
+
+	switch {
+	case len(segments) == 2 && segments[1] == "remove":
+		s.mu.Lock()
+		delete(s.groups, group)
+		s.mu.Unlock()
+	case len(segments) != 1:
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	case q.Get("add") != "":
+		s.mu.Lock()
+		channels := s.groups[group]
+		if channels == nil {
+			channels = make(map[string]bool)
+			s.groups[group] = channels
+		}
+		for _, ch := range splitList(q.Get("add")) {
+			channels[ch] = true
+		}
+		s.mu.Unlock()
+	case q.Get("remove") != "":
+		s.mu.Lock()
+		for _, ch := range splitList(q.Get("remove")) {
+			delete(s.groups[group], ch)
+		}
+		s.mu.Unlock()
+	default:
+		s.mu.Lock()
+		channels := []string{}
+		for ch := range s.groups[group] {
+			channels = append(channels, ch)
+		}
+		s.mu.Unlock()
+		sort.Strings(channels)
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"status":  200,
+			"service": "channel-registry",
+			"error":   false,
+			"payload": map[string]interface{}{
+				"group":    group,
+				"channels": channels,
+			},
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  200,
+		"message": "OK",
+		"service": "channel-registry",
+		"error":   false,
+	})
+}
@@ -0,0 +1,180 @@
+package pubnubtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultHistoryCount = 100
+	defaultFetchCount   = 25
+)
+
+type fetchItem struct {
+	Message   json.RawMessage `json:"message"`
+	Meta      json.RawMessage `json:"meta,omitempty"`
+	UUID      string          `json:"uuid,omitempty"`
+	Timetoken string          `json:"timetoken"`
+}
+
+type historyItem struct {
+	Message   json.RawMessage `json:"message"`
+	Timetoken int64           `json:"timetoken"`
+}
+
+// inRange returns true if the tt is in the range of the history requests:
+// start is exclusive, end is inclusive, either can be omitted.
+func inRange(tt, start, end int64) bool {
+	switch {
+	case start != 0 && end != 0 && start > end:
+		return tt < start && tt >= end
+	case start != 0 && end != 0:
+		return tt > start && tt <= end
+	case start != 0:
+		return tt < start
+	case end != 0:
+		return tt >= end
+	}
+
+	return true
+}
+
+// storedMessagesLocked returns the stored messages of the channel in the range, oldest first.
+// Up to count messages are returned, the oldest ones when reverse is set and the newest ones otherwise.
+func (s *Server) storedMessagesLocked(channel string, start, end int64, count int, reverse bool) []*message {
+	var messages []*message
+
+	for _, m := range s.messages {
+		if m.Stored && m.Channel == channel && inRange(m.Timetoken, start, end) {
+			messages = append(messages, m)
+		}
+	}
+
+	if count > 0 && len(messages) > count {
+		if reverse {
+			messages = messages[:count]
+		} else {
+			messages = messages[len(messages)-count:]
+		}
+	}
+
+	return messages
+}
+
+// historyParams returns the start, end, count and reverse params of a history request.
+func historyParams(req *http.Request, countParam string, defaultCount int) (int64, int64, int, bool) {
+	q := req.URL.Query()
+
+	count, err := strconv.Atoi(q.Get(countParam))
+	if err != nil || count <= 0 {
+		count = defaultCount
+	}
+
+	return parseTimetoken(q.Get("start")), parseTimetoken(q.Get("end")), count, q.Get("reverse") == "true"
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, req *http.Request, channel string) {
+	start, end, count, reverse := historyParams(req, "count", defaultHistoryCount)
+	includeToken := req.URL.Query().Get("include_token") == "true"
+
+	s.mu.Lock()
+	messages := s.storedMessagesLocked(channel, start, end, count, reverse)
+	s.mu.Unlock()
+
+	items := make([]interface{}, len(messages))
+	for i, m := range messages {
+		if includeToken {
+			items[i] = historyItem{Message: m.Payload, Timetoken: m.Timetoken}
+		} else {
+			items[i] = m.Payload
+		}
+	}
+
+	var first, last int64
+	if len(messages) > 0 {
+		first, last = messages[0].Timetoken, messages[len(messages)-1].Timetoken
+	}
+
+	writeJSON(w, http.StatusOK, []interface{}{items, first, last})
+}
+
+func (s *Server) handleFetch(w http.ResponseWriter, req *http.Request, channels []string) {
+	start, end, count, reverse := historyParams(req, "max", defaultFetchCount)
+	q := req.URL.Query()
+	withMeta, withUUID := q.Get("include_meta") == "true", q.Get("include_uuid") == "true"
+
+	s.mu.Lock()
+	res := make(map[string][]fetchItem, len(channels))
+	for _, ch := range channels {
+		items := []fetchItem{}
+		for _, m := range s.storedMessagesLocked(ch, start, end, count, reverse) {
+			item := fetchItem{Message: m.Payload, Timetoken: formatTimetoken(m.Timetoken)}
+			if withMeta {
+				item.Meta = m.Meta
+			}
+			if withUUID {
+				item.UUID = m.Issuer
+			}
+			items = append(items, item)
+		}
+		res[ch] = items
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":        200,
+		"error":         false,
+		"error_message": "",
+		"channels":      res,
+	})
+}
+
+func (s *Server) handleDeleteMessages(w http.ResponseWriter, req *http.Request, channels []string) {
+	start, end, _, _ := historyParams(req, "", 0)
+
+	s.mu.Lock()
+	for _, ch := range channels {
+		for _, m := range s.storedMessagesLocked(ch, start, end, 0, false) {
+			m.Stored = false
+		}
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":        200,
+		"error":         false,
+		"error_message": "",
+	})
+}
+
+// handleMessageCounts counts the stored messages since the timetoken param, or since
+// the channelsTimetoken param of each channel.
+func (s *Server) handleMessageCounts(w http.ResponseWriter, req *http.Request, channels []string) {
+	q := req.URL.Query()
+	timetokens := splitList(q.Get("channelsTimetoken"))
+
+	if len(timetokens) > 0 && len(timetokens) != len(channels) {
+		writeError(w, http.StatusBadRequest, "Invalid channelsTimetoken")
+		return
+	}
+
+	s.mu.Lock()
+	res := make(map[string]int, len(channels))
+	for i, ch := range channels {
+		since := parseTimetoken(q.Get("timetoken"))
+		if len(timetokens) > 0 {
+			since = parseTimetoken(timetokens[i])
+		}
+
+		res[ch] = len(s.storedMessagesLocked(ch, 0, since, 0, false))
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":        200,
+		"error":         false,
+		"error_message": "",
+		"channels":      res,
+	})
+}
@@ -0,0 +1,414 @@
+package pubnubtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const defaultObjectsLimit = 100
+
+// object is a user or a space of the Objects API, as it is returned to the clients.
+type object map[string]interface{}
+
+// membership links a user to a space.
+type membership struct {
+	Custom  interface{}
+	Created string
+	Updated string
+	ETag    string
+}
+
+type membershipInput struct {
+	ID     string      `json:"id"`
+	Custom interface{} `json:"custom"`
+}
+
+type membershipsChangeSet struct {
+	Add    []membershipInput `json:"add"`
+	Update []membershipInput `json:"update"`
+	Remove []membershipInput `json:"remove"`
+}
+
+type objectsEvent struct {
+	Source  string      `json:"source"`
+	Version string      `json:"version"`
+	Event   string      `json:"event"`
+	Type    string      `json:"type"`
+	Data    interface{} `json:"data"`
+}
+
+// objectsKind holds what differs between the users and the spaces endpoints.
+type objectsKind struct {
+	name      string
+	eventType string
+	store     func(s *Server) map[string]object
+}
+
+var (
+	usersKind = &objectsKind{
+		name:      "User",
+		eventType: "user",
+		store:     func(s *Server) map[string]object { return s.users },
+	}
+	spacesKind = &objectsKind{
+		name:      "Space",
+		eventType: "space",
+		store:     func(s *Server) map[string]object { return s.spaces },
+	}
+)
+
+func objectsTimestamp() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+func objectsETag(tt int64) string {
+	return strconv.FormatInt(tt, 36)
+}
+
+func writeObjectsError(w http.ResponseWriter, statusCode int, msg string) {
+	writeJSON(w, statusCode, map[string]interface{}{
+		"status": statusCode,
+		"error": map[string]interface{}{
+			"message": msg,
+			"source":  "objects",
+		},
+	})
+}
+
+func writeObjectsData(w http.ResponseWriter, data interface{}) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status": 200,
+		"data":   data,
+	})
+}
+
+func (s *Server) routeObjects(w http.ResponseWriter, req *http.Request, segments []string) {
+	var kind *objectsKind
+
+	switch segments[1] {
+	case "users":
+		kind = usersKind
+	case "spaces":
+		kind = spacesKind
+	default:
+		writeObjectsError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	switch {
+	case len(segments) == 2 && req.Method == http.MethodGet:
+		s.handleGetObjects(w, req, kind)
+	case len(segments) == 2 && req.Method == http.MethodPost:
+		s.handleCreateObject(w, req, kind)
+	case len(segments) == 3 && req.Method == http.MethodGet:
+		s.handleGetObject(w, kind, segments[2])
+	case len(segments) == 3 && req.Method == http.MethodPatch:
+		s.handleUpdateObject(w, req, kind, segments[2])
+	case len(segments) == 3 && req.Method == http.MethodDelete:
+		s.handleDeleteObject(w, kind, segments[2])
+	case len(segments) == 4 && segments[3] != segments[1] && (req.Method == http.MethodGet || req.Method == http.MethodPatch):
+		s.handleMemberships(w, req, kind, segments[2])
+	default:
+		writeObjectsError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) handleGetObjects(w http.ResponseWriter, req *http.Request, kind *objectsKind) {
+	q := req.URL.Query()
+
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultObjectsLimit
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store := kind.store(s)
+	ids := make([]string, 0, len(store))
+	for id := range store {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	data := []object{}
+	for _, id := range ids {
+		if len(data) == limit {
+			break
+		}
+		data = append(data, store[id])
+	}
+
+	res := map[string]interface{}{
+		"status": 200,
+		"data":   data,
+	}
+	if q.Get("count") == "1" {
+		res["totalCount"] = len(ids)
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleCreateObject(w http.ResponseWriter, req *http.Request, kind *objectsKind) {
+	body, err := readBody(req)
+	if err != nil {
+		writeObjectsError(w, http.StatusBadRequest, "Invalid body")
+		return
+	}
+
+	var o object
+	if err := json.Unmarshal(body, &o); err != nil {
+		writeObjectsError(w, http.StatusBadRequest, "Invalid body")
+		return
+	}
+
+	id, _ := o["id"].(string)
+	if id == "" {
+		writeObjectsError(w, http.StatusBadRequest, "Missing id")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store := kind.store(s)
+	if _, ok := store[id]; ok {
+		writeObjectsError(w, http.StatusConflict, fmt.Sprintf("%s with the ID already exists.", kind.name))
+		return
+	}
+
+	now := objectsTimestamp()
+	o["created"] = now
+	o["updated"] = now
+	o["eTag"] = objectsETag(s.nextTimetokenLocked())
+	store[id] = o
+
+	s.announceObjectsLocked(id, "create", kind.eventType, o)
+
+	writeObjectsData(w, o)
+}
+
+func (s *Server) handleGetObject(w http.ResponseWriter, kind *objectsKind, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := kind.store(s)[id]
+	if !ok {
+		writeObjectsError(w, http.StatusNotFound, fmt.Sprintf("%s not found.", kind.name))
+		return
+	}
+
+	writeObjectsData(w, o)
+}
+
+func (s *Server) handleUpdateObject(w http.ResponseWriter, req *http.Request, kind *objectsKind, id string) {
+	body, err := readBody(req)
+	if err != nil {
+		writeObjectsError(w, http.StatusBadRequest, "Invalid body")
+		return
+	}
+
+	var changes object
+	if err := json.Unmarshal(body, &changes); err != nil {
+		writeObjectsError(w, http.StatusBadRequest, "Invalid body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := kind.store(s)[id]
+	if !ok {
+		writeObjectsError(w, http.StatusNotFound, fmt.Sprintf("%s not found.", kind.name))
+		return
+	}
+
+	for k, v := range changes {
+		switch k {
+		case "id", "created", "updated", "eTag":
+		default:
+			o[k] = v
+		}
+	}
+	o["updated"] = objectsTimestamp()
+	o["eTag"] = objectsETag(s.nextTimetokenLocked())
+
+	s.announceObjectsLocked(id, "update", kind.eventType, o)
+
+	writeObjectsData(w, o)
+}
+
+func (s *Server) handleDeleteObject(w http.ResponseWriter, kind *objectsKind, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store := kind.store(s)
+	if _, ok := store[id]; !ok {
+		writeObjectsError(w, http.StatusNotFound, fmt.Sprintf("%s not found.", kind.name))
+		return
+	}
+	delete(store, id)
+
+	if kind == usersKind {
+		delete(s.memberships, id)
+	} else {
+		for _, spaces := range s.memberships {
+			delete(spaces, id)
+		}
+	}
+
+	s.announceObjectsLocked(id, "delete", kind.eventType, object{"id": id})
+
+	writeObjectsData(w, nil)
+}
+
+// handleMemberships serves the memberships of a user or the members of a space,
+// both being stored as the spaces of each user.
+func (s *Server) handleMemberships(w http.ResponseWriter, req *http.Request, kind *objectsKind, id string) {
+	var changes membershipsChangeSet
+
+	if req.Method == http.MethodPatch {
+		body, err := readBody(req)
+		if err == nil {
+			err = json.Unmarshal(body, &changes)
+		}
+		if err != nil {
+			writeObjectsError(w, http.StatusBadRequest, "Invalid body")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := kind.store(s)[id]; !ok {
+		writeObjectsError(w, http.StatusNotFound, fmt.Sprintf("%s not found.", kind.name))
+		return
+	}
+
+	// userSpace returns the user and the space of a membership of the id with the other object
+	userSpace := func(other string) (string, string) {
+		if kind == usersKind {
+			return id, other
+		}
+		return other, id
+	}
+
+	for _, in := range append(changes.Add, changes.Update...) {
+		userID, spaceID := userSpace(in.ID)
+		if _, ok := s.users[userID]; !ok {
+			continue
+		}
+		if _, ok := s.spaces[spaceID]; !ok {
+			continue
+		}
+
+		spaces := s.memberships[userID]
+		if spaces == nil {
+			spaces = make(map[string]*membership)
+			s.memberships[userID] = spaces
+		}
+
+		now := objectsTimestamp()
+		event := "update"
+		m, ok := spaces[spaceID]
+		if !ok {
+			m = &membership{Created: now}
+			spaces[spaceID] = m
+			event = "create"
+		}
+		m.Custom = in.Custom
+		m.Updated = now
+		m.ETag = objectsETag(s.nextTimetokenLocked())
+
+		s.announceMembershipLocked(userID, spaceID, event, m)
+	}
+
+	for _, in := range changes.Remove {
+		userID, spaceID := userSpace(in.ID)
+		if m, ok := s.memberships[userID][spaceID]; ok {
+			delete(s.memberships[userID], spaceID)
+			s.announceMembershipLocked(userID, spaceID, "delete", m)
+		}
+	}
+
+	data := []map[string]interface{}{}
+
+	if kind == usersKind {
+		spaceIDs := make([]string, 0, len(s.memberships[id]))
+		for spaceID := range s.memberships[id] {
+			spaceIDs = append(spaceIDs, spaceID)
+		}
+		sort.Strings(spaceIDs)
+
+		for _, spaceID := range spaceIDs {
+			item := membershipData(s.memberships[id][spaceID])
+			item["id"] = spaceID
+			item["space"] = s.spaces[spaceID]
+			data = append(data, item)
+		}
+	} else {
+		userIDs := make([]string, 0, len(s.memberships))
+		for userID, spaces := range s.memberships {
+			if _, ok := spaces[id]; ok {
+				userIDs = append(userIDs, userID)
+			}
+		}
+		sort.Strings(userIDs)
+
+		for _, userID := range userIDs {
+			item := membershipData(s.memberships[userID][id])
+			item["id"] = userID
+			item["user"] = s.users[userID]
+			data = append(data, item)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":     200,
+		"data":       data,
+		"totalCount": len(data),
+	})
+}
+
+func membershipData(m *membership) map[string]interface{} {
+	return map[string]interface{}{
+		"custom":  m.Custom,
+		"created": m.Created,
+		"updated": m.Updated,
+		"eTag":    m.ETag,
+	}
+}
+
+// announceObjectsLocked publishes the objects event on the channel named after the object.
+func (s *Server) announceObjectsLocked(channel, event, eventType string, data interface{}) {
+	payload, _ := json.Marshal(objectsEvent{
+		Source:  "objects",
+		Version: "1.0",
+		Event:   event,
+		Type:    eventType,
+		Data:    data,
+	})
+
+	s.addMessageLocked(&message{
+		Channel: channel,
+		Type:    2,
+		Payload: payload,
+	})
+}
+
+// announceMembershipLocked publishes the membership event on the channels of both the user and the space.
+func (s *Server) announceMembershipLocked(userID, spaceID, event string, m *membership) {
+	data := membershipData(m)
+	data["userId"] = userID
+	data["spaceId"] = spaceID
+	data["timestamp"] = m.Updated
+
+	s.announceObjectsLocked(userID, event, "membership", data)
+	s.announceObjectsLocked(spaceID, event, "membership", data)
+}
@@ -0,0 +1,385 @@
+package pubnubtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// occupant is a client present on a channel.
+type occupant struct {
+	expires time.Time
+}
+
+type presenceEvent struct {
+	Action    string          `json:"action"`
+	UUID      string          `json:"uuid"`
+	Timestamp int64           `json:"timestamp"`
+	Occupancy int             `json:"occupancy"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// Occupants returns the sorted UUIDs present on the channel.
+func (s *Server) Occupants(channel string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.occupantsLocked(channel)
+}
+
+// Timeout times out the uuid on the channel right away, as if it stopped sending heartbeats.
+func (s *Server) Timeout(channel, uuid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.presence[channel][uuid]; ok {
+		s.removeOccupantLocked(channel, uuid, "timeout")
+	}
+}
+
+func (s *Server) occupantsLocked(channel string) []string {
+	uuids := make([]string, 0, len(s.presence[channel]))
+	for uuid := range s.presence[channel] {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+
+	return uuids
+}
+
+// presenceTimeout returns the heartbeat param of the request, or the PresenceTimeout.
+func (s *Server) presenceTimeout(q url.Values) time.Duration {
+	if secs, err := strconv.Atoi(q.Get("heartbeat")); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+
+	return s.PresenceTimeout
+}
+
+// joinLocked marks the uuid present on the channels until the timeout, and announces the new occupants.
+func (s *Server) joinLocked(uuid string, channels []string, timeout time.Duration,
+	state map[string]json.RawMessage) {
+
+	if uuid == "" {
+		return
+	}
+
+	expires := time.Now().Add(timeout)
+
+	for _, ch := range channels {
+		if st, ok := state[ch]; ok {
+			s.setStateLocked(ch, uuid, st)
+		}
+
+		occupants := s.presence[ch]
+		if occupants == nil {
+			occupants = make(map[string]*occupant)
+			s.presence[ch] = occupants
+		}
+
+		o, ok := occupants[uuid]
+		if !ok {
+			o = &occupant{}
+			occupants[uuid] = o
+			s.announcePresenceLocked(ch, "join", uuid, s.states[ch][uuid])
+		}
+		o.expires = expires
+	}
+}
+
+// leaveLocked removes the uuid from the channels it is present on.
+func (s *Server) leaveLocked(uuid string, channels []string) {
+	for _, ch := range channels {
+		if _, ok := s.presence[ch][uuid]; ok {
+			s.removeOccupantLocked(ch, uuid, "leave")
+		}
+	}
+}
+
+func (s *Server) removeOccupantLocked(channel, uuid, action string) {
+	delete(s.presence[channel], uuid)
+	if len(s.presence[channel]) == 0 {
+		delete(s.presence, channel)
+	}
+
+	delete(s.states[channel], uuid)
+
+	s.announcePresenceLocked(channel, action, uuid, nil)
+}
+
+// expirePresence times out the occupants which did not send a heartbeat in time.
+func (s *Server) expirePresence(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channels := make([]string, 0, len(s.presence))
+	for ch := range s.presence {
+		channels = append(channels, ch)
+	}
+	sort.Strings(channels)
+
+	for _, ch := range channels {
+		for _, uuid := range s.occupantsLocked(ch) {
+			if now.After(s.presence[ch][uuid].expires) {
+				s.removeOccupantLocked(ch, uuid, "timeout")
+			}
+		}
+	}
+}
+
+func (s *Server) announcePresenceLocked(channel, action, uuid string, data json.RawMessage) {
+	payload, _ := json.Marshal(presenceEvent{
+		Action:    action,
+		UUID:      uuid,
+		Timestamp: time.Now().Unix(),
+		Occupancy: len(s.presence[channel]),
+		Data:      data,
+	})
+
+	s.addMessageLocked(&message{
+		Channel: channel + presenceSuffix,
+		Payload: payload,
+	})
+}
+
+func (s *Server) setStateLocked(channel, uuid string, state json.RawMessage) {
+	states := s.states[channel]
+	if states == nil {
+		states = make(map[string]json.RawMessage)
+		s.states[channel] = states
+	}
+	states[uuid] = state
+}
+
+// parseChannelsState parses the state param of the subscribe and heartbeat requests, keyed by channel.
+func parseChannelsState(v string) (map[string]json.RawMessage, error) {
+	if v == "" {
+		return nil, nil
+	}
+
+	var state map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(v), &state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func (s *Server) routePresence(w http.ResponseWriter, req *http.Request, segments []string) {
+	if len(segments) < 2 || (segments[0] != "sub-key" && segments[0] != "sub_key") {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	q := req.URL.Query()
+	groups := splitList(q.Get("channel-group"))
+
+	switch {
+	case len(segments) == 2:
+		s.handleHereNow(w, q, nil, nil, true)
+	case len(segments) == 4 && segments[2] == "uuid":
+		s.handleWhereNow(w, segments[3])
+	case len(segments) == 4 && segments[2] == "channel":
+		s.handleHereNow(w, q, splitList(segments[3]), groups, false)
+	case len(segments) == 5 && segments[2] == "channel" && segments[4] == "leave":
+		s.handleLeave(w, q, splitList(segments[3]), groups)
+	case len(segments) == 5 && segments[2] == "channel" && segments[4] == "heartbeat":
+		s.handleHeartbeat(w, q, splitList(segments[3]), groups)
+	case len(segments) == 6 && segments[2] == "channel" && segments[4] == "uuid":
+		s.handleGetState(w, splitList(segments[3]), groups, segments[5])
+	case len(segments) == 7 && segments[2] == "channel" && segments[4] == "uuid" && segments[6] == "data":
+		s.handleSetState(w, q, splitList(segments[3]), groups, segments[5])
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) handleLeave(w http.ResponseWriter, q url.Values, channels, groups []string) {
+	s.mu.Lock()
+	s.leaveLocked(q.Get("uuid"), append(channels, s.groupChannelsLocked(groups)...))
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  200,
+		"message": "OK",
+		"action":  "leave",
+		"service": "Presence",
+	})
+}
+
+func (s *Server) handleHeartbeat(w http.ResponseWriter, q url.Values, channels, groups []string) {
+	state, err := parseChannelsState(q.Get("state"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid state")
+		return
+	}
+
+	s.mu.Lock()
+	s.joinLocked(q.Get("uuid"), append(channels, s.groupChannelsLocked(groups)...), s.presenceTimeout(q), state)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  200,
+		"message": "OK",
+		"service": "Presence",
+	})
+}
+
+// hereNowChannelLocked returns the occupancy and the occupants of the channel, in the here now format.
+func (s *Server) hereNowChannelLocked(channel string, includeUUIDs, includeState bool) map[string]interface{} {
+	data := map[string]interface{}{
+		"occupancy": len(s.presence[channel]),
+	}
+
+	if includeUUIDs {
+		uuids := []interface{}{}
+		for _, uuid := range s.occupantsLocked(channel) {
+			if !includeState {
+				uuids = append(uuids, uuid)
+				continue
+			}
+
+			occupant := map[string]interface{}{"uuid": uuid}
+			if st, ok := s.states[channel][uuid]; ok {
+				occupant["state"] = st
+			}
+			uuids = append(uuids, occupant)
+		}
+		data["uuids"] = uuids
+	}
+
+	return data
+}
+
+func (s *Server) handleHereNow(w http.ResponseWriter, q url.Values, channels, groups []string, global bool) {
+	includeUUIDs := q.Get("disable-uuids") != "1"
+	includeState := q.Get("state") == "1"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !global && len(channels) == 1 && len(groups) == 0 {
+		res := s.hereNowChannelLocked(channels[0], includeUUIDs, includeState)
+		res["status"] = 200
+		res["message"] = "OK"
+		res["service"] = "Presence"
+
+		writeJSON(w, http.StatusOK, res)
+		return
+	}
+
+	if global {
+		for ch := range s.presence {
+			channels = append(channels, ch)
+		}
+	} else {
+		channels = append(channels, s.groupChannelsLocked(groups)...)
+	}
+
+	data := make(map[string]interface{}, len(channels))
+	totalOccupancy := 0
+
+	// like the service, the channels without occupants are omitted
+	for _, ch := range channels {
+		if len(s.presence[ch]) == 0 {
+			continue
+		}
+		data[ch] = s.hereNowChannelLocked(ch, includeUUIDs, includeState)
+		totalOccupancy += len(s.presence[ch])
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  200,
+		"message": "OK",
+		"service": "Presence",
+		"payload": map[string]interface{}{
+			"channels":        data,
+			"total_channels":  len(data),
+			"total_occupancy": totalOccupancy,
+		},
+	})
+}
+
+func (s *Server) handleWhereNow(w http.ResponseWriter, uuid string) {
+	s.mu.Lock()
+
+	channels := []string{}
+	for ch, occupants := range s.presence {
+		if _, ok := occupants[uuid]; ok {
+			channels = append(channels, ch)
+		}
+	}
+	sort.Strings(channels)
+
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  200,
+		"message": "OK",
+		"service": "Presence",
+		"payload": map[string]interface{}{
+			"channels": channels,
+		},
+	})
+}
+
+func (s *Server) handleGetState(w http.ResponseWriter, channels, groups []string, uuid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stateOf := func(ch string) json.RawMessage {
+		if st, ok := s.states[ch][uuid]; ok {
+			return st
+		}
+		return json.RawMessage("{}")
+	}
+
+	res := map[string]interface{}{
+		"status":  200,
+		"message": "OK",
+		"service": "Presence",
+		"uuid":    uuid,
+	}
+
+	if len(channels) == 1 && len(groups) == 0 {
+		res["channel"] = channels[0]
+		res["payload"] = stateOf(channels[0])
+	} else {
+		states := make(map[string]json.RawMessage)
+		for _, ch := range append(channels, s.groupChannelsLocked(groups)...) {
+			states[ch] = stateOf(ch)
+		}
+		res["payload"] = map[string]interface{}{"channels": states}
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleSetState(w http.ResponseWriter, q url.Values, channels, groups []string, uuid string) {
+	var state map[string]interface{}
+	if err := json.Unmarshal([]byte(q.Get("state")), &state); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid state")
+		return
+	}
+	raw := json.RawMessage(q.Get("state"))
+
+	s.mu.Lock()
+	for _, ch := range append(channels, s.groupChannelsLocked(groups)...) {
+		s.setStateLocked(ch, uuid, raw)
+
+		if _, ok := s.presence[ch][uuid]; ok {
+			s.announcePresenceLocked(ch, "state-change", uuid, raw)
+		}
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  200,
+		"message": "OK",
+		"service": "Presence",
+		"payload": raw,
+	})
+}
@@ -0,0 +1,466 @@
+// Package pubnubtest provides an in-memory PubNub server for the tests which
+// can not reach the network.
+//
+// The Server implements publish, signal, subscribe long-polls with timetokens,
+// presence (join, leave, timeout, state, here now and where now), history,
+// channel groups and the Objects API (users, spaces, memberships and members).
+// It is used either as the Transport of the http.Client of the PubNub instance:
+//
+//	srv := pubnubtest.NewServer()
+//	defer srv.Close()
+//
+//	pn := pubnub.NewPubNub(config)
+//	pn.SetClient(srv.Client())
+//	pn.SetSubscribeClient(srv.Client())
+//
+// or, when only the origin can be configured, over HTTP on a local port:
+//
+//	srv.Start()
+//	config.Origin = srv.Origin()
+//	config.Secure = false
+//
+// The Server keeps a single keyset, the publish and subscribe keys of the
+// requests are not checked.
+package pubnubtest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSubscribeTimeout = 280 * time.Second
+	defaultPresenceTimeout  = 300 * time.Second
+	defaultRegion           = 1
+	presenceSweepInterval   = 250 * time.Millisecond
+	maxSubscribeMessages    = 100
+)
+
+// message is a message stored by the Server, in the shape it is delivered to the subscribers.
+type message struct {
+	Channel   string
+	Timetoken int64
+	Type      int
+	Issuer    string
+	Sequence  int64
+	Payload   json.RawMessage
+	Meta      json.RawMessage
+	Stored    bool
+}
+
+// Server is an in-memory PubNub server. Its fields must be set before the first request.
+type Server struct {
+	// SubscribeTimeout is how long a subscribe long-poll waits for the messages. Defaults to 280 seconds.
+	SubscribeTimeout time.Duration
+	// PresenceTimeout is how long a client stays present without a subscribe or a heartbeat,
+	// when the request does not set the heartbeat param. Defaults to 300 seconds.
+	PresenceTimeout time.Duration
+	// Region is the region returned with the subscribe timetokens. Defaults to 1.
+	Region int
+
+	mu            sync.Mutex
+	lastTimetoken int64
+	messages      []*message
+	notify        chan struct{}
+	groups        map[string]map[string]bool
+	presence      map[string]map[string]*occupant
+	states        map[string]map[string]json.RawMessage
+	users         map[string]object
+	spaces        map[string]object
+	memberships   map[string]map[string]*membership
+	failures      []*failure
+
+	httpServer *httptest.Server
+	done       chan struct{}
+	closeOnce  sync.Once
+	wg         sync.WaitGroup
+}
+
+// failure is a response the Server returns instead of serving the requests of an endpoint.
+type failure struct {
+	segments   []string
+	count      int
+	statusCode int
+	header     http.Header
+}
+
+// NewServer returns a Server ready to be used as a http.RoundTripper.
+// It must be closed to stop its presence timeout sweep.
+func NewServer() *Server {
+	s := &Server{
+		SubscribeTimeout: defaultSubscribeTimeout,
+		PresenceTimeout:  defaultPresenceTimeout,
+		Region:           defaultRegion,
+		notify:           make(chan struct{}),
+		groups:           make(map[string]map[string]bool),
+		presence:         make(map[string]map[string]*occupant),
+		states:           make(map[string]map[string]json.RawMessage),
+		users:            make(map[string]object),
+		spaces:           make(map[string]object),
+		memberships:      make(map[string]map[string]*membership),
+		done:             make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.sweepPresence()
+
+	return s
+}
+
+// Start serves the Server over HTTP on a local port. Use Origin with Config.Origin
+// and set Config.Secure to false.
+func (s *Server) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.httpServer == nil {
+		s.httpServer = httptest.NewServer(s)
+	}
+}
+
+// Origin returns the host and port the Server listens on, once started.
+func (s *Server) Origin() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.httpServer == nil {
+		return ""
+	}
+
+	u, err := url.Parse(s.httpServer.URL)
+	if err != nil {
+		return ""
+	}
+
+	return u.Host
+}
+
+// Client returns a http.Client which sends its requests to the Server.
+func (s *Server) Client() *http.Client {
+	return &http.Client{Transport: s}
+}
+
+// Close releases the pending subscribe long-polls, stops the presence timeout sweep
+// and the HTTP server, if started.
+func (s *Server) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+
+		s.mu.Lock()
+		httpServer := s.httpServer
+		s.mu.Unlock()
+
+		if httpServer != nil {
+			httpServer.Close()
+		}
+	})
+}
+
+// RoundTrip serves the req in memory.
+func (s *Server) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	res := rec.Result()
+	res.Request = req
+
+	return res, nil
+}
+
+// ServeHTTP routes the req to the PubNub endpoint.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	segments, err := pathSegments(req.URL)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid path")
+		return
+	}
+
+	if f := s.takeFailure(segments); f != nil {
+		for k, v := range f.header {
+			w.Header()[k] = v
+		}
+		writeError(w, f.statusCode, http.StatusText(f.statusCode))
+		return
+	}
+
+	switch {
+	case match(segments, "time", "0"):
+		writeJSON(w, http.StatusOK, []int64{s.nextTimetoken()})
+	case match(segments, "publish", "", "", "0", ""):
+		s.handlePublish(w, req, segments, 0)
+	case match(segments, "signal", "", "", "0", ""):
+		s.handlePublish(w, req, segments, 1)
+	case match(segments, "v2", "subscribe", "", "", "0"):
+		s.handleSubscribe(w, req, segments[2], splitList(segments[3]))
+	case match(segments, "v2", "presence"):
+		s.routePresence(w, req, segments[2:])
+	case match(segments, "v2", "history", "sub-key", "", "channel", "") && len(segments) == 6:
+		s.handleHistory(w, req, segments[5])
+	case match(segments, "v3", "history", "sub-key", "", "channel", "") && len(segments) == 6:
+		if req.Method == http.MethodDelete {
+			s.handleDeleteMessages(w, req, splitList(segments[5]))
+		} else {
+			s.handleFetch(w, req, splitList(segments[5]))
+		}
+	case match(segments, "v3", "history", "sub-key", "", "message-counts", "") && len(segments) == 6:
+		s.handleMessageCounts(w, req, splitList(segments[5]))
+	case match(segments, "v1", "channel-registration", "sub-key", "", "channel-group", ""):
+		s.routeChannelGroup(w, req, segments[5:])
+	case match(segments, "v1", "objects", "", ""):
+		s.routeObjects(w, req, segments[2:])
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// FailNext makes the next count requests whose path starts with the path segments fail with the
+// statusCode, with the header set on the responses, for ex. to test the handling of the rate limits:
+//
+//	srv.FailNext(1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}}, "v2", "subscribe")
+//
+// An empty segment matches any segment.
+func (s *Server) FailNext(count, statusCode int, header http.Header, segments ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = append(s.failures, &failure{
+		segments:   segments,
+		count:      count,
+		statusCode: statusCode,
+		header:     header,
+	})
+}
+
+// takeFailure returns the failure matching the segments of a request, if any, and counts it.
+func (s *Server) takeFailure(segments []string) *failure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.failures {
+		if !match(segments, f.segments...) {
+			continue
+		}
+
+		f.count--
+		if f.count <= 0 {
+			s.failures = append(s.failures[:i], s.failures[i+1:]...)
+		}
+		return f
+	}
+
+	return nil
+}
+
+// nextTimetoken returns a timetoken greater than all the previous ones.
+func (s *Server) nextTimetoken() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.nextTimetokenLocked()
+}
+
+func (s *Server) nextTimetokenLocked() int64 {
+	tt := time.Now().UnixNano() / 100
+	if tt <= s.lastTimetoken {
+		tt = s.lastTimetoken + 1
+	}
+	s.lastTimetoken = tt
+
+	return tt
+}
+
+// addMessageLocked stores the m with a new timetoken and wakes up the pending subscribe long-polls.
+func (s *Server) addMessageLocked(m *message) int64 {
+	m.Timetoken = s.nextTimetokenLocked()
+	s.messages = append(s.messages, m)
+
+	close(s.notify)
+	s.notify = make(chan struct{})
+
+	return m.Timetoken
+}
+
+// Publish publishes the msg to the channel on behalf of the issuer, as if it was published by a client.
+// It returns the timetoken of the message.
+func (s *Server) Publish(channel, issuer string, msg interface{}) (int64, error) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addMessageLocked(&message{
+		Channel: channel,
+		Issuer:  issuer,
+		Payload: payload,
+		Stored:  true,
+	}), nil
+}
+
+func (s *Server) handlePublish(w http.ResponseWriter, req *http.Request, segments []string, messageType int) {
+	var payload []byte
+
+	if req.Method == http.MethodPost {
+		body, err := readBody(req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid body")
+			return
+		}
+		payload = body
+	} else if len(segments) == 7 {
+		payload = []byte(segments[6])
+	}
+
+	if !json.Valid(payload) {
+		writeJSON(w, http.StatusBadRequest, []interface{}{0, "Invalid JSON", "0"})
+		return
+	}
+
+	q := req.URL.Query()
+
+	var meta json.RawMessage
+	if v := q.Get("meta"); v != "" {
+		if !json.Valid([]byte(v)) {
+			writeJSON(w, http.StatusBadRequest, []interface{}{0, "Invalid Meta", "0"})
+			return
+		}
+		meta = json.RawMessage(v)
+	}
+
+	seqn, _ := strconv.ParseInt(q.Get("seqn"), 10, 64)
+
+	s.mu.Lock()
+	tt := s.addMessageLocked(&message{
+		Channel:  segments[4],
+		Type:     messageType,
+		Issuer:   q.Get("uuid"),
+		Sequence: seqn,
+		Payload:  payload,
+		Meta:     meta,
+		Stored:   messageType == 0 && q.Get("store") != "0",
+	})
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, []interface{}{1, "Sent", formatTimetoken(tt)})
+}
+
+func (s *Server) sweepPresence() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(presenceSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			s.expirePresence(now)
+		}
+	}
+}
+
+// pathSegments returns the unescaped segments of the path of the u,
+// so that the escaped slashes of a published message do not split it.
+// The requests sent in memory keep the "//origin/path" opaque URL built by the client.
+func pathSegments(u *url.URL) ([]string, error) {
+	path := u.EscapedPath()
+	if strings.HasPrefix(u.Opaque, "//") {
+		path = u.Opaque[2:]
+		if i := strings.Index(path, "/"); i >= 0 {
+			path = path[i:]
+		}
+	}
+
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	for i, segment := range segments {
+		v, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, err
+		}
+		segments[i] = v
+	}
+
+	return segments, nil
+}
+
+// match returns true if the segments start with the pattern, an empty pattern segment matches any segment.
+func match(segments []string, pattern ...string) bool {
+	if len(segments) < len(pattern) {
+		return false
+	}
+
+	for i, p := range pattern {
+		if p != "" && p != segments[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitList splits a comma separated list of channels or groups, "," being the empty list.
+func splitList(v string) []string {
+	var items []string
+
+	for _, item := range strings.Split(v, ",") {
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	defer req.Body.Close()
+
+	return ioutil.ReadAll(req.Body)
+}
+
+func formatTimetoken(tt int64) string {
+	return strconv.FormatInt(tt, 10)
+}
+
+func parseTimetoken(v string) int64 {
+	tt, _ := strconv.ParseInt(v, 10, 64)
+	return tt
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		b = []byte(`{"status":500,"error":true,"message":"Internal Server Error"}`)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(b)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, msg string) {
+	writeJSON(w, statusCode, map[string]interface{}{
+		"status":  statusCode,
+		"error":   true,
+		"message": msg,
+	})
+}
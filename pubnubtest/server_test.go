@@ -0,0 +1,370 @@
+package pubnubtest_test
+
+import (
+	"testing"
+	"time"
+
+	pubnub "github.com/sprucehealth/pubnub-go"
+	"github.com/sprucehealth/pubnub-go/pubnubtest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTimeout = 5 * time.Second
+
+func newTestPubNub(srv *pubnubtest.Server, uuid string) *pubnub.PubNub {
+	config := pubnub.NewConfig()
+	config.PublishKey = "pub-key"
+	config.SubscribeKey = "sub-key"
+	config.UUID = uuid
+
+	pn := pubnub.NewPubNub(config)
+	pn.SetClient(srv.Client())
+	pn.SetSubscribeClient(srv.Client())
+
+	return pn
+}
+
+func waitForStatus(t *testing.T, listener *pubnub.Listener, category pubnub.StatusCategory) {
+	timeout := time.After(testTimeout)
+	for {
+		select {
+		case status := <-listener.Status:
+			if status.Category == category {
+				return
+			}
+		case <-listener.Presence:
+		case <-timeout:
+			t.Fatalf("timed out waiting for the %s status", category)
+		}
+	}
+}
+
+func waitForMessage(t *testing.T, listener *pubnub.Listener) *pubnub.PNMessage {
+	select {
+	case msg := <-listener.Message:
+		return msg
+	case <-time.After(testTimeout):
+		t.Fatal("timed out waiting for a message")
+	}
+	return nil
+}
+
+func waitForPresence(t *testing.T, listener *pubnub.Listener, event, uuid string) *pubnub.PNPresence {
+	timeout := time.After(testTimeout)
+	for {
+		select {
+		case presence := <-listener.Presence:
+			if presence.Event == event && presence.UUID == uuid {
+				return presence
+			}
+		case <-listener.Status:
+		case <-timeout:
+			t.Fatalf("timed out waiting for the %s of %s", event, uuid)
+			return nil
+		}
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	assert := assert.New(t)
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	subscriber := newTestPubNub(srv, "subscriber")
+	defer subscriber.UnsubscribeAll()
+	publisher := newTestPubNub(srv, "publisher")
+
+	listener := pubnub.NewListener()
+	subscriber.AddListener(listener)
+	subscriber.Subscribe().Channels([]string{"ch"}).Execute()
+	waitForStatus(t, listener, pubnub.PNConnectedCategory)
+
+	res, _, err := publisher.Publish().Channel("ch").Message(map[string]interface{}{"text": "hey"}).
+		Meta(map[string]interface{}{"k": "v"}).Execute()
+	assert.Nil(err)
+
+	msg := waitForMessage(t, listener)
+	assert.Equal("ch", msg.Channel)
+	assert.Equal(map[string]interface{}{"text": "hey"}, msg.Message)
+	assert.Equal(map[string]interface{}{"k": "v"}, msg.UserMetadata)
+	assert.Equal("publisher", msg.Publisher)
+	assert.Equal(res.Timestamp, msg.Timetoken)
+
+	_, err = srv.Publish("ch", "server", "hello")
+	assert.Nil(err)
+	assert.Equal("hello", waitForMessage(t, listener).Message)
+
+	_, _, err = publisher.Publish().Channel("ch").Message("a/b?c").UsePost(true).Execute()
+	assert.Nil(err)
+	assert.Equal("a/b?c", waitForMessage(t, listener).Message)
+}
+
+func TestSubscribeTimeout(t *testing.T) {
+	assert := assert.New(t)
+	srv := pubnubtest.NewServer()
+	srv.SubscribeTimeout = 20 * time.Millisecond
+	defer srv.Close()
+
+	pn := newTestPubNub(srv, "subscriber")
+	defer pn.UnsubscribeAll()
+
+	listener := pubnub.NewListener()
+	pn.AddListener(listener)
+	pn.Subscribe().Channels([]string{"ch"}).Execute()
+	waitForStatus(t, listener, pubnub.PNConnectedCategory)
+
+	// the long-polls which time out are renewed with the same timetoken
+	time.Sleep(100 * time.Millisecond)
+
+	_, err := srv.Publish("ch", "server", "late")
+	assert.Nil(err)
+	assert.Equal("late", waitForMessage(t, listener).Message)
+}
+
+func TestPresence(t *testing.T) {
+	assert := assert.New(t)
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	watcher := newTestPubNub(srv, "watcher")
+	defer watcher.UnsubscribeAll()
+
+	listener := pubnub.NewListener()
+	watcher.AddListener(listener)
+	watcher.Subscribe().Channels([]string{"room"}).WithPresence(true).Execute()
+	waitForPresence(t, listener, "join", "watcher")
+
+	visitor := newTestPubNub(srv, "visitor")
+	visitor.Subscribe().Channels([]string{"room"}).
+		State(map[string]interface{}{"mood": "happy"}).Execute()
+
+	join := waitForPresence(t, listener, "join", "visitor")
+	assert.Equal("room", join.Channel)
+	assert.Equal(map[string]interface{}{"mood": "happy"}, join.State)
+	assert.Equal([]string{"visitor", "watcher"}, srv.Occupants("room"))
+
+	hereNow, _, err := watcher.HereNow().Channels([]string{"room"}).IncludeState(true).Execute()
+	assert.Nil(err)
+	assert.Equal(2, hereNow.TotalOccupancy)
+	assert.Equal("visitor", hereNow.Channels[0].Occupants[0].UUID)
+	assert.Equal(map[string]interface{}{"mood": "happy"}, hereNow.Channels[0].Occupants[0].State)
+
+	whereNow, _, err := watcher.WhereNow().UUID("visitor").Execute()
+	assert.Nil(err)
+	assert.Equal([]string{"room"}, whereNow.Channels)
+
+	_, _, err = visitor.SetState().Channels([]string{"room"}).State(map[string]interface{}{"mood": "sad"}).Execute()
+	assert.Nil(err)
+	change := waitForPresence(t, listener, "state-change", "visitor")
+	assert.Equal(map[string]interface{}{"mood": "sad"}, change.State)
+
+	state, _, err := watcher.GetState().Channels([]string{"room"}).UUID("visitor").Execute()
+	assert.Nil(err)
+	assert.Equal(map[string]interface{}{"mood": "sad"}, state.State["room"])
+
+	visitor.UnsubscribeAll()
+	waitForPresence(t, listener, "leave", "visitor")
+	assert.Equal([]string{"watcher"}, srv.Occupants("room"))
+
+	srv.Timeout("room", "watcher")
+	waitForPresence(t, listener, "timeout", "watcher")
+}
+
+func TestPresenceExpires(t *testing.T) {
+	srv := pubnubtest.NewServer()
+	srv.PresenceTimeout = 200 * time.Millisecond
+	defer srv.Close()
+
+	watcher := newTestPubNub(srv, "watcher")
+	defer watcher.UnsubscribeAll()
+
+	listener := pubnub.NewListener()
+	watcher.AddListener(listener)
+	watcher.Subscribe().Channels([]string{"room"}).WithPresence(true).Execute()
+	waitForPresence(t, listener, "join", "watcher")
+
+	// the visitor does not send heartbeats while its long-poll is pending
+	visitor := newTestPubNub(srv, "visitor")
+	defer visitor.UnsubscribeAll()
+	visitor.Subscribe().Channels([]string{"room"}).Execute()
+
+	waitForPresence(t, listener, "join", "visitor")
+	waitForPresence(t, listener, "timeout", "visitor")
+}
+
+func TestHistory(t *testing.T) {
+	assert := assert.New(t)
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	pn := newTestPubNub(srv, "publisher")
+	pn.Config.SecretKey = "secret-key"
+
+	var timetokens []int64
+	for _, msg := range []string{"one", "two", "three"} {
+		res, _, err := pn.Publish().Channel("ch").Message(msg).Execute()
+		assert.Nil(err)
+		timetokens = append(timetokens, res.Timestamp)
+	}
+
+	_, _, err := pn.Fire().Channel("ch").Message("not stored").Execute()
+	assert.Nil(err)
+
+	history, _, err := pn.History().Channel("ch").IncludeTimetoken(true).Execute()
+	assert.Nil(err)
+	assert.Len(history.Messages, 3)
+	assert.Equal("one", history.Messages[0].Message)
+	assert.Equal(timetokens[0], history.Messages[0].Timetoken)
+	assert.Equal(timetokens[0], history.StartTimetoken)
+	assert.Equal(timetokens[2], history.EndTimetoken)
+
+	history, _, err = pn.History().Channel("ch").Count(2).Execute()
+	assert.Nil(err)
+	assert.Equal("two", history.Messages[0].Message)
+	assert.Equal("three", history.Messages[1].Message)
+
+	history, _, err = pn.History().Channel("ch").Count(2).Reverse(true).Execute()
+	assert.Nil(err)
+	assert.Equal("one", history.Messages[0].Message)
+	assert.Equal("two", history.Messages[1].Message)
+
+	fetch, _, err := pn.Fetch().Channels([]string{"ch"}).Start(timetokens[2]).Execute()
+	assert.Nil(err)
+	assert.Len(fetch.Messages["ch"], 2)
+	assert.Equal("two", fetch.Messages["ch"][1].Message)
+
+	counts, _, err := pn.MessageCounts().Channels([]string{"ch"}).ChannelsTimetoken([]int64{timetokens[1]}).Execute()
+	assert.Nil(err)
+	assert.Equal(2, counts.Channels["ch"])
+
+	_, _, err = pn.DeleteMessages().Channel("ch").Execute()
+	assert.Nil(err)
+
+	history, _, err = pn.History().Channel("ch").Execute()
+	assert.Nil(err)
+	assert.Empty(history.Messages)
+}
+
+func TestChannelGroups(t *testing.T) {
+	assert := assert.New(t)
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	pn := newTestPubNub(srv, "subscriber")
+	defer pn.UnsubscribeAll()
+
+	_, _, err := pn.AddChannelToChannelGroup().ChannelGroup("cg").Channels([]string{"ch1", "ch2", "ch3"}).Execute()
+	assert.Nil(err)
+	_, _, err = pn.RemoveChannelFromChannelGroup().ChannelGroup("cg").Channels([]string{"ch3"}).Execute()
+	assert.Nil(err)
+
+	list, _, err := pn.ListChannelsInChannelGroup().ChannelGroup("cg").Execute()
+	assert.Nil(err)
+	assert.Equal([]string{"ch1", "ch2"}, list.Channels)
+
+	listener := pubnub.NewListener()
+	pn.AddListener(listener)
+	pn.Subscribe().ChannelGroups([]string{"cg"}).Execute()
+	waitForStatus(t, listener, pubnub.PNConnectedCategory)
+
+	_, err = srv.Publish("ch2", "server", "to the group")
+	assert.Nil(err)
+
+	msg := waitForMessage(t, listener)
+	assert.Equal("ch2", msg.Channel)
+	assert.Equal("cg", msg.Subscription)
+	assert.Equal("to the group", msg.Message)
+
+	_, _, err = pn.DeleteChannelGroup().ChannelGroup("cg").Execute()
+	assert.Nil(err)
+
+	list, _, err = pn.ListChannelsInChannelGroup().ChannelGroup("cg").Execute()
+	assert.Nil(err)
+	assert.Empty(list.Channels)
+}
+
+func TestObjects(t *testing.T) {
+	assert := assert.New(t)
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	pn := newTestPubNub(srv, "admin")
+	defer pn.UnsubscribeAll()
+
+	listener := pubnub.NewListener()
+	pn.AddListener(listener)
+	pn.Subscribe().Channels([]string{"user-1"}).Execute()
+	waitForStatus(t, listener, pubnub.PNConnectedCategory)
+
+	user, _, err := pn.CreateUser().ID("user-1").Name("Ann").Email("ann@example.com").Execute()
+	assert.Nil(err)
+	assert.Equal("Ann", user.Data.Name)
+	assert.NotEmpty(user.Data.ETag)
+
+	select {
+	case event := <-listener.UserEvent:
+		assert.Equal(pubnub.PNObjectsEventCreate, event.Event)
+		assert.Equal("user-1", event.UserID)
+	case <-time.After(testTimeout):
+		t.Fatal("timed out waiting for the user event")
+	}
+
+	_, _, err = pn.CreateUser().ID("user-1").Name("Ann").Execute()
+	assert.NotNil(err)
+
+	updated, _, err := pn.UpdateUser().ID("user-1").Name("Anna").Execute()
+	assert.Nil(err)
+	assert.Equal("Anna", updated.Data.Name)
+
+	_, _, err = pn.CreateSpace().ID("space-1").Name("Lobby").Execute()
+	assert.Nil(err)
+
+	memberships, _, err := pn.ManageMemberships().UserID("user-1").
+		Add([]pubnub.PNMembershipsInput{{ID: "space-1", Custom: map[string]interface{}{"role": "admin"}}}).
+		Update([]pubnub.PNMembershipsInput{}).Remove([]pubnub.PNMembershipsRemove{}).Execute()
+	assert.Nil(err)
+	assert.Len(memberships.Data, 1)
+	assert.Equal("Lobby", memberships.Data[0].Space.Name)
+
+	members, _, err := pn.GetMembers().SpaceID("space-1").Execute()
+	assert.Nil(err)
+	assert.Len(members.Data, 1)
+	assert.Equal("user-1", members.Data[0].ID)
+	assert.Equal("admin", members.Data[0].Custom["role"])
+
+	users, _, err := pn.GetUsers().Count(true).Execute()
+	assert.Nil(err)
+	assert.Equal(1, users.TotalCount)
+
+	_, _, err = pn.DeleteUser().ID("user-1").Execute()
+	assert.Nil(err)
+
+	_, _, err = pn.GetUser().ID("user-1").Execute()
+	assert.NotNil(err)
+
+	members, _, err = pn.GetMembers().SpaceID("space-1").Execute()
+	assert.Nil(err)
+	assert.Empty(members.Data)
+}
+
+func TestStart(t *testing.T) {
+	assert := assert.New(t)
+	srv := pubnubtest.NewServer()
+	srv.Start()
+	defer srv.Close()
+
+	config := pubnub.NewConfig()
+	config.PublishKey = "pub-key"
+	config.SubscribeKey = "sub-key"
+	config.Origin = srv.Origin()
+	config.Secure = false
+
+	pn := pubnub.NewPubNub(config)
+
+	_, _, err := pn.Publish().Channel("ch").Message("over http").Execute()
+	assert.Nil(err)
+
+	history, _, err := pn.History().Channel("ch").Execute()
+	assert.Nil(err)
+	assert.Equal("over http", history.Messages[0].Message)
+}
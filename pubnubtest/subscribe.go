@@ -0,0 +1,214 @@
+package pubnubtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const presenceSuffix = "-pnpres"
+
+type subscribeTimetoken struct {
+	Timetoken string `json:"t"`
+	Region    int    `json:"r"`
+}
+
+type subscribeMessage struct {
+	Shard             string             `json:"a"`
+	SubscriptionMatch string             `json:"b,omitempty"`
+	Channel           string             `json:"c"`
+	Payload           json.RawMessage    `json:"d"`
+	MessageType       int                `json:"e"`
+	Flags             int                `json:"f"`
+	IssuingClientID   string             `json:"i,omitempty"`
+	SequenceNumber    int64              `json:"s,omitempty"`
+	SubscribeKey      string             `json:"k"`
+	UserMetadata      json.RawMessage    `json:"u,omitempty"`
+	PublishMetadata   subscribeTimetoken `json:"p"`
+}
+
+type subscribeEnvelope struct {
+	Metadata subscribeTimetoken `json:"t"`
+	Messages []subscribeMessage `json:"m"`
+}
+
+// subscription is the set of channels, wildcard channels and channel groups of a subscribe request.
+type subscription struct {
+	channels  map[string]bool
+	wildcards []string
+	groups    []string
+}
+
+func newSubscription(channels, groups []string) *subscription {
+	sub := &subscription{
+		channels: make(map[string]bool, len(channels)),
+		groups:   groups,
+	}
+
+	for _, ch := range channels {
+		if strings.HasSuffix(ch, ".*") {
+			sub.wildcards = append(sub.wildcards, ch)
+		} else {
+			sub.channels[ch] = true
+		}
+	}
+
+	return sub
+}
+
+// matchLocked returns the subscription the channel matches: the channel itself,
+// a wildcard channel or a channel group.
+func (sub *subscription) matchLocked(s *Server, channel string) (string, bool) {
+	if sub.channels[channel] {
+		return channel, true
+	}
+
+	if !strings.HasSuffix(channel, presenceSuffix) {
+		for _, w := range sub.wildcards {
+			if strings.HasPrefix(channel, strings.TrimSuffix(w, "*")) {
+				return w, true
+			}
+		}
+	}
+
+	for _, g := range sub.groups {
+		if strings.HasSuffix(g, presenceSuffix) {
+			base := strings.TrimSuffix(g, presenceSuffix)
+			if strings.HasSuffix(channel, presenceSuffix) && s.groups[base][strings.TrimSuffix(channel, presenceSuffix)] {
+				return g, true
+			}
+		} else if s.groups[g][channel] {
+			return g, true
+		}
+	}
+
+	return "", false
+}
+
+// presenceChannelsLocked returns the channels the client of the subscription is present on.
+func (sub *subscription) presenceChannelsLocked(s *Server) []string {
+	var channels []string
+
+	for ch := range sub.channels {
+		if !strings.HasSuffix(ch, presenceSuffix) {
+			channels = append(channels, ch)
+		}
+	}
+
+	return append(channels, s.groupChannelsLocked(sub.groups)...)
+}
+
+// handleSubscribe answers a handshake (tt=0) with the current timetoken, otherwise it waits
+// for the messages published after tt until the SubscribeTimeout.
+func (s *Server) handleSubscribe(w http.ResponseWriter, req *http.Request, subKey string, channels []string) {
+	q := req.URL.Query()
+	uuid := q.Get("uuid")
+	tt := parseTimetoken(q.Get("tt"))
+	sub := newSubscription(channels, splitList(q.Get("channel-group")))
+
+	state, err := parseChannelsState(q.Get("state"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid state")
+		return
+	}
+
+	s.mu.Lock()
+
+	handshake := tt == 0
+	if handshake {
+		// the timetoken is taken first for the client to receive its own join
+		tt = s.nextTimetokenLocked()
+	}
+
+	s.joinLocked(uuid, sub.presenceChannelsLocked(s), s.presenceTimeout(q), state)
+
+	if handshake {
+		s.mu.Unlock()
+		s.writeSubscribeEnvelope(w, tt, nil)
+		return
+	}
+
+	timer := time.NewTimer(s.SubscribeTimeout)
+	defer timer.Stop()
+
+	for {
+		messages, last := s.collectMessagesLocked(sub, subKey, tt)
+		if len(messages) > 0 {
+			s.mu.Unlock()
+			s.writeSubscribeEnvelope(w, last, messages)
+			return
+		}
+
+		notify := s.notify
+		s.mu.Unlock()
+
+		select {
+		case <-notify:
+			s.mu.Lock()
+		case <-timer.C:
+			s.writeSubscribeEnvelope(w, tt, nil)
+			return
+		case <-s.done:
+			s.writeSubscribeEnvelope(w, tt, nil)
+			return
+		case <-req.Context().Done():
+			return
+		}
+	}
+}
+
+// collectMessagesLocked returns the messages published after tt which match the sub,
+// and the timetoken of the last one.
+func (s *Server) collectMessagesLocked(sub *subscription, subKey string, tt int64) ([]subscribeMessage, int64) {
+	var messages []subscribeMessage
+	last := tt
+
+	for _, m := range s.messages {
+		if m.Timetoken <= tt {
+			continue
+		}
+
+		match, ok := sub.matchLocked(s, m.Channel)
+		if !ok {
+			continue
+		}
+
+		messages = append(messages, subscribeMessage{
+			Shard:             "0",
+			SubscriptionMatch: match,
+			Channel:           m.Channel,
+			Payload:           m.Payload,
+			MessageType:       m.Type,
+			IssuingClientID:   m.Issuer,
+			SequenceNumber:    m.Sequence,
+			SubscribeKey:      subKey,
+			UserMetadata:      m.Meta,
+			PublishMetadata: subscribeTimetoken{
+				Timetoken: formatTimetoken(m.Timetoken),
+				Region:    s.Region,
+			},
+		})
+		last = m.Timetoken
+
+		if len(messages) == maxSubscribeMessages {
+			break
+		}
+	}
+
+	return messages, last
+}
+
+func (s *Server) writeSubscribeEnvelope(w http.ResponseWriter, tt int64, messages []subscribeMessage) {
+	if messages == nil {
+		messages = []subscribeMessage{}
+	}
+
+	writeJSON(w, http.StatusOK, subscribeEnvelope{
+		Metadata: subscribeTimetoken{
+			Timetoken: formatTimetoken(tt),
+			Region:    s.Region,
+		},
+		Messages: messages,
+	})
+}
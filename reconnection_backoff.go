@@ -0,0 +1,81 @@
+package pubnub
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	reconnectionDefaultLinearDelay      = reconnectionInterval * time.Second
+	reconnectionDefaultExponentialDelay = time.Second
+	reconnectionDefaultMaxDelay         = 32 * time.Second
+)
+
+// ReconnectionBackoff sets the delays between the connectivity probes of the PNReconnectionPolicy,
+// once the network was found disconnected.
+type ReconnectionBackoff struct {
+	// Delay is the delay between the probes of PNLinearPolicy, 10 seconds by default, and the
+	// initial delay of PNExponentialPolicy, 1 second by default.
+	Delay time.Duration
+	// MaxDelay caps the delay of PNExponentialPolicy, which stays at MaxDelay once reached.
+	// Defaults to 32 seconds.
+	MaxDelay time.Duration
+	// FullJitter picks each delay at random between 0 and the delay of the policy, for the
+	// clients disconnected by the same outage not to reconnect at the same time.
+	FullJitter bool
+	// Func, when set, returns the delay before the probe following the failed attempt (starting
+	// at 1), the policy delays and FullJitter are ignored.
+	Func func(attempt int) time.Duration
+}
+
+// delay returns the delay before the probe following the failed attempt (starting at 1).
+func (b ReconnectionBackoff) delay(policy ReconnectionPolicy, attempt int) time.Duration {
+	if b.Func != nil {
+		return b.Func(attempt)
+	}
+
+	var d time.Duration
+	if policy == PNExponentialPolicy {
+		base := b.Delay
+		if base <= 0 {
+			base = reconnectionDefaultExponentialDelay
+		}
+		maxDelay := b.MaxDelay
+		if maxDelay <= 0 {
+			maxDelay = reconnectionDefaultMaxDelay
+		}
+
+		exp := math.Pow(2, float64(attempt-1))
+		if float64(base)*exp >= float64(maxDelay) {
+			d = maxDelay
+		} else {
+			d = time.Duration(float64(base) * exp)
+		}
+	} else {
+		d = b.Delay
+		if d <= 0 {
+			d = reconnectionDefaultLinearDelay
+		}
+	}
+
+	if b.FullJitter && d > 0 {
+		d = time.Duration(rand.Int63n(int64(d) + 1))
+	}
+
+	return d
+}
+
+// ConnectivityProbeFunc checks the connectivity to PubNub, the network is considered connected
+// when it returns nil. The ctx is done when the PubNub instance is destroyed.
+type ConnectivityProbeFunc func(ctx Context) error
+
+// probe checks the connectivity with the Config.ConnectivityProbe, or a Time request.
+func (m *ReconnectionManager) probe() error {
+	if probe := m.pubnub.Config.ConnectivityProbe; probe != nil {
+		return probe(m.pubnub.ctx)
+	}
+
+	_, _, err := m.pubnub.TimeWithContext(m.pubnub.ctx).Execute()
+	return err
+}
@@ -1,17 +1,13 @@
 package pubnub
 
 import (
-	"fmt"
-	"math"
 	"sync"
 	"time"
 )
 
-const (
-	reconnectionInterval              = 10
-	reconnectionMinExponentialBackoff = 1
-	reconnectionMaxExponentialBackoff = 32
-)
+// reconnectionInterval is the number of seconds between the connectivity probes while the
+// network is connected.
+const reconnectionInterval = 10
 
 // ReconnectionManager is used to store the properties required in running the Reconnection Manager.
 type ReconnectionManager struct {
@@ -19,15 +15,16 @@ type ReconnectionManager struct {
 
 	timerMutex sync.RWMutex
 
-	ExponentialMultiplier       int
 	FailedCalls                 int
 	Milliseconds                int
 	OnReconnection              func()
+	OnDisconnection             func(err error)
 	OnMaxReconnectionExhaustion func()
 	DoneTimer                   chan bool
 	hbRunning                   bool
 	pubnub                      *PubNub
 	exitReconnectionManager     chan bool
+	probeNow                    chan bool
 }
 
 func newReconnectionManager(pubnub *PubNub) *ReconnectionManager {
@@ -35,10 +32,9 @@ func newReconnectionManager(pubnub *PubNub) *ReconnectionManager {
 
 	manager.pubnub = pubnub
 
-	manager.ExponentialMultiplier = 1
 	manager.FailedCalls = 0
 	manager.Milliseconds = 1000
-	manager.exitReconnectionManager = make(chan bool)
+	manager.probeNow = make(chan bool, 1)
 	manager.hbRunning = false
 
 	return manager
@@ -51,6 +47,13 @@ func (m *ReconnectionManager) HandleReconnection(handler func()) {
 	m.Unlock()
 }
 
+// HandleDisconnection sets the handler that will be called when the network is found disconnected.
+func (m *ReconnectionManager) HandleDisconnection(handler func(err error)) {
+	m.Lock()
+	m.OnDisconnection = handler
+	m.Unlock()
+}
+
 // HandleOnMaxReconnectionExhaustion sets the handler that will be called when the max reconnection attempts are exhausted.
 func (m *ReconnectionManager) HandleOnMaxReconnectionExhaustion(handler func()) {
 	m.Lock()
@@ -61,110 +64,142 @@ func (m *ReconnectionManager) HandleOnMaxReconnectionExhaustion(handler func())
 func (m *ReconnectionManager) startPolling() {
 
 	if m.pubnub.Config.PNReconnectionPolicy == PNNonePolicy {
-		m.pubnub.Config.Log.Println("Reconnection policy is disabled, please handle reconnection manually.")
+		m.pubnub.Config.logger().Warn("reconnection policy is disabled, the reconnections must be handled by the app")
 		return
 	}
 
 	m.Lock()
-	m.ExponentialMultiplier = 1
-	m.FailedCalls = 0
 	hbRunning := m.hbRunning
+	if !hbRunning {
+		m.FailedCalls = 0
+	}
 	m.Unlock()
 
 	if !hbRunning {
-		m.pubnub.Config.Log.Println(fmt.Sprintf("Reconnection policy: %d, retries: %d", m.pubnub.Config.PNReconnectionPolicy, m.pubnub.Config.MaximumReconnectionRetries))
+		m.pubnub.Config.logger().Info("reconnecting", "policy", m.pubnub.Config.PNReconnectionPolicy, "max_retries", m.pubnub.Config.MaximumReconnectionRetries)
 
 		m.startHeartbeatTimer()
 	} else {
-		m.pubnub.Config.Log.Println("hb already running")
+		m.pubnub.Config.logger().Debug("reconnection already running")
 	}
 
 }
 
 func (m *ReconnectionManager) startHeartbeatTimer() {
 
-	timerInterval := reconnectionInterval
+	timerInterval := reconnectionInterval * time.Second
+
+	m.Lock()
+	m.hbRunning = true
+	exit := make(chan bool)
+	m.exitReconnectionManager = exit
+	m.Unlock()
+
+	// the loop stopped meanwhile, before the probes were marked as running
+	if sm := m.pubnub.subscriptionManager; sm != nil && sm.reconnectionManager == m && !sm.connection.running() {
+		m.pubnub.Config.logger().Debug("reconnection not started, the subscribe loop stopped")
+		m.stopped(exit)
+		return
+	}
 
 	for {
 
 		m.Lock()
-		m.hbRunning = true
 		failedCalls := m.FailedCalls
 		m.Unlock()
-		_, status, err := m.pubnub.Time().Execute()
-		if status.Error == nil {
+		err := m.probe()
+
+		select {
+		case <-exit:
+			m.pubnub.Config.logger().Debug("reconnection stopped")
+			return
+		default:
+		}
+
+		if err == nil {
+			timerInterval = reconnectionInterval * time.Second
 			if failedCalls > 0 {
-				timerInterval = reconnectionInterval
 				m.Lock()
 				m.FailedCalls = 0
 				m.Unlock()
-				m.pubnub.Config.Log.Println(fmt.Sprintf("Network reconnected"))
+				m.pubnub.Config.logger().Info("network reconnected")
+				m.OnReconnection()
+			} else if m.subscribeLost() {
+				m.pubnub.Config.logger().Info("network connected, resuming the subscribe loop")
 				m.OnReconnection()
 			}
 		} else {
-			if m.pubnub.Config.PNReconnectionPolicy == PNExponentialPolicy {
-				timerInterval = m.getExponentialInterval()
-			}
 			m.Lock()
 			m.FailedCalls++
-			m.pubnub.Config.Log.Println(fmt.Sprintf("Network disconnected, reconnection try %d of %d\n %v %v", m.FailedCalls, m.pubnub.Config.MaximumReconnectionRetries, status, err))
-			m.ExponentialMultiplier++
+			m.pubnub.Config.logger().Warn("network disconnected", "attempt", m.FailedCalls, "max_retries", m.pubnub.Config.MaximumReconnectionRetries, "error", err)
 
 			failedCalls := m.FailedCalls
 			retries := m.pubnub.Config.MaximumReconnectionRetries
+			onDisconnection := m.OnDisconnection
 			m.Unlock()
+			if failedCalls == 1 && onDisconnection != nil {
+				onDisconnection(err)
+			}
 			if retries != -1 && failedCalls >= retries {
-				m.pubnub.Config.Log.Printf(fmt.Sprintf("Network connection retry limit (%d) exceeded", retries))
-				m.Lock()
-				m.hbRunning = false
-				m.Unlock()
+				m.pubnub.Config.logger().Error("network reconnection retries exhausted", "max_retries", retries)
+				m.stopped(exit)
 				m.OnMaxReconnectionExhaustion()
 				return
 			}
+			timerInterval = m.pubnub.Config.ReconnectionBackoff.delay(m.pubnub.Config.PNReconnectionPolicy, failedCalls)
+			m.pubnub.Config.logger().Debug("next connectivity probe", "delay", timerInterval)
 		}
 
 		select {
-		case <-time.After(time.Duration(timerInterval) * time.Second):
+		case <-time.After(timerInterval):
+		case <-m.probeNow:
+			m.pubnub.Config.logger().Debug("connectivity probe requested")
 		case <-m.pubnub.ctx.Done():
-			m.pubnub.Config.Log.Printf(fmt.Sprintf("pubnub.ctx.Done\n"))
-			m.Lock()
-			m.hbRunning = false
-			m.Unlock()
+			m.pubnub.Config.logger().Debug("reconnection stopped by the PubNub context")
+			m.stopped(exit)
 			return
-		case <-m.exitReconnectionManager:
-			m.pubnub.Config.Log.Printf(fmt.Sprintf("exitReconnectionManager\n"))
+		case <-exit:
+			m.pubnub.Config.logger().Debug("reconnection stopped")
 			return
 		}
 	}
 }
 
-func (m *ReconnectionManager) getExponentialInterval() int {
-	timerInterval := int(math.Pow(2, float64(m.ExponentialMultiplier)) - 1)
-	if timerInterval > reconnectionMaxExponentialBackoff {
-		timerInterval = reconnectionMinExponentialBackoff
+// subscribeLost returns true if the subscribe loop stopped on a failure while the network stayed
+// connected, for the next successful probe to resume it.
+func (m *ReconnectionManager) subscribeLost() bool {
+	sm := m.pubnub.subscriptionManager
+	return sm != nil && sm.reconnectionManager == m && sm.connection.current() == PNReconnectingState
+}
 
-		m.Lock()
-		m.ExponentialMultiplier = 1
-		m.pubnub.Config.Log.Printf(fmt.Sprintf("timerInterval > MaxExponentialBackoff at: %d\n", m.ExponentialMultiplier))
-		m.Unlock()
+// stopped marks the run of startHeartbeatTimer with the exit channel as stopped, unless it was
+// stopped by stopHeartbeatTimer already.
+func (m *ReconnectionManager) stopped(exit chan bool) {
+	m.Lock()
+	if m.exitReconnectionManager == exit {
+		m.exitReconnectionManager = nil
+		m.hbRunning = false
+	}
+	m.Unlock()
+}
 
-	} else if timerInterval < 1 {
-		timerInterval = reconnectionMinExponentialBackoff
-		m.Lock()
-		m.ExponentialMultiplier = 1
-		m.pubnub.Config.Log.Printf(fmt.Sprintf("timerInterval < 1 at: %d\n", m.ExponentialMultiplier))
-		m.Unlock()
+// probeImmediately checks the connectivity right away instead of waiting for the end of the delay, the
+// request is dropped when one is pending already.
+func (m *ReconnectionManager) probeImmediately() {
+	select {
+	case m.probeNow <- true:
+	default:
 	}
-	return timerInterval
 }
 
 func (m *ReconnectionManager) stopHeartbeatTimer() {
-	m.pubnub.Config.Log.Printf("stopHeartbeatTimer")
+	m.pubnub.Config.logger().Debug("stopping reconnection timer")
 	m.Lock()
-	if m.hbRunning {
-		m.hbRunning = false
-		m.exitReconnectionManager <- true
+	if m.exitReconnectionManager != nil {
+		close(m.exitReconnectionManager)
+		m.exitReconnectionManager = nil
 	}
+	m.hbRunning = false
 	m.Unlock()
-	m.pubnub.Config.Log.Printf("stopHeartbeatTimer true")
+	m.pubnub.Config.logger().Debug("reconnection timer stopped")
 }
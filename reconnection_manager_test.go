@@ -1,6 +1,7 @@
 package pubnub
 
 import (
+	"errors"
 	//"fmt"
 	"github.com/sprucehealth/pubnub-go/tests/stubs"
 	"github.com/stretchr/testify/assert"
@@ -100,3 +101,69 @@ func TestReconnect(t *testing.T) {
 	assert.True(reconnected)
 	r.stopHeartbeatTimer()
 }
+
+func TestReconnectionBackoffDelay(t *testing.T) {
+	assert := assert.New(t)
+
+	var b ReconnectionBackoff
+	assert.Equal(10*time.Second, b.delay(PNLinearPolicy, 1))
+	assert.Equal(10*time.Second, b.delay(PNLinearPolicy, 7))
+	assert.Equal(time.Second, b.delay(PNExponentialPolicy, 1))
+	assert.Equal(2*time.Second, b.delay(PNExponentialPolicy, 2))
+	assert.Equal(16*time.Second, b.delay(PNExponentialPolicy, 5))
+	assert.Equal(32*time.Second, b.delay(PNExponentialPolicy, 6))
+	assert.Equal(32*time.Second, b.delay(PNExponentialPolicy, 7))
+	assert.Equal(32*time.Second, b.delay(PNExponentialPolicy, 1000))
+
+	b = ReconnectionBackoff{Delay: 500 * time.Millisecond, MaxDelay: 3 * time.Second}
+	assert.Equal(500*time.Millisecond, b.delay(PNLinearPolicy, 3))
+	assert.Equal(2*time.Second, b.delay(PNExponentialPolicy, 3))
+	assert.Equal(3*time.Second, b.delay(PNExponentialPolicy, 4))
+
+	b.FullJitter = true
+	for attempt := 1; attempt < 100; attempt++ {
+		d := b.delay(PNExponentialPolicy, attempt)
+		assert.True(d >= 0 && d <= 3*time.Second, "attempt %d: %s", attempt, d)
+	}
+
+	b.Func = func(attempt int) time.Duration {
+		return time.Duration(attempt) * time.Millisecond
+	}
+	assert.Equal(4*time.Millisecond, b.delay(PNExponentialPolicy, 4))
+}
+
+func TestReconnectionConnectivityProbe(t *testing.T) {
+	assert := assert.New(t)
+
+	config := NewConfig()
+	config.MaximumReconnectionRetries = 3
+	config.PNReconnectionPolicy = PNExponentialPolicy
+	config.ReconnectionBackoff = ReconnectionBackoff{
+		Func: func(attempt int) time.Duration {
+			return 10 * time.Millisecond
+		},
+	}
+	probes := 0
+	config.ConnectivityProbe = func(ctx Context) error {
+		probes++
+		return errors.New("offline")
+	}
+
+	pn := NewPubNub(config)
+	r := newReconnectionManager(pn)
+	var disconnectionErr error
+	r.HandleDisconnection(func(err error) {
+		disconnectionErr = err
+	})
+	reconnectionExhausted := false
+	r.HandleOnMaxReconnectionExhaustion(func() {
+		reconnectionExhausted = true
+	})
+
+	t1 := time.Now()
+	r.startHeartbeatTimer()
+	assert.True(time.Since(t1) < time.Second)
+	assert.Equal(3, probes)
+	assert.EqualError(disconnectionErr, "offline")
+	assert.True(reconnectionExhausted)
+}
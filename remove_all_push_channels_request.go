@@ -90,6 +90,10 @@ func (o *removeAllPushChannelsForDeviceOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *removeAllPushChannelsForDeviceOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *removeAllPushChannelsForDeviceOpts) context() Context {
 	return o.ctx
 }
@@ -126,8 +130,8 @@ func (o *removeAllPushChannelsForDeviceOpts) buildQuery() (*url.Values, error) {
 	return q, nil
 }
 
-func (o *removeAllPushChannelsForDeviceOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *removeAllPushChannelsForDeviceOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *removeAllPushChannelsForDeviceOpts) buildBody() ([]byte, error) {
@@ -92,6 +92,10 @@ func (o *removeChannelOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *removeChannelOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *removeChannelOpts) context() Context {
 	return o.ctx
 }
@@ -128,12 +132,13 @@ func (o *removeChannelOpts) buildQuery() (*url.Values, error) {
 	}
 
 	q.Set("remove", strings.Join(channels, ","))
+	o.pubnub.tokenManager.setAuthParam(q, PNGroups, o.ChannelGroup)
 	SetQueryParam(q, o.QueryParam)
 	return q, nil
 }
 
-func (o *removeChannelOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *removeChannelOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *removeChannelOpts) buildBody() ([]byte, error) {
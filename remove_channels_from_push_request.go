@@ -99,6 +99,10 @@ func (o *removeChannelsFromPushOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *removeChannelsFromPushOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *removeChannelsFromPushOpts) context() Context {
 	return o.ctx
 }
@@ -146,8 +150,8 @@ func (o *removeChannelsFromPushOpts) buildQuery() (*url.Values, error) {
 	return q, nil
 }
 
-func (o *removeChannelsFromPushOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *removeChannelsFromPushOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *removeChannelsFromPushOpts) buildBody() ([]byte, error) {
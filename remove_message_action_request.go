@@ -0,0 +1,208 @@
+package pubnub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/sprucehealth/pubnub-go/pnerr"
+	"github.com/sprucehealth/pubnub-go/utils"
+)
+
+var emptyPNRemoveMessageActionResponse *PNRemoveMessageActionResponse
+
+const removeMessageActionPath = "/v1/message-actions/%s/channel/%s/message/%s/action/%s"
+
+type removeMessageActionBuilder struct {
+	opts *removeMessageActionOpts
+}
+
+func newRemoveMessageActionBuilder(pubnub *PubNub) *removeMessageActionBuilder {
+	builder := removeMessageActionBuilder{
+		opts: &removeMessageActionOpts{
+			pubnub: pubnub,
+		},
+	}
+
+	return &builder
+}
+
+func newRemoveMessageActionBuilderWithContext(pubnub *PubNub,
+	context Context) *removeMessageActionBuilder {
+	builder := removeMessageActionBuilder{
+		opts: &removeMessageActionOpts{
+			pubnub: pubnub,
+			ctx:    context,
+		},
+	}
+
+	return &builder
+}
+
+// Channel sets the Channel of the message to remove the action from.
+func (b *removeMessageActionBuilder) Channel(channel string) *removeMessageActionBuilder {
+	b.opts.Channel = channel
+
+	return b
+}
+
+// MessageTimetoken sets the publish timetoken of the message to remove the action from.
+func (b *removeMessageActionBuilder) MessageTimetoken(timetoken string) *removeMessageActionBuilder {
+	b.opts.MessageTimetoken = timetoken
+
+	return b
+}
+
+// ActionTimetoken sets the timetoken of the action to remove.
+func (b *removeMessageActionBuilder) ActionTimetoken(timetoken string) *removeMessageActionBuilder {
+	b.opts.ActionTimetoken = timetoken
+
+	return b
+}
+
+// QueryParam accepts a map, the keys and values of the map are passed as the query string parameters of the URL called by the API.
+func (b *removeMessageActionBuilder) QueryParam(queryParam map[string]string) *removeMessageActionBuilder {
+	b.opts.QueryParam = queryParam
+
+	return b
+}
+
+// Transport sets the Transport for the removeMessageAction request.
+func (b *removeMessageActionBuilder) Transport(tr http.RoundTripper) *removeMessageActionBuilder {
+	b.opts.Transport = tr
+	return b
+}
+
+// Execute runs the removeMessageAction request.
+func (b *removeMessageActionBuilder) Execute() (*PNRemoveMessageActionResponse, StatusResponse, error) {
+	rawJSON, status, err := executeRequest(b.opts)
+	if err != nil {
+		return emptyPNRemoveMessageActionResponse, status, err
+	}
+
+	return newPNRemoveMessageActionResponse(rawJSON, b.opts, status)
+}
+
+type removeMessageActionOpts struct {
+	pubnub *PubNub
+
+	Channel          string
+	MessageTimetoken string
+	ActionTimetoken  string
+	QueryParam       map[string]string
+
+	Transport http.RoundTripper
+
+	ctx Context
+}
+
+func (o *removeMessageActionOpts) config() Config {
+	return *o.pubnub.Config
+}
+
+func (o *removeMessageActionOpts) client() *http.Client {
+	return o.pubnub.GetClient()
+}
+
+func (o *removeMessageActionOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
+func (o *removeMessageActionOpts) context() Context {
+	return o.ctx
+}
+
+func (o *removeMessageActionOpts) validate() error {
+	if o.config().SubscribeKey == "" {
+		return newValidationError(o, StrMissingSubKey)
+	}
+
+	if o.Channel == "" {
+		return newValidationError(o, StrMissingChannel)
+	}
+
+	if o.MessageTimetoken == "" {
+		return newValidationError(o, StrMissingMessageTimetoken)
+	}
+
+	if o.ActionTimetoken == "" {
+		return newValidationError(o, StrMissingActionTimetoken)
+	}
+
+	return nil
+}
+
+func (o *removeMessageActionOpts) buildPath() (string, error) {
+	return fmt.Sprintf(removeMessageActionPath,
+		o.pubnub.Config.SubscribeKey,
+		utils.URLEncode(o.Channel),
+		o.MessageTimetoken,
+		o.ActionTimetoken), nil
+}
+
+func (o *removeMessageActionOpts) buildQuery() (*url.Values, error) {
+
+	q := defaultQuery(o.pubnub.Config.UUID, o.pubnub.telemetryManager)
+
+	o.pubnub.tokenManager.setAuthParam(q, PNChannels, o.Channel)
+	SetQueryParam(q, o.QueryParam)
+
+	return q, nil
+}
+
+func (o *removeMessageActionOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
+}
+
+func (o *removeMessageActionOpts) buildBody() ([]byte, error) {
+	return []byte{}, nil
+}
+
+func (o *removeMessageActionOpts) httpMethod() string {
+	return "DELETE"
+}
+
+func (o *removeMessageActionOpts) isAuthRequired() bool {
+	return true
+}
+
+func (o *removeMessageActionOpts) requestTimeout() int {
+	return o.pubnub.Config.NonSubscribeRequestTimeout
+}
+
+func (o *removeMessageActionOpts) connectTimeout() int {
+	return o.pubnub.Config.ConnectTimeout
+}
+
+func (o *removeMessageActionOpts) operationType() OperationType {
+	return PNRemoveMessageActionsOperation
+}
+
+func (o *removeMessageActionOpts) telemetryManager() *TelemetryManager {
+	return o.pubnub.telemetryManager
+}
+
+// PNRemoveMessageActionResponse is the Message Actions API Response for Remove Message Action
+type PNRemoveMessageActionResponse struct {
+	Status int         `json:"status"`
+	Data   interface{} `json:"data"`
+}
+
+func newPNRemoveMessageActionResponse(jsonBytes []byte, o *removeMessageActionOpts,
+	status StatusResponse) (*PNRemoveMessageActionResponse, StatusResponse, error) {
+
+	resp := &PNRemoveMessageActionResponse{}
+
+	err := json.Unmarshal(jsonBytes, &resp)
+	if err != nil {
+		e := pnerr.NewResponseParsingError("Error unmarshalling response",
+			ioutil.NopCloser(bytes.NewBufferString(string(jsonBytes))), err)
+
+		return emptyPNRemoveMessageActionResponse, status, e
+	}
+
+	return resp, status, nil
+}
@@ -0,0 +1,102 @@
+package pubnub
+
+import (
+	"fmt"
+	"testing"
+
+	h "github.com/sprucehealth/pubnub-go/tests/helpers"
+	"github.com/stretchr/testify/assert"
+)
+
+func AssertRemoveMessageAction(t *testing.T, checkQueryParam, testContext bool) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+
+	queryParam := map[string]string{
+		"q1": "v1",
+		"q2": "v2",
+	}
+
+	if !checkQueryParam {
+		queryParam = nil
+	}
+
+	o := newRemoveMessageActionBuilder(pn)
+	if testContext {
+		o = newRemoveMessageActionBuilderWithContext(pn, backgroundContext)
+	}
+
+	o.Channel("ch")
+	o.MessageTimetoken("15698453474046033")
+	o.ActionTimetoken("15698453605345560")
+	o.QueryParam(queryParam)
+
+	path, err := o.opts.buildPath()
+	assert.Nil(err)
+
+	h.AssertPathsEqual(t,
+		fmt.Sprintf("/v1/message-actions/%s/channel/%s/message/%s/action/%s", pn.Config.SubscribeKey, "ch", "15698453474046033", "15698453605345560"),
+		path, []int{})
+
+	body, err := o.opts.buildBody()
+	assert.Nil(err)
+	assert.Empty(body)
+	assert.Equal("DELETE", o.opts.httpMethod())
+
+	if checkQueryParam {
+		u, _ := o.opts.buildQuery()
+		assert.Equal("v1", u.Get("q1"))
+		assert.Equal("v2", u.Get("q2"))
+	}
+
+}
+
+func TestRemoveMessageAction(t *testing.T) {
+	AssertRemoveMessageAction(t, true, false)
+}
+
+func TestRemoveMessageActionContext(t *testing.T) {
+	AssertRemoveMessageAction(t, true, true)
+}
+
+func TestRemoveMessageActionValidate(t *testing.T) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+
+	o := newRemoveMessageActionBuilder(pn)
+	assert.Contains(o.opts.validate().Error(), StrMissingChannel)
+
+	o.Channel("ch")
+	o.MessageTimetoken("15698453474046033")
+	assert.Contains(o.opts.validate().Error(), StrMissingActionTimetoken)
+
+	o.ActionTimetoken("15698453605345560")
+	assert.Nil(o.opts.validate())
+}
+
+func TestRemoveMessageActionResponseValueError(t *testing.T) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+	opts := &removeMessageActionOpts{
+		pubnub: pn,
+	}
+	jsonBytes := []byte(`s`)
+
+	_, _, err := newPNRemoveMessageActionResponse(jsonBytes, opts, StatusResponse{})
+	assert.Equal("pubnub/parsing: Error unmarshalling response: {s}", err.Error())
+}
+
+// {"status": 200, "data": {}}
+func TestRemoveMessageActionResponseValuePass(t *testing.T) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+	opts := &removeMessageActionOpts{
+		pubnub: pn,
+	}
+	jsonBytes := []byte(`{"status": 200, "data": {}}`)
+
+	r, _, err := newPNRemoveMessageActionResponse(jsonBytes, opts, StatusResponse{})
+	assert.Equal(200, r.Status)
+
+	assert.Nil(err)
+}
@@ -2,7 +2,6 @@ package pubnub
 
 import (
 	"bytes"
-	"fmt"
 	"github.com/sprucehealth/pubnub-go/pnerr"
 	"io"
 	"io/ioutil"
@@ -43,17 +42,19 @@ func addToJobQ(req *http.Request, client *http.Client, opts endpointOpts, j chan
 		Req:         req,
 		Client:      client,
 		JobResponse: j,
+		operation:   opts.operationType(),
+		priority:    opts.config().RequestPriorities.priority(opts.operationType()),
 	}
-	opts.jobQueue() <- jqi
+	opts.requestWorkers().enqueue(jqi, opts.context())
 }
 
 func buildBody(opts endpointOpts, url *url.URL) (io.Reader, error) {
 	b, err := opts.buildBody()
 	if err != nil {
-		opts.config().Log.Println("PNUnknownCategory", err, url)
+		opts.config().logger().Error("request body serialization failed", "operation", opts.operationType(), "error", err)
 		return nil, err
 	}
-	opts.config().Log.Println("BODY", string(b))
+	opts.config().logger().Debug("request body", "operation", opts.operationType(), "body", string(b))
 
 	return bytes.NewReader(b), nil
 }
@@ -62,7 +63,7 @@ func executeRequest(opts endpointOpts) ([]byte, StatusResponse, error) {
 	err := opts.validate()
 
 	if err != nil {
-		opts.config().Log.Println("PNUnknownCategory", err)
+		opts.config().logger().Error("request validation failed", "operation", opts.operationType(), "error", err)
 		return nil,
 			createStatus(PNUnknownCategory, "", ResponseInfo{}, err),
 			err
@@ -71,88 +72,82 @@ func executeRequest(opts endpointOpts) ([]byte, StatusResponse, error) {
 	url, err := buildURL(opts)
 
 	if err != nil {
-		opts.config().Log.Println("PNUnknownCategory", err)
+		opts.config().logger().Error("request URL build failed", "operation", opts.operationType(), "error", err)
 		return nil,
 			createStatus(PNUnknownCategory, "", ResponseInfo{}, err),
 			err
 	}
 
-	opts.config().Log.Println(fmt.Sprintf("url:%s\nmethod:%s", url, opts.httpMethod()))
+	opts.config().logger().Debug("sending request", "operation", opts.operationType(), "method", opts.httpMethod(), "url", url)
 
-	var req *http.Request
+	client := opts.client()
+	if tr := opts.transport(); tr != nil {
+		client = newClientWithTransport(client, tr, opts.requestTimeout())
+	}
+	startTimestamp := time.Now()
 
-	if opts.httpMethod() == "POST" {
-		body, err := buildBody(opts, url)
-		if err != nil {
-			return nil, createStatus(PNUnknownCategory, "", ResponseInfo{}, err), err
-		}
+	retryPolicy := opts.config().RequestRetryPolicy
+	retry := retryPolicy.appliesTo(opts)
 
-		req, err = newRequest("POST", url, body, opts.config().UseHTTP2)
-	} else if opts.httpMethod() == "DELETE" {
-		req, err = newRequest("DELETE", url, nil, opts.config().UseHTTP2)
-	} else if opts.httpMethod() == "PATCH" {
-		body, err := buildBody(opts, url)
+	var res *http.Response
+
+	for attempt := 1; ; attempt++ {
+		var req *http.Request
+		req, err = newEndpointRequest(opts, url)
 		if err != nil {
-			return nil, createStatus(PNUnknownCategory, "", ResponseInfo{}, err), err
+			opts.config().logger().Error("request creation failed", "operation", opts.operationType(), "url", url, "error", err)
+			return nil,
+				createStatus(PNUnknownCategory, "", ResponseInfo{}, err),
+				err
 		}
 
-		req, err = newRequest("PATCH", url, body, opts.config().UseHTTP2)
-	} else {
-		req, err = newRequest("GET", url, nil, opts.config().UseHTTP2)
-	}
-
-	if err != nil {
-		opts.config().Log.Println("PNUnknownCategory", err, url)
-		return nil,
-			createStatus(PNUnknownCategory, "", ResponseInfo{}, err),
-			err
-	}
+		res, err = doRequest(req, client, opts)
 
-	ctx := opts.context()
-	if ctx != nil {
-		// with !go1.7 you can't assign context directly to a request,
-		// the request.cancel is mapped to the ctx.Done() channel instead
-		// go1.7 can assign context to an executed request
-		req = setRequestContext(req, ctx)
-	}
-
-	client := opts.client()
-	startTimestamp := time.Now()
-
-	var res *http.Response
-	runRequestWorker := false
+		if !retry || attempt >= retryPolicy.maxAttempts() || !shouldRetry(res, err) {
+			break
+		}
 
-	switch opts.operationType() {
-	case PNPublishOperation, PNAccessManagerGrant:
-		runRequestWorker = true
-	}
+		delay, ok := retryPolicy.delay(attempt, res)
+		if !ok {
+			opts.config().logger().Warn("not retrying request, Retry-After above the max delay", "operation", opts.operationType(), "attempt", attempt, "retry_after", delay)
+			break
+		}
+		if res != nil {
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+			opts.config().logger().Warn("retrying request", "operation", opts.operationType(), "attempt", attempt, "status_code", res.StatusCode, "delay", delay)
+		} else {
+			opts.config().logger().Warn("retrying request", "operation", opts.operationType(), "attempt", attempt, "error", err, "delay", delay)
+		}
 
-	if runRequestWorker && opts.config().MaxWorkers > 0 {
-		j := make(chan *JobQResponse)
-		go addToJobQ(req, client, opts, j)
-		jr := <-j
-		close(j)
-		res = jr.Resp
-		err = jr.Error
-	} else {
-		res, err = client.Do(req)
+		if !waitForRetry(opts.context(), delay) {
+			res, err = nil, opts.context().Err()
+			break
+		}
 	}
 
 	// Host lookup failed
 	if err != nil {
-		opts.config().Log.Println("err.Error()", err.Error())
 		e := pnerr.NewConnectionError("Failed to execute request", err)
 
-		opts.config().Log.Println("PNUnknownCategory", e.Error(), url)
+		category := PNUnknownCategory
+		switch {
+		case pnerr.IsTimeout(e):
+			category = PNTimeoutCategory
+		case pnerr.IsCancelled(e):
+			category = PNCancelledCategory
+		}
+
+		opts.config().logger().Error("request failed", "operation", opts.operationType(), "category", category, "url", url, "error", e)
 		return nil,
-			createStatus(PNUnknownCategory, "", ResponseInfo{}, e),
+			createStatus(category, "", ResponseInfo{}, e),
 			e
 	}
 
 	val, status, err := parseResponse(res, opts)
 	// Already wrapped error
 	if err != nil {
-		opts.config().Log.Println("res.StatusCode, status, err.Error()", res.StatusCode, status, err.Error())
+		opts.config().logger().Debug("request returned an error", "operation", opts.operationType(), "status_code", res.StatusCode, "category", status.Category)
 		return nil, status, err
 	}
 
@@ -185,6 +180,78 @@ func executeRequest(opts endpointOpts) ([]byte, StatusResponse, error) {
 	return val, status, nil
 }
 
+// newEndpointRequest creates the request of the opts, a new request is created for each attempt
+// as the body is consumed by the previous one.
+func newEndpointRequest(opts endpointOpts, url *url.URL) (*http.Request, error) {
+	var req *http.Request
+	var err error
+
+	if opts.httpMethod() == "POST" {
+		body, bodyErr := buildBody(opts, url)
+		if bodyErr != nil {
+			return nil, bodyErr
+		}
+
+		req, err = newRequest("POST", url, body, opts.config().UseHTTP2)
+	} else if opts.httpMethod() == "DELETE" {
+		req, err = newRequest("DELETE", url, nil, opts.config().UseHTTP2)
+	} else if opts.httpMethod() == "PATCH" {
+		body, bodyErr := buildBody(opts, url)
+		if bodyErr != nil {
+			return nil, bodyErr
+		}
+
+		req, err = newRequest("PATCH", url, body, opts.config().UseHTTP2)
+	} else {
+		req, err = newRequest("GET", url, nil, opts.config().UseHTTP2)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := opts.context()
+	if ctx != nil {
+		// with !go1.7 you can't assign context directly to a request,
+		// the request.cancel is mapped to the ctx.Done() channel instead
+		// go1.7 can assign context to an executed request
+		req = setRequestContext(req, ctx)
+	}
+
+	return req, nil
+}
+
+// doRequest runs the request on the request workers. The subscribe requests are sent directly,
+// as these long-polls would hold a worker.
+func doRequest(req *http.Request, client *http.Client, opts endpointOpts) (*http.Response, error) {
+	if opts.operationType() == PNSubscribeOperation || opts.requestWorkers().MaxWorkers <= 0 {
+		return client.Do(req)
+	}
+
+	j := make(chan *JobQResponse, 1)
+	addToJobQ(req, client, opts, j)
+	jr := <-j
+	return jr.Resp, jr.Error
+}
+
+// waitForRetry waits for the delay, it returns false if the context is done before.
+func waitForRetry(ctx Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	if ctx == nil {
+		<-timer.C
+		return true
+	}
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 func newRequest(method string, u *url.URL, body io.Reader, useHTTP2 bool) (*http.Request,
 	error) {
 
@@ -226,24 +293,20 @@ func parseResponse(resp *http.Response, opts endpointOpts) ([]byte, StatusRespon
 	if resp.StatusCode != 200 {
 		// Errors like 400, 403, 500
 		e := pnerr.NewServerError(resp.StatusCode, resp.Body)
+		e.Header = resp.Header
 
-		opts.config().Log.Println(e.Error())
-
-		if resp.StatusCode == 408 {
-			opts.config().Log.Println("PNTimeoutCategory: resp.StatusCode, resp.Body, resp.Request.URL", resp.StatusCode, resp.Body, resp.Request.URL)
+		switch {
+		case pnerr.IsTimeout(e):
 			status = createStatus(PNTimeoutCategory, "", ResponseInfo{StatusCode: resp.StatusCode}, e)
-
-			return nil, status, e
-		}
-
-		if resp.StatusCode == 400 {
-			opts.config().Log.Println("PNBadRequestCategory: resp.StatusCode, resp.Body, resp.Request.URL", resp.StatusCode, resp.Body, resp.Request.URL)
+		case pnerr.IsBadRequest(e):
 			status = createStatus(PNBadRequestCategory, "", ResponseInfo{StatusCode: resp.StatusCode}, e)
-
-			return nil, status, e
+		case pnerr.IsAccessDenied(e):
+			status = createStatus(PNAccessDeniedCategory, "", ResponseInfo{StatusCode: resp.StatusCode, Operation: opts.operationType()}, e)
+		case pnerr.IsRateLimited(e):
+			status = createStatus(PNRateLimitedCategory, "", ResponseInfo{StatusCode: resp.StatusCode, Operation: opts.operationType()}, e)
+		default:
+			status = createStatus(PNUnknownCategory, "", ResponseInfo{StatusCode: resp.StatusCode, Operation: opts.operationType()}, e)
 		}
-		opts.config().Log.Println("PNUnknownCategory: resp.StatusCode, resp.Body, resp.Request.URL", resp.StatusCode, resp.Body, resp.Request.URL)
-		status = createStatus(PNUnknownCategory, "", ResponseInfo{StatusCode: resp.StatusCode, Operation: opts.operationType()}, e)
 
 		return nil, status, e
 	}
@@ -251,12 +314,12 @@ func parseResponse(resp *http.Response, opts endpointOpts) ([]byte, StatusRespon
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		e := pnerr.NewResponseParsingError("Error reading response body", resp.Body, err)
-		opts.config().Log.Println("Read All error: resp.Body, resp.Request.URL, e", resp.StatusCode, resp.Body, resp.Request.URL, e)
+		opts.config().logger().Error("response body read failed", "operation", opts.operationType(), "status_code", resp.StatusCode, "url", resp.Request.URL, "error", err)
 
 		return nil, status, e
 	}
 
-	opts.config().Log.Println("200 OK: resp.StatusCode, resp.Status, resp.Body, resp.Request.URL, string(body)", resp.StatusCode, resp.Status, resp.Body, resp.Request.URL, string(body))
+	opts.config().logger().Debug("response received", "operation", opts.operationType(), "status_code", resp.StatusCode, "url", resp.Request.URL, "body", string(body))
 	return body, status, nil
 }
 
@@ -282,5 +345,15 @@ func createStatus(category StatusCategory, response string,
 	resp.AffectedChannels = []string{}
 	resp.AffectedChannelGroups = []string{}
 
+	// the resources the server error applies to, for ex. the channels which are not granted
+	if se := pnerr.AsServerError(err); se != nil {
+		if len(se.Channels) > 0 {
+			resp.AffectedChannels = se.Channels
+		}
+		if len(se.ChannelGroups) > 0 {
+			resp.AffectedChannelGroups = se.ChannelGroups
+		}
+	}
+
 	return resp
 }
@@ -3,9 +3,25 @@
 package pubnub
 
 import (
+	"context"
 	"net/http"
 )
 
 func setRequestContext(r *http.Request, ctx Context) *http.Request {
 	return r.WithContext(ctx)
 }
+
+// cancelRequestOn returns the request cancelled when done is closed, in addition to its own
+// context. The returned func releases it once the response is read.
+func cancelRequestOn(r *http.Request, done <-chan struct{}) (*http.Request, func()) {
+	ctx, cancel := context.WithCancel(r.Context())
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return r.WithContext(ctx), cancel
+}
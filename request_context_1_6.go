@@ -4,6 +4,7 @@ package pubnub
 
 import (
 	"net/http"
+	"sync"
 )
 
 func setRequestContext(r *http.Request, ctx Context) *http.Request {
@@ -11,3 +12,28 @@ func setRequestContext(r *http.Request, ctx Context) *http.Request {
 
 	return r
 }
+
+// cancelRequestOn returns the request cancelled when done is closed, in addition to its own
+// Cancel channel. The returned func releases it once the response is read.
+func cancelRequestOn(r *http.Request, done <-chan struct{}) (*http.Request, func()) {
+	cancelled := make(chan struct{})
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() { close(cancelled) })
+	}
+
+	go func(requestCancel <-chan struct{}) {
+		select {
+		case <-done:
+			cancel()
+		case <-requestCancel:
+			cancel()
+		case <-cancelled:
+		}
+	}(r.Cancel)
+
+	req := *r
+	req.Cancel = cancelled
+
+	return &req, cancel
+}
@@ -0,0 +1,184 @@
+package pubnub
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	requestRetryDefaultMaxAttempts = 3
+	requestRetryDefaultDelay       = time.Second
+	requestRetryDefaultMaxDelay    = 30 * time.Second
+)
+
+// nonIdempotentOperations are not retried by the RequestRetryPolicy, unless they are listed
+// in its Operations or the request can be deduplicated (for ex. a Publish with a DedupeKey).
+var nonIdempotentOperations = map[OperationType]bool{
+	PNPublishOperation:           true,
+	PNFireOperation:              true,
+	PNSignalOperation:            true,
+	PNAddMessageActionsOperation: true,
+}
+
+// RequestRetryPolicy sets how the non-subscribe requests which fail with a connection error,
+// a 429 or a 5xx response are retried. The subscribe requests follow the PNReconnectionPolicy.
+type RequestRetryPolicy struct {
+	// Policy is PNLinearPolicy or PNExponentialPolicy, any other value disables the retries.
+	Policy ReconnectionPolicy
+	// MaxAttempts is the max number of attempts, including the first one. Defaults to 3.
+	MaxAttempts int
+	// Delay is the delay between the attempts of PNLinearPolicy and the initial delay of PNExponentialPolicy.
+	// Defaults to 1 second.
+	Delay time.Duration
+	// MaxDelay caps the delay of PNExponentialPolicy, the requests whose Retry-After is longer
+	// aren't retried. Defaults to 30 seconds.
+	MaxDelay time.Duration
+	// Jitter is the max random duration added to each delay.
+	Jitter time.Duration
+	// Operations limits the retries to these operations. When empty all the idempotent operations are retried.
+	Operations []OperationType
+	// ExcludedOperations are never retried.
+	ExcludedOperations []OperationType
+}
+
+// retryableOpts is implemented by the endpointOpts of the non-idempotent operations
+// which can be retried safely, for ex. when the request can be deduplicated.
+type retryableOpts interface {
+	isRetryable() bool
+}
+
+func (p RequestRetryPolicy) enabled() bool {
+	return p.Policy == PNLinearPolicy || p.Policy == PNExponentialPolicy
+}
+
+func (p RequestRetryPolicy) maxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return requestRetryDefaultMaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+// appliesTo returns true if the requests of the opts can be retried.
+func (p RequestRetryPolicy) appliesTo(opts endpointOpts) bool {
+	if !p.enabled() {
+		return false
+	}
+
+	operation := opts.operationType()
+	if operation == PNSubscribeOperation {
+		return false
+	}
+
+	for _, o := range p.ExcludedOperations {
+		if o == operation {
+			return false
+		}
+	}
+
+	if len(p.Operations) > 0 {
+		for _, o := range p.Operations {
+			if o == operation {
+				return true
+			}
+		}
+		return false
+	}
+
+	if nonIdempotentOperations[operation] {
+		r, ok := opts.(retryableOpts)
+		return ok && r.isRetryable()
+	}
+
+	return true
+}
+
+func (p RequestRetryPolicy) maxDelay() time.Duration {
+	if p.MaxDelay <= 0 {
+		return requestRetryDefaultMaxDelay
+	}
+	return p.MaxDelay
+}
+
+// delay returns the delay before the attempt following the failed attempt (starting at 1).
+// The Retry-After header of the response, if any, takes precedence. It returns false when the
+// Retry-After is longer than the MaxDelay, the request isn't retried then.
+func (p RequestRetryPolicy) delay(attempt int, res *http.Response) (time.Duration, bool) {
+	if d, ok := retryAfter(res); ok {
+		return d, d <= p.maxDelay()
+	}
+
+	base := p.Delay
+	if base <= 0 {
+		base = requestRetryDefaultDelay
+	}
+
+	d := base
+	if p.Policy == PNExponentialPolicy {
+		maxDelay := p.maxDelay()
+		exp := math.Pow(2, float64(attempt-1))
+		if float64(base)*exp >= float64(maxDelay) {
+			d = maxDelay
+		} else {
+			d = time.Duration(float64(base) * exp)
+		}
+	}
+
+	if p.Jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(p.Jitter)))
+	}
+
+	return d, true
+}
+
+// shouldRetry returns true if the attempt failed with a connection error or a response
+// status which may not happen again.
+func shouldRetry(res *http.Response, err error) bool {
+	if err == ErrClosed || err == ErrRequestQueueFull {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// retryAfter parses the Retry-After header of the response, if any.
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+
+	return retryAfterHeader(res.Header)
+}
+
+// retryAfterHeader parses the Retry-After header, which is either a number of seconds or a date.
+func retryAfterHeader(header http.Header) (time.Duration, bool) {
+	v := header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		d := t.Sub(time.Now())
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
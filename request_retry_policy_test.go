@@ -0,0 +1,289 @@
+package pubnub
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sequenceTransport responds with the responses in order, the last one is repeated.
+type sequenceTransport struct {
+	sync.Mutex
+
+	responses []func(req *http.Request) (*http.Response, error)
+	calls     int
+}
+
+func (t *sequenceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.Lock()
+	i := t.calls
+	if i >= len(t.responses) {
+		i = len(t.responses) - 1
+	}
+	t.calls++
+	t.Unlock()
+
+	return t.responses[i](req)
+}
+
+func (t *sequenceTransport) callCount() int {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.calls
+}
+
+func respondWith(statusCode int, body string, header http.Header) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			StatusCode: statusCode,
+			Header:     header,
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	}
+}
+
+func failWith(err error) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		return nil, err
+	}
+}
+
+func newRetryTestPubNub(policy RequestRetryPolicy) *PubNub {
+	config := NewDemoConfig()
+	config.RequestRetryPolicy = policy
+	config.MaxWorkers = 0
+
+	return NewPubNub(config)
+}
+
+func TestRequestRetryPolicyAppliesTo(t *testing.T) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+
+	timeOpts := &timeOpts{pubnub: pn}
+	publishOpts := &publishOpts{pubnub: pn}
+	subscribeOpts := &subscribeOpts{pubnub: pn}
+
+	assert.False(RequestRetryPolicy{}.appliesTo(timeOpts))
+	assert.False(RequestRetryPolicy{Policy: PNNonePolicy}.appliesTo(timeOpts))
+
+	policy := RequestRetryPolicy{Policy: PNLinearPolicy}
+	assert.True(policy.appliesTo(timeOpts))
+	assert.False(policy.appliesTo(subscribeOpts))
+	assert.False(policy.appliesTo(publishOpts))
+
+	publishOpts.DedupeKey = "key"
+	assert.True(policy.appliesTo(publishOpts))
+
+	policy.ExcludedOperations = []OperationType{PNTimeOperation}
+	assert.False(policy.appliesTo(timeOpts))
+
+	policy = RequestRetryPolicy{Policy: PNExponentialPolicy, Operations: []OperationType{PNPublishOperation}}
+	publishOpts.DedupeKey = ""
+	assert.True(policy.appliesTo(publishOpts))
+	assert.False(policy.appliesTo(timeOpts))
+}
+
+func TestRequestRetryPolicyDelay(t *testing.T) {
+	assert := assert.New(t)
+
+	delay := func(p RequestRetryPolicy, attempt int, res *http.Response) time.Duration {
+		d, ok := p.delay(attempt, res)
+		assert.True(ok)
+		return d
+	}
+
+	linear := RequestRetryPolicy{Policy: PNLinearPolicy, Delay: 2 * time.Second}
+	assert.Equal(2*time.Second, delay(linear, 1, nil))
+	assert.Equal(2*time.Second, delay(linear, 5, nil))
+
+	exponential := RequestRetryPolicy{Policy: PNExponentialPolicy, Delay: time.Second, MaxDelay: 5 * time.Second}
+	assert.Equal(time.Second, delay(exponential, 1, nil))
+	assert.Equal(2*time.Second, delay(exponential, 2, nil))
+	assert.Equal(4*time.Second, delay(exponential, 3, nil))
+	assert.Equal(5*time.Second, delay(exponential, 4, nil))
+	assert.Equal(5*time.Second, delay(exponential, 100, nil))
+
+	jitter := RequestRetryPolicy{Policy: PNLinearPolicy, Delay: time.Second, Jitter: 100 * time.Millisecond}
+	for i := 0; i < 10; i++ {
+		d := delay(jitter, 1, nil)
+		assert.True(d >= time.Second && d < 1100*time.Millisecond)
+	}
+
+	res := &http.Response{Header: http.Header{"Retry-After": []string{"4"}}}
+	assert.Equal(4*time.Second, delay(exponential, 1, res))
+
+	res.Header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	assert.Equal(time.Duration(0), delay(exponential, 1, res))
+
+	// above the MaxDelay, or the default one, the request isn't retried
+	res.Header.Set("Retry-After", "7")
+	_, ok := exponential.delay(1, res)
+	assert.False(ok)
+	res.Header.Set("Retry-After", "3600")
+	_, ok = linear.delay(1, res)
+	assert.False(ok)
+}
+
+func TestShouldRetry(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(shouldRetry(nil, errors.New("connection reset by peer")))
+	assert.True(shouldRetry(&http.Response{StatusCode: 429}, nil))
+	assert.True(shouldRetry(&http.Response{StatusCode: 503}, nil))
+	assert.False(shouldRetry(&http.Response{StatusCode: 400}, nil))
+	assert.False(shouldRetry(&http.Response{StatusCode: 403}, nil))
+}
+
+func TestExecuteRequestRetries(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := newRetryTestPubNub(RequestRetryPolicy{
+		Policy:      PNLinearPolicy,
+		MaxAttempts: 4,
+		Delay:       time.Millisecond,
+	})
+
+	tr := &sequenceTransport{
+		responses: []func(req *http.Request) (*http.Response, error){
+			failWith(errors.New("connection reset by peer")),
+			respondWith(503, `{"error":true}`, nil),
+			respondWith(429, `{"error":true}`, http.Header{"Retry-After": []string{"0"}}),
+			respondWith(200, `[15078947309567840]`, nil),
+		},
+	}
+
+	res, status, err := pn.Time().Transport(tr).Execute()
+	assert.Nil(err)
+	assert.Equal(200, status.StatusCode)
+	assert.Equal(int64(15078947309567840), res.Timetoken)
+	assert.Equal(4, tr.callCount())
+}
+
+func TestExecuteRequestRetriesExhausted(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := newRetryTestPubNub(RequestRetryPolicy{
+		Policy:      PNExponentialPolicy,
+		MaxAttempts: 2,
+		Delay:       time.Millisecond,
+	})
+
+	tr := &sequenceTransport{
+		responses: []func(req *http.Request) (*http.Response, error){
+			respondWith(500, `{"error":true}`, nil),
+		},
+	}
+
+	_, status, err := pn.Time().Transport(tr).Execute()
+	assert.NotNil(err)
+	assert.Equal(500, status.StatusCode)
+	assert.Equal(2, tr.callCount())
+}
+
+func TestExecuteRequestNoRetryAfterMaxDelay(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := newRetryTestPubNub(RequestRetryPolicy{
+		Policy:      PNLinearPolicy,
+		MaxAttempts: 3,
+		Delay:       time.Millisecond,
+	})
+
+	tr := &sequenceTransport{
+		responses: []func(req *http.Request) (*http.Response, error){
+			respondWith(429, `{"error":true}`, http.Header{"Retry-After": []string{"3600"}}),
+		},
+	}
+
+	_, status, err := pn.Time().Transport(tr).Execute()
+	assert.NotNil(err)
+	assert.Equal(429, status.StatusCode)
+	assert.Equal(1, tr.callCount())
+}
+
+func TestExecuteRequestNoRetryOnClientError(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := newRetryTestPubNub(RequestRetryPolicy{
+		Policy: PNLinearPolicy,
+		Delay:  time.Millisecond,
+	})
+
+	tr := &sequenceTransport{
+		responses: []func(req *http.Request) (*http.Response, error){
+			respondWith(400, `{"error":true}`, nil),
+		},
+	}
+
+	_, _, err := pn.Time().Transport(tr).Execute()
+	assert.NotNil(err)
+	assert.Equal(1, tr.callCount())
+}
+
+func TestExecuteRequestPublishRetries(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := newRetryTestPubNub(RequestRetryPolicy{
+		Policy: PNLinearPolicy,
+		Delay:  time.Millisecond,
+	})
+
+	newTransport := func() *sequenceTransport {
+		return &sequenceTransport{
+			responses: []func(req *http.Request) (*http.Response, error){
+				respondWith(503, `{"error":true}`, nil),
+				respondWith(200, `[1,"Sent","15078947309567840"]`, nil),
+			},
+		}
+	}
+
+	tr := newTransport()
+	_, _, err := pn.Publish().Channel("ch").Message("hey").Transport(tr).Execute()
+	assert.NotNil(err)
+	assert.Equal(1, tr.callCount())
+
+	var meta []string
+	tr = newTransport()
+	tr.responses[0] = func(req *http.Request) (*http.Response, error) {
+		meta = append(meta, req.URL.Query().Get("meta"))
+		return respondWith(503, `{"error":true}`, nil)(req)
+	}
+	_, _, err = pn.Publish().Channel("ch").Message("hey").DedupeKey("msg-1").Transport(tr).Execute()
+	assert.Nil(err)
+	assert.Equal(2, tr.callCount())
+	assert.Equal([]string{`{"pn_dedupe_key":"msg-1"}`}, meta)
+}
+
+func TestExecuteRequestRetryContextCancelled(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := newRetryTestPubNub(RequestRetryPolicy{
+		Policy: PNLinearPolicy,
+		Delay:  time.Hour,
+	})
+
+	tr := &sequenceTransport{
+		responses: []func(req *http.Request) (*http.Response, error){
+			respondWith(503, `{"error":true}`, nil),
+		},
+	}
+
+	ctx, cancel := contextWithCancel(backgroundContext)
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, _, err := pn.TimeWithContext(ctx).Transport(tr).Execute()
+	assert.NotNil(err)
+	assert.Equal(1, tr.callCount())
+}
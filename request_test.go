@@ -0,0 +1,145 @@
+package pubnub
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sprucehealth/pubnub-go/pnerr"
+	"github.com/stretchr/testify/assert"
+)
+
+type timeoutTestError struct{}
+
+func (e timeoutTestError) Error() string   { return "i/o timeout" }
+func (e timeoutTestError) Timeout() bool   { return true }
+func (e timeoutTestError) Temporary() bool { return true }
+
+func assertExecuteRequestCategory(t *testing.T, category StatusCategory,
+	response func(req *http.Request) (*http.Response, error)) error {
+	assert := assert.New(t)
+
+	tr := &sequenceTransport{
+		responses: []func(req *http.Request) (*http.Response, error){response},
+	}
+
+	_, status, err := NewPubNub(NewDemoConfig()).Time().Transport(tr).Execute()
+	assert.NotNil(err)
+	assert.Equal(category, status.Category)
+
+	return err
+}
+
+func TestExecuteRequestAccessDenied(t *testing.T) {
+	err := assertExecuteRequestCategory(t, PNAccessDeniedCategory,
+		respondWith(403, `{"message":"Forbidden","payload":{"channels":["ch1"]},"error":true,"service":"Access Manager","status":403}`, nil))
+
+	assert.True(t, pnerr.IsAccessDenied(err))
+	assert.Equal(t, "Access Manager", pnerr.AsServerError(err).Service)
+}
+
+func TestExecuteRequestAccessDeniedAffectedChannels(t *testing.T) {
+	assert := assert.New(t)
+
+	tr := &sequenceTransport{
+		responses: []func(req *http.Request) (*http.Response, error){
+			respondWith(403, `{"message":"Forbidden","payload":{"channels":["ch1","ch2"],"channel-groups":["cg1"]},"error":true,"service":"Access Manager","status":403}`, nil),
+		},
+	}
+
+	_, status, err := NewPubNub(NewDemoConfig()).Time().Transport(tr).Execute()
+	assert.NotNil(err)
+	assert.Equal(403, status.StatusCode)
+	assert.Equal([]string{"ch1", "ch2"}, status.AffectedChannels)
+	assert.Equal([]string{"cg1"}, status.AffectedChannelGroups)
+}
+
+func TestExecuteRequestRateLimited(t *testing.T) {
+	err := assertExecuteRequestCategory(t, PNRateLimitedCategory, respondWith(429, `{"message":"Too Many Requests","error":true,"status":429}`, nil))
+
+	assert.True(t, pnerr.IsRateLimited(err))
+}
+
+func TestExecuteRequestBadRequest(t *testing.T) {
+	assertExecuteRequestCategory(t, PNBadRequestCategory, respondWith(400, `{"error":true}`, nil))
+}
+
+func TestExecuteRequestServerTimeout(t *testing.T) {
+	assertExecuteRequestCategory(t, PNTimeoutCategory, respondWith(408, `{"error":true}`, nil))
+}
+
+func TestExecuteRequestServerError(t *testing.T) {
+	assertExecuteRequestCategory(t, PNUnknownCategory, respondWith(500, `{"error":true}`, nil))
+}
+
+func TestExecuteRequestConnectionTimeout(t *testing.T) {
+	err := assertExecuteRequestCategory(t, PNTimeoutCategory, failWith(timeoutTestError{}))
+
+	assert.True(t, pnerr.IsTimeout(err))
+}
+
+func TestExecuteRequestConnectionError(t *testing.T) {
+	err := assertExecuteRequestCategory(t, PNUnknownCategory, failWith(errors.New("connection refused")))
+
+	assert.False(t, pnerr.IsTimeout(err))
+}
+
+// recordingTransport records the requests it's given and the deadlines of their context.
+type recordingTransport struct {
+	requests  []*http.Request
+	deadlines []time.Time
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	deadline, _ := req.Context().Deadline()
+	t.requests = append(t.requests, req)
+	t.deadlines = append(t.deadlines, deadline)
+
+	return &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(strings.NewReader("[15078947309567840]")),
+		Request:    req,
+	}, nil
+}
+
+// longTimeoutOpts are timeOpts with a request timeout other than the NonSubscribeRequestTimeout.
+type longTimeoutOpts struct {
+	*timeOpts
+	timeout int
+}
+
+func (o *longTimeoutOpts) requestTimeout() int {
+	return o.timeout
+}
+
+func TestExecuteRequestTransport(t *testing.T) {
+	assert := assert.New(t)
+
+	config := NewDemoConfig()
+	config.MaxWorkers = 0
+	pn := NewPubNub(config)
+	defer pn.Destroy()
+
+	tr := &recordingTransport{}
+	opts := &longTimeoutOpts{
+		timeOpts: &timeOpts{pubnub: pn, Transport: tr},
+		timeout:  config.NonSubscribeRequestTimeout + 300,
+	}
+
+	timeout := time.Duration(opts.timeout) * time.Second
+	start := time.Now()
+	_, _, err := executeRequest(opts)
+	assert.Nil(err)
+	end := time.Now()
+
+	// the request was sent with the transport, within the request timeout of the opts
+	if assert.Len(tr.requests, 1) {
+		assert.Contains(tr.requests[0].URL.String(), "/time/0")
+		deadline := tr.deadlines[0]
+		assert.False(deadline.Before(start.Add(timeout)), deadline.Sub(start))
+		assert.False(deadline.After(end.Add(timeout)), deadline.Sub(start))
+	}
+}
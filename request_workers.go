@@ -1,13 +1,79 @@
 package pubnub
 
-import "net/http"
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
 
-type nonSubMsgType int
+// ErrRequestQueueFull is the error of the requests made while the queue of their RequestPriority
+// is full, with the PNRequestQueueFailFast RequestQueueFullPolicy.
+var ErrRequestQueueFull = errors.New("pubnub: request queue full")
 
-const (
-	messageTypePublish nonSubMsgType = 1 << iota
-	messageTypePAM
-)
+// requestPriorities are the priorities in the order their queues are read by the workers.
+var requestPriorities = []RequestPriority{PNRequestPriorityHigh, PNRequestPriorityNormal, PNRequestPriorityLow}
+
+// defaultRequestPriorities are the priorities of the operations which aren't PNRequestPriorityNormal,
+// unless they are set in the Config.RequestPriorities.
+var defaultRequestPriorities = RequestPriorities{
+	PNPublishOperation:           PNRequestPriorityHigh,
+	PNFireOperation:              PNRequestPriorityHigh,
+	PNSignalOperation:            PNRequestPriorityHigh,
+	PNUnsubscribeOperation:       PNRequestPriorityHigh,
+	PNHeartBeatOperation:         PNRequestPriorityHigh,
+	PNHereNowOperation:           PNRequestPriorityHigh,
+	PNWhereNowOperation:          PNRequestPriorityHigh,
+	PNSetStateOperation:          PNRequestPriorityHigh,
+	PNGetStateOperation:          PNRequestPriorityHigh,
+	PNTimeOperation:              PNRequestPriorityHigh,
+	PNAccessManagerGrant:         PNRequestPriorityHigh,
+	PNAccessManagerGrantToken:    PNRequestPriorityHigh,
+	PNHistoryOperation:           PNRequestPriorityLow,
+	PNFetchMessagesOperation:     PNRequestPriorityLow,
+	PNMessageCountsOperation:     PNRequestPriorityLow,
+	PNDeleteMessagesOperation:    PNRequestPriorityLow,
+	PNGetMessageActionsOperation: PNRequestPriorityLow,
+}
+
+// RequestPriorities sets the priorities of the operations on the request workers. The requests
+// waiting for a worker are sent by priority, then in the order they were made. The operations
+// which aren't set keep their default priority: high for the publish, presence, time and grant
+// requests, low for the history requests, and normal for the others.
+type RequestPriorities map[OperationType]RequestPriority
+
+// priority returns the priority of the requests of the operation.
+func (p RequestPriorities) priority(operation OperationType) RequestPriority {
+	if priority, ok := p[operation]; ok && priority >= PNRequestPriorityLow && priority <= PNRequestPriorityHigh {
+		return priority
+	}
+	if priority, ok := defaultRequestPriorities[operation]; ok {
+		return priority
+	}
+
+	return PNRequestPriorityNormal
+}
+
+// RequestQueueStats are the metrics of the request workers, see PubNub.RequestQueueStats.
+type RequestQueueStats struct {
+	// Workers is the number of request workers, Busy the number of them sending a request.
+	Workers int
+	Busy    int
+	// Queued is the number of requests waiting for a worker by priority.
+	Queued map[RequestPriority]int
+	// Operations are the metrics of the operations whose requests were queued.
+	Operations map[OperationType]OperationQueueStats
+}
+
+// OperationQueueStats are the request worker metrics of an operation.
+type OperationQueueStats struct {
+	Queued    int           // Number of requests waiting for a worker.
+	Sent      uint64        // Number of requests passed on to a worker.
+	Rejected  uint64        // Number of requests which failed with ErrRequestQueueFull.
+	TotalWait time.Duration // Sum of the waits for a worker of the sent requests, TotalWait / Sent is the mean wait.
+	MaxWait   time.Duration // Longest wait for a worker of the sent requests.
+}
 
 type JobQResponse struct {
 	Resp  *http.Response
@@ -18,88 +84,278 @@ type JobQItem struct {
 	Req         *http.Request
 	Client      *http.Client
 	JobResponse chan *JobQResponse
+
+	operation OperationType
+	priority  RequestPriority
+	queuedAt  time.Time
+
+	// done is called once the response is sent, for the pending jobs to be waited for.
+	done func()
 }
 
+// respond sends the response of the job.
+func (job *JobQItem) respond(res *JobQResponse) {
+	job.JobResponse <- res
+	if job.done != nil {
+		job.done()
+	}
+}
+
+// RequestWorkers send the non-subscribe requests, MaxWorkers of them at a time. The requests
+// waiting for a worker are queued by priority, each queue holds at most queueSize requests.
 type RequestWorkers struct {
-	workers    []Worker
-	Workers    chan chan *JobQItem
+	sync.Mutex
+
 	MaxWorkers int
-	Sem        chan bool
-}
-
-type Worker struct {
-	Workers    chan chan *JobQItem
-	JobChannel chan *JobQItem
-	ctx        Context
-	id         int
-}
-
-func newRequestWorkers(workers chan chan *JobQItem, id int, ctx Context) Worker {
-	return Worker{
-		Workers:    workers,
-		JobChannel: make(chan *JobQItem),
-		ctx:        ctx,
-		id:         id,
-	}
-}
-
-// Process runs a goroutine for the worker
-func (pw Worker) Process(pubnub *PubNub) {
-	go func() {
-	ProcessLabel:
-		for {
-			select {
-			case pw.Workers <- pw.JobChannel:
-				job := <-pw.JobChannel
-				if job != nil {
-					res, err := job.Client.Do(job.Req)
-					jqr := &JobQResponse{
-						Error: err,
-						Resp:  res,
-					}
-					job.JobResponse <- jqr
-					pubnub.Config.Log.Println("Request sent using worker id ", pw.id)
-				}
-			case <-pw.ctx.Done():
-				pubnub.Config.Log.Println("Exiting Worker Process by worker ctx, id ", pw.id)
-				break ProcessLabel
-			case <-pubnub.ctx.Done():
-				pubnub.Config.Log.Println("Exiting Worker Process by PN ctx, id ", pw.id)
-				break ProcessLabel
-			}
-		}
-	}()
+
+	queueSize  int
+	fullPolicy RequestQueueFullPolicy
+	queues     map[RequestPriority][]*JobQItem
+	busy       int
+	operations map[OperationType]*OperationQueueStats
+
+	// ready holds a value for each queued job, for the workers to wait for one.
+	ready chan struct{}
+	// space is notified when a job leaves the queue of the priority, for the blocked enqueue to retry.
+	space map[RequestPriority]chan struct{}
+
+	ctx      Context
+	cancel   func()
+	closed   bool
+	draining bool
+	pending  routineGroup
 }
 
-// Start starts the workers
+// Start starts the workers, they run until Close is called or the ctx is done.
 func (p *RequestWorkers) Start(pubnub *PubNub, ctx Context) {
-	pubnub.Config.Log.Println("Start: Running with workers ", p.MaxWorkers)
-	p.workers = make([]Worker, p.MaxWorkers)
+	pubnub.Config.logger().Debug("starting request workers", "workers", p.MaxWorkers)
+	if p.queueSize <= 0 {
+		p.queueSize = 1
+	}
+	p.ctx, p.cancel = contextWithCancel(ctx)
+	p.queues = make(map[RequestPriority][]*JobQItem)
+	p.operations = make(map[OperationType]*OperationQueueStats)
+	p.ready = make(chan struct{}, p.queueSize*len(requestPriorities))
+	p.space = make(map[RequestPriority]chan struct{})
+	for _, priority := range requestPriorities {
+		p.space[priority] = make(chan struct{}, 1)
+	}
+
 	for i := 0; i < p.MaxWorkers; i++ {
-		pubnub.Config.Log.Println("Start: StartNonSubWorker ", i)
-		worker := newRequestWorkers(p.Workers, i, ctx)
-		worker.Process(pubnub)
-		p.workers[i] = worker
+		id := i
+		pubnub.routines.spawn(func() {
+			p.work(pubnub, id)
+		})
 	}
-	go p.ReadQueue(pubnub)
 }
 
-// ReadQueue reads the queue and passes on the job to the workers
-func (p *RequestWorkers) ReadQueue(pubnub *PubNub) {
-	for job := range pubnub.jobQueue {
-		pubnub.Config.Log.Println("ReadQueue: Got job for channel ", job.Req)
-		go func(job *JobQItem) {
-			jobChannel := <-p.Workers
-			jobChannel <- job
-		}(job)
+// work sends the queued requests, the highest priority first, until the workers are closed.
+func (p *RequestWorkers) work(pubnub *PubNub, id int) {
+	for {
+		select {
+		case <-p.ready:
+		case <-p.ctx.Done():
+			pubnub.Config.logger().Debug("worker stopped by its context", "worker", id)
+			return
+		}
+
+		job, wait := p.next()
+		if job == nil {
+			continue
+		}
+
+		// Close cancels the request, until its response is read
+		req, release := cancelRequestOn(job.Req, p.ctx.Done())
+		res, err := job.Client.Do(req)
+		if err != nil {
+			release()
+		} else {
+			res.Body = &releasingBody{ReadCloser: res.Body, release: release}
+		}
+
+		p.Lock()
+		p.busy--
+		p.Unlock()
+
+		job.respond(&JobQResponse{
+			Error: err,
+			Resp:  res,
+		})
+		pubnub.Config.logger().Debug("request sent by worker", "worker", id, "operation", job.operation, "wait", wait)
+	}
+}
+
+// releasingBody is the body of a response sent by a worker, the request is released once the
+// body is read or closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.release()
 	}
-	pubnub.Config.Log.Println("ReadQueue: Exit")
+
+	return n, err
 }
 
-// Close closes the workers
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+
+	return err
+}
+
+// next takes the oldest job of the highest priority off its queue, nil is returned when the
+// queues were emptied by Close.
+func (p *RequestWorkers) next() (*JobQItem, time.Duration) {
+	p.Lock()
+	defer p.Unlock()
+
+	for _, priority := range requestPriorities {
+		queue := p.queues[priority]
+		if len(queue) == 0 {
+			continue
+		}
+
+		job := queue[0]
+		queue[0] = nil
+		p.queues[priority] = queue[1:]
+		p.busy++
+		notify(p.space[priority])
+
+		wait := time.Since(job.queuedAt)
+		stats := p.operationStats(job.operation)
+		stats.Queued--
+		stats.Sent++
+		stats.TotalWait += wait
+		if wait > stats.MaxWait {
+			stats.MaxWait = wait
+		}
+
+		return job, wait
+	}
+
+	return nil, 0
+}
+
+func (p *RequestWorkers) operationStats(operation OperationType) *OperationQueueStats {
+	stats, ok := p.operations[operation]
+	if !ok {
+		stats = &OperationQueueStats{}
+		p.operations[operation] = stats
+	}
+
+	return stats
+}
+
+// enqueue queues the job for the workers. When the queue of its priority is full, it fails with
+// ErrRequestQueueFull or waits for room depending on the RequestQueueFullPolicy, and fails with
+// the error of the ctx if it's done while waiting. It fails with ErrClosed once the workers are
+// draining or closed.
+func (p *RequestWorkers) enqueue(job *JobQItem, ctx Context) {
+	var ctxDone <-chan struct{}
+	if ctx != nil {
+		ctxDone = ctx.Done()
+	}
+
+	for {
+		p.Lock()
+		if p.closed || p.draining || p.ctx.Err() != nil {
+			p.Unlock()
+			job.respond(&JobQResponse{Error: ErrClosed})
+			return
+		}
+
+		stats := p.operationStats(job.operation)
+		queue := p.queues[job.priority]
+		if len(queue) < p.queueSize {
+			job.queuedAt = time.Now()
+			p.queues[job.priority] = append(queue, job)
+			stats.Queued++
+			p.pending.add()
+			job.done = p.pending.done
+			if len(queue)+1 < p.queueSize {
+				// passes on the room left to the next blocked enqueue
+				notify(p.space[job.priority])
+			}
+			p.ready <- struct{}{}
+			p.Unlock()
+			return
+		}
+
+		if p.fullPolicy == PNRequestQueueFailFast {
+			stats.Rejected++
+			p.Unlock()
+			job.respond(&JobQResponse{Error: ErrRequestQueueFull})
+			return
+		}
+
+		space := p.space[job.priority]
+		p.Unlock()
+
+		select {
+		case <-space:
+		case <-p.ctx.Done():
+			job.respond(&JobQResponse{Error: ErrClosed})
+			return
+		case <-ctxDone:
+			job.respond(&JobQResponse{Error: ctx.Err()})
+			return
+		}
+	}
+}
+
+// stats returns a snapshot of the metrics of the workers.
+func (p *RequestWorkers) stats() RequestQueueStats {
+	p.Lock()
+	defer p.Unlock()
+
+	stats := RequestQueueStats{
+		Workers:    p.MaxWorkers,
+		Busy:       p.busy,
+		Queued:     make(map[RequestPriority]int),
+		Operations: make(map[OperationType]OperationQueueStats),
+	}
+	for _, priority := range requestPriorities {
+		stats.Queued[priority] = len(p.queues[priority])
+	}
+	for operation, s := range p.operations {
+		stats.Operations[operation] = *s
+	}
+
+	return stats
+}
+
+// drain fails the jobs queued from now on with ErrClosed, and waits for the responses of the
+// pending ones, or for the ctx to be done.
+func (p *RequestWorkers) drain(ctx Context) error {
+	p.Lock()
+	p.draining = true
+	p.Unlock()
+
+	return p.pending.wait(ctx)
+}
+
+// Close stops the workers, the jobs not sent yet fail with ErrClosed and the ones being sent
+// are cancelled.
 func (p *RequestWorkers) Close() {
-	for _, w := range p.workers {
-		close(w.JobChannel)
-		w.ctx.Done()
+	p.Lock()
+	p.closed = true
+	var jobs []*JobQItem
+	for _, priority := range requestPriorities {
+		for _, job := range p.queues[priority] {
+			p.operationStats(job.operation).Queued--
+			jobs = append(jobs, job)
+		}
+		p.queues[priority] = nil
+	}
+	p.Unlock()
+
+	p.cancel()
+	for _, job := range jobs {
+		job.respond(&JobQResponse{Error: ErrClosed})
 	}
 }
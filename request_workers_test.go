@@ -0,0 +1,257 @@
+package pubnub
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sprucehealth/pubnub-go/pnerr"
+	"github.com/stretchr/testify/assert"
+)
+
+// gatedTransport holds the requests until the gate is opened or they are cancelled, and records the
+// order they were sent in.
+type gatedTransport struct {
+	sync.Mutex
+
+	gate chan struct{}
+	sent []string
+}
+
+func (t *gatedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u := req.URL.String()
+	operation, body := "other", "[]"
+	switch {
+	case strings.Contains(u, "/publish/"):
+		operation, body = "publish", `[1,"Sent","15078947309567840"]`
+	case strings.Contains(u, "/history/"):
+		operation, body = "history", `[[],0,0]`
+	}
+
+	t.Lock()
+	t.sent = append(t.sent, operation)
+	t.Unlock()
+
+	select {
+	case <-t.gate:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	return &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func (t *gatedTransport) count() int {
+	t.Lock()
+	defer t.Unlock()
+
+	return len(t.sent)
+}
+
+func newGatedPubNub(config *Config) (*PubNub, *gatedTransport) {
+	transport := &gatedTransport{gate: make(chan struct{})}
+	pn := NewPubNub(config)
+	pn.SetClient(&http.Client{Transport: transport})
+
+	return pn, transport
+}
+
+func TestRequestPrioritiesDefaults(t *testing.T) {
+	assert := assert.New(t)
+
+	var p RequestPriorities
+	assert.Equal(PNRequestPriorityHigh, p.priority(PNPublishOperation))
+	assert.Equal(PNRequestPriorityHigh, p.priority(PNHeartBeatOperation))
+	assert.Equal(PNRequestPriorityLow, p.priority(PNFetchMessagesOperation))
+	assert.Equal(PNRequestPriorityNormal, p.priority(PNGetUsersOperation))
+
+	p = RequestPriorities{
+		PNFetchMessagesOperation: PNRequestPriorityHigh,
+		PNPublishOperation:       RequestPriority(42),
+	}
+	assert.Equal(PNRequestPriorityHigh, p.priority(PNFetchMessagesOperation))
+	assert.Equal(PNRequestPriorityHigh, p.priority(PNPublishOperation))
+	assert.Equal(PNRequestPriorityLow, p.priority(PNHistoryOperation))
+}
+
+func TestRequestWorkersPriority(t *testing.T) {
+	assert := assert.New(t)
+
+	config := NewDemoConfig()
+	config.MaxWorkers = 1
+	pn, transport := newGatedPubNub(config)
+	defer pn.Destroy()
+
+	var wg sync.WaitGroup
+	run := func(f func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f()
+		}()
+	}
+	history := func() {
+		pn.History().Channel("ch").Execute()
+	}
+
+	// the only worker is held by the first history request
+	run(history)
+	waitUntil(t, func() bool {
+		return transport.count() == 1
+	})
+
+	run(history)
+	run(history)
+	waitUntil(t, func() bool {
+		return pn.RequestQueueStats().Queued[PNRequestPriorityLow] == 2
+	})
+	run(func() {
+		pn.Publish().Channel("ch").Message("hi").Execute()
+	})
+	waitUntil(t, func() bool {
+		return pn.RequestQueueStats().Queued[PNRequestPriorityHigh] == 1
+	})
+
+	stats := pn.RequestQueueStats()
+	assert.Equal(1, stats.Workers)
+	assert.Equal(1, stats.Busy)
+	assert.Equal(2, stats.Operations[PNHistoryOperation].Queued)
+	assert.Equal(uint64(1), stats.Operations[PNHistoryOperation].Sent)
+
+	close(transport.gate)
+	wg.Wait()
+
+	assert.Equal([]string{"history", "publish", "history", "history"}, transport.sent)
+
+	stats = pn.RequestQueueStats()
+	assert.Equal(0, stats.Busy)
+	assert.Equal(0, stats.Queued[PNRequestPriorityLow])
+	assert.Equal(uint64(3), stats.Operations[PNHistoryOperation].Sent)
+	assert.Equal(uint64(1), stats.Operations[PNPublishOperation].Sent)
+	assert.True(stats.Operations[PNHistoryOperation].MaxWait > 0)
+	assert.True(stats.Operations[PNHistoryOperation].TotalWait >= stats.Operations[PNHistoryOperation].MaxWait)
+}
+
+func TestRequestWorkersQueueFull(t *testing.T) {
+	assert := assert.New(t)
+
+	config := NewDemoConfig()
+	config.MaxWorkers = 1
+	config.RequestQueueSize = 1
+	config.RequestQueueFullPolicy = PNRequestQueueFailFast
+	pn, transport := newGatedPubNub(config)
+	defer pn.Destroy()
+
+	var wg sync.WaitGroup
+	history := func() {
+		defer wg.Done()
+		pn.History().Channel("ch").Execute()
+	}
+
+	// the first history request holds the worker, the second one fills the queue
+	wg.Add(2)
+	go history()
+	waitUntil(t, func() bool {
+		return transport.count() == 1
+	})
+	go history()
+	waitUntil(t, func() bool {
+		return pn.RequestQueueStats().Queued[PNRequestPriorityLow] == 1
+	})
+
+	_, _, err := pn.History().Channel("ch").Execute()
+	assert.NotNil(err)
+	assert.Equal(ErrRequestQueueFull, err.(*pnerr.ConnectionError).OrigError)
+	assert.Equal(uint64(1), pn.RequestQueueStats().Operations[PNHistoryOperation].Rejected)
+
+	// the queues of the other priorities still have room
+	done := make(chan bool)
+	go func() {
+		pn.Publish().Channel("ch").Message("hi").Execute()
+		close(done)
+	}()
+	waitUntil(t, func() bool {
+		return pn.RequestQueueStats().Queued[PNRequestPriorityHigh] == 1
+	})
+
+	close(transport.gate)
+	wg.Wait()
+	<-done
+}
+
+func TestRequestWorkersQueueFullBlock(t *testing.T) {
+	assert := assert.New(t)
+
+	config := NewDemoConfig()
+	config.MaxWorkers = 1
+	config.RequestQueueSize = 1
+	pn, transport := newGatedPubNub(config)
+	defer pn.Destroy()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pn.History().Channel("ch").Execute()
+		}()
+	}
+	waitUntil(t, func() bool {
+		return transport.count() == 1 && pn.RequestQueueStats().Queued[PNRequestPriorityLow] == 1
+	})
+
+	// the request waits for room in the queue until its ctx is done
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, _, err := pn.HistoryWithContext(ctx).Channel("ch").Execute()
+	assert.NotNil(err)
+	assert.Equal(context.DeadlineExceeded, err.(*pnerr.ConnectionError).OrigError)
+
+	// and is queued once there is room
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		pn.History().Channel("ch").Execute()
+	}()
+	close(transport.gate)
+	wg.Wait()
+
+	assert.Equal(3, transport.count())
+	assert.Equal(uint64(0), pn.RequestQueueStats().Operations[PNHistoryOperation].Rejected)
+}
+
+func TestRequestWorkersCloseCancelsRequests(t *testing.T) {
+	assert := assert.New(t)
+
+	config := NewDemoConfig()
+	config.MaxWorkers = 1
+	pn, transport := newGatedPubNub(config)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := pn.Publish().Channel("ch").Message("hey").Execute()
+		errs <- err
+	}()
+	waitUntil(t, func() bool { return transport.count() == 1 })
+
+	// Close waits for the request being sent until its ctx is done, then cancels it
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.Equal(context.DeadlineExceeded, pn.Close(ctx))
+
+	select {
+	case err := <-errs:
+		assert.True(pnerr.IsCancelled(err))
+	case <-time.After(5 * time.Second):
+		t.Fatal("the request was not cancelled")
+	}
+	waitUntil(t, func() bool { return pn.routines.running() == 0 })
+}
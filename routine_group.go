@@ -0,0 +1,72 @@
+package pubnub
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrClosed is the error of the requests queued on the request workers of a PubNub instance
+// which is closed or destroyed.
+var ErrClosed = errors.New("pubnub: closed")
+
+// routineGroup counts running goroutines or requests, for Close to wait until they are all done.
+// Unlike sync.WaitGroup, more can be added while waiting.
+type routineGroup struct {
+	sync.Mutex
+
+	count int
+	idle  chan struct{}
+}
+
+func (g *routineGroup) add() {
+	g.Lock()
+	g.count++
+	g.Unlock()
+}
+
+func (g *routineGroup) done() {
+	g.Lock()
+	g.count--
+	if g.count == 0 && g.idle != nil {
+		close(g.idle)
+		g.idle = nil
+	}
+	g.Unlock()
+}
+
+// spawn runs f in a goroutine counted by the group.
+func (g *routineGroup) spawn(f func()) {
+	g.add()
+	go func() {
+		defer g.done()
+		f()
+	}()
+}
+
+func (g *routineGroup) running() int {
+	g.Lock()
+	defer g.Unlock()
+
+	return g.count
+}
+
+// wait returns once nothing is running, or the error of the ctx if it's done before.
+func (g *routineGroup) wait(ctx Context) error {
+	g.Lock()
+	if g.count == 0 {
+		g.Unlock()
+		return nil
+	}
+	if g.idle == nil {
+		g.idle = make(chan struct{})
+	}
+	idle := g.idle
+	g.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -87,7 +87,13 @@ func (b *setStateBuilder) Execute() (*SetStateResponse, StatusResponse, error) {
 		return emptySetStateResponse, status, err
 	}
 
-	return newSetStateResponse(rawJSON, status)
+	resp, status, err := newSetStateResponse(rawJSON, status)
+	if err != nil {
+		return resp, status, err
+	}
+	resp.State = decryptState(b.opts.pubnub.Config, resp.State)
+
+	return resp, status, nil
 }
 
 type setStateOpts struct {
@@ -109,6 +115,10 @@ func (o *setStateOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *setStateOpts) transport() http.RoundTripper {
+	return nil
+}
+
 func (o *setStateOpts) context() Context {
 	return o.ctx
 }
@@ -125,7 +135,12 @@ func (o *setStateOpts) validate() error {
 	if o.State == nil {
 		return newValidationError(o, "Missing State")
 	}
-	state, err := json.Marshal(o.State)
+	encState, err := encryptState(o.pubnub.Config, o.State)
+	if err != nil {
+		return newValidationError(o, err.Error())
+	}
+
+	state, err := json.Marshal(encState)
 	if err != nil {
 		return newValidationError(o, err.Error())
 	}
@@ -163,12 +178,13 @@ func (o *setStateOpts) buildQuery() (*url.Values, error) {
 	if len(o.ChannelGroups) > 0 {
 		q.Set("channel-group", string(groups))
 	}
+	o.pubnub.tokenManager.setChannelsAuthParam(q, o.Channels, o.ChannelGroups)
 	SetQueryParam(q, o.QueryParam)
 	return q, nil
 }
 
-func (o *setStateOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *setStateOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *setStateOpts) buildBody() ([]byte, error) {
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	h "github.com/sprucehealth/pubnub-go/tests/helpers"
+	"github.com/sprucehealth/pubnub-go/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -126,6 +127,40 @@ func TestSetStateRequestBasic(t *testing.T) {
 
 }
 
+func TestSetStateCryptor(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	pn.Config.Cryptor = testXORCryptor{}
+
+	opts := &setStateOpts{
+		Channels: []string{"ch"},
+		State:    map[string]interface{}{"name": "Alex"},
+		pubnub:   pn,
+	}
+
+	err := opts.validate()
+	assert.Nil(err)
+
+	var state map[string]interface{}
+	assert.Nil(json.Unmarshal([]byte(opts.stringState), &state))
+	assert.NotEqual("Alex", state["name"])
+
+	decrypted := decryptState(pn.Config, state)
+	assert.Equal(map[string]interface{}{"name": "Alex"}, decrypted)
+
+	// like the published messages, the state is encrypted with the cipher key only
+	pn.Config.Cryptor = nil
+	pn.Config.CipherKey = "enigma"
+
+	err = opts.validate()
+	assert.Nil(err)
+	state = nil
+	assert.Nil(json.Unmarshal([]byte(opts.stringState), &state))
+	assert.Equal(utils.EncryptString("enigma", `"Alex"`), state["name"])
+	assert.Equal(map[string]interface{}{"name": "Alex"}, decryptState(pn.Config, state))
+}
+
 func TestSetStateMultipleChannels(t *testing.T) {
 	assert := assert.New(t)
 
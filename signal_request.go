@@ -107,6 +107,10 @@ func (o *signalOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *signalOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *signalOpts) context() Context {
 	return o.ctx
 }
@@ -135,7 +139,7 @@ func (o *signalOpts) buildPath() (string, error) {
 	var msg string
 	jsonEncBytes, errEnc := json.Marshal(o.Message)
 	if errEnc != nil {
-		o.pubnub.Config.Log.Printf("ERROR: Publish error: %s\n", errEnc.Error())
+		o.pubnub.Config.logger().Error("signal serialization failed", "error", errEnc)
 		return "", errEnc
 	}
 	msg = string(jsonEncBytes)
@@ -151,20 +155,21 @@ func (o *signalOpts) buildPath() (string, error) {
 func (o *signalOpts) buildQuery() (*url.Values, error) {
 	q := defaultQuery(o.pubnub.Config.UUID, o.pubnub.telemetryManager)
 
+	o.pubnub.tokenManager.setAuthParam(q, PNChannels, o.Channel)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *signalOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *signalOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *signalOpts) buildBody() ([]byte, error) {
 	if o.UsePost {
 		jsonEncBytes, errEnc := json.Marshal(o.Message)
 		if errEnc != nil {
-			o.pubnub.Config.Log.Printf("ERROR: Signal error: %s\n", errEnc.Error())
+			o.pubnub.Config.logger().Error("signal serialization failed", "error", errEnc)
 			return []byte{}, errEnc
 		}
 		return jsonEncBytes, nil
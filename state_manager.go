@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+
+	"github.com/sprucehealth/pubnub-go/utils"
 )
 
 // StateManager is used to store the subscriptions types
@@ -20,6 +22,7 @@ type StateManager struct {
 type SubscriptionItem struct {
 	name  string
 	state map[string]interface{}
+	refs  int // number of subscriptions which subscribed the item
 }
 
 func newStateManager() *StateManager {
@@ -38,13 +41,6 @@ func newSubscriptionItem(name string) *SubscriptionItem {
 	}
 }
 
-func newSubscriptionItemWithState(name string, state map[string]interface{}) *SubscriptionItem {
-	return &SubscriptionItem{
-		name:  name,
-		state: state,
-	}
-}
-
 func (m *StateManager) prepareChannelList(includePresence bool) []string {
 	m.RLock()
 	channels := m.channels
@@ -64,25 +60,19 @@ func (m *StateManager) prepareGroupList(includePresence bool) []string {
 }
 
 func (m *StateManager) adaptSubscribeOperation(
-	subscribeOperation *SubscribeOperation) {
+	subscribeOperation *SubscribeOperation) []subscriptionRef {
+	var refs []subscriptionRef
+
 	m.Lock()
 
 	for _, ch := range subscribeOperation.Channels {
 		if strings.Contains(ch, "-pnpres") {
-			if len(subscribeOperation.State) > 0 {
-				m.presenceChannels[ch] = newSubscriptionItemWithState(ch, subscribeOperation.State)
-			} else {
-				m.presenceChannels[ch] = newSubscriptionItem(ch)
-			}
+			refs = append(refs, m.retain(m.presenceChannels, ch, ch, false, subscribeOperation.State))
 		} else {
-			if len(subscribeOperation.State) > 0 {
-				m.channels[ch] = newSubscriptionItemWithState(ch, subscribeOperation.State)
-			} else {
-				m.channels[ch] = newSubscriptionItem(ch)
-			}
+			refs = append(refs, m.retain(m.channels, ch, ch, false, subscribeOperation.State))
 
 			if subscribeOperation.PresenceEnabled {
-				m.presenceChannels[ch] = newSubscriptionItem(ch)
+				refs = append(refs, m.retain(m.presenceChannels, ch, ch+"-pnpres", false, nil))
 			}
 		}
 
@@ -90,24 +80,79 @@ func (m *StateManager) adaptSubscribeOperation(
 
 	for _, cg := range subscribeOperation.ChannelGroups {
 		if strings.Contains(cg, "-pnpres") {
-			if len(subscribeOperation.State) > 0 {
-				m.presenceGroups[cg] = newSubscriptionItemWithState(cg, subscribeOperation.State)
-			} else {
-				m.presenceGroups[cg] = newSubscriptionItem(cg)
-			}
+			refs = append(refs, m.retain(m.presenceGroups, cg, cg, true, subscribeOperation.State))
 		} else {
-			if len(subscribeOperation.State) > 0 {
-				m.groups[cg] = newSubscriptionItemWithState(cg, subscribeOperation.State)
-			} else {
-				m.groups[cg] = newSubscriptionItem(cg)
-			}
+			refs = append(refs, m.retain(m.groups, cg, cg, true, subscribeOperation.State))
 
 			if subscribeOperation.PresenceEnabled {
-				m.presenceGroups[cg] = newSubscriptionItem(cg)
+				refs = append(refs, m.retain(m.presenceGroups, cg, cg+"-pnpres", true, nil))
 			}
 		}
 	}
 	m.Unlock()
+
+	return refs
+}
+
+// subscriptionRef is the reference of a Subscription to an item of the StateManager.
+type subscriptionRef struct {
+	storage map[string]*SubscriptionItem
+	key     string
+	name    string // name of the channel or group to unsubscribe
+	isGroup bool
+	item    *SubscriptionItem
+}
+
+// retain references the item of the storage, the item is added if it isn't subscribed yet.
+// The state replaces the state of the item when it is set.
+func (m *StateManager) retain(storage map[string]*SubscriptionItem, key, name string,
+	isGroup bool, state map[string]interface{}) subscriptionRef {
+	item, ok := storage[key]
+	if !ok {
+		item = newSubscriptionItem(key)
+		storage[key] = item
+	}
+	if len(state) > 0 {
+		item.state = state
+	}
+	item.refs++
+
+	return subscriptionRef{
+		storage: storage,
+		key:     key,
+		name:    name,
+		isGroup: isGroup,
+		item:    item,
+	}
+}
+
+// release drops the references of a Subscription. The items no other subscription references
+// are removed and returned as an UnsubscribeOperation. The items removed by an Unsubscribe
+// meanwhile are skipped.
+func (m *StateManager) release(refs []subscriptionRef) *UnsubscribeOperation {
+	unsubscribeOperation := &UnsubscribeOperation{}
+
+	m.Lock()
+	for _, ref := range refs {
+		if item, ok := ref.storage[ref.key]; !ok || item != ref.item {
+			continue
+		}
+
+		ref.item.refs--
+		if ref.item.refs > 0 {
+			continue
+		}
+
+		delete(ref.storage, ref.key)
+		if ref.isGroup {
+			unsubscribeOperation.ChannelGroups = append(unsubscribeOperation.ChannelGroups, ref.name)
+		} else {
+			unsubscribeOperation.Channels = append(unsubscribeOperation.Channels, ref.name)
+		}
+	}
+	m.Unlock()
+
+	return unsubscribeOperation
 }
 
 func (m *StateManager) adaptStateOperation(stateOperation StateOperation) {
@@ -182,8 +227,8 @@ func (m *StateManager) isEmpty() bool {
 	m.RLock()
 	defer m.RUnlock()
 
-	return len(m.channels) != 0 && len(m.presenceChannels) != 0 &&
-		len(m.groups) != 0 && len(m.presenceGroups) != 0
+	return len(m.channels) == 0 && len(m.presenceChannels) == 0 &&
+		len(m.groups) == 0 && len(m.presenceGroups) == 0
 }
 
 func (m *StateManager) hasNonPresenceChannels() bool {
@@ -216,3 +261,66 @@ func (m *StateManager) prepareMembershipList(dataStorage map[string]*Subscriptio
 
 	return response
 }
+
+// encryptState encrypts each of the values of the state with the crypto module of the config, like
+// the published messages. The state is returned as is when no Cryptor or CipherKey is set.
+func encryptState(pnConf *Config, state map[string]interface{}) (map[string]interface{}, error) {
+	module := pnConf.cryptoModule("")
+	if module == nil || state == nil {
+		return state, nil
+	}
+
+	encrypted := make(map[string]interface{}, len(state))
+	for k, v := range state {
+		enc, err := utils.SerializeAndEncryptWithModule(v, module, true)
+		if err != nil {
+			return nil, err
+		}
+		encrypted[k] = enc
+	}
+
+	return encrypted, nil
+}
+
+// encryptChannelsState encrypts the state of each of the channels with encryptState.
+func encryptChannelsState(pnConf *Config, state map[string]interface{}) (map[string]interface{}, error) {
+	if pnConf.cryptoModule("") == nil || state == nil {
+		return state, nil
+	}
+
+	encrypted := make(map[string]interface{}, len(state))
+	for ch, v := range state {
+		if s, ok := v.(map[string]interface{}); ok {
+			encState, err := encryptState(pnConf, s)
+			if err != nil {
+				return nil, err
+			}
+			encrypted[ch] = encState
+		} else {
+			encrypted[ch] = v
+		}
+	}
+
+	return encrypted, nil
+}
+
+// decryptState decrypts the values of the state encrypted by encryptState.
+// The values which cannot be decrypted are returned as is.
+func decryptState(pnConf *Config, state interface{}) interface{} {
+	s, ok := state.(map[string]interface{})
+	if pnConf.cryptoModule("") == nil || !ok {
+		return state
+	}
+
+	decrypted := make(map[string]interface{}, len(s))
+	for k, v := range s {
+		if _, isString := v.(string); isString {
+			if dec, err := parseCipherInterface(v, pnConf); err == nil {
+				v = dec
+			}
+		}
+		decrypted[k] = v
+	}
+
+	return decrypted
+}
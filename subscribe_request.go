@@ -2,6 +2,7 @@ package pubnub
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -12,6 +13,10 @@ import (
 
 const subscribePath = "/v2/subscribe/%s/%s/0"
 
+// ErrSubscribeStopped is returned by ExecuteContext when the subscribe loop is stopped or
+// unsubscribed before connecting.
+var ErrSubscribeStopped = errors.New("pubnub: subscribe loop stopped before connecting")
+
 type subscribeOpts struct {
 	pubnub *PubNub
 
@@ -32,6 +37,7 @@ type subscribeOpts struct {
 type subscribeBuilder struct {
 	opts      *subscribeOpts
 	operation *SubscribeOperation
+	listener  *Listener
 }
 
 func newSubscribeBuilder(pubnub *PubNub) *subscribeBuilder {
@@ -93,9 +99,60 @@ func (b *subscribeBuilder) QueryParam(queryParam map[string]string) *subscribeBu
 	return b
 }
 
-// Execute runs the Subscribe operation.
-func (b *subscribeBuilder) Execute() {
-	b.opts.pubnub.subscriptionManager.adaptSubscribe(b.operation)
+// Listener sets the listener of the Subscription, which receives the events of its channels and
+// groups only. The listener is removed on Subscription.Unsubscribe. A listener already added with
+// AddListener or set on another Subscription is copied, see Subscription.Listener.
+func (b *subscribeBuilder) Listener(listener *Listener) *subscribeBuilder {
+	b.listener = listener
+
+	return b
+}
+
+// Execute runs the Subscribe operation. The returned Subscription can be ignored, the listeners
+// added with AddListener receive the events of all the subscriptions.
+func (b *subscribeBuilder) Execute() *Subscription {
+	pn := b.opts.pubnub
+
+	subscription := newSubscription(pn, b.operation)
+	if b.listener != nil {
+		subscription.setListener(b.listener)
+	}
+	subscription.refs = pn.subscriptionManager.adaptSubscribe(b.operation)
+
+	return subscription
+}
+
+// ExecuteContext runs the Subscribe operation like Execute, and blocks until the subscribe loop
+// is connected. The error of a denied or failed subscribe is returned, or the error of the ctx
+// once it's done. The subscription is unsubscribed when an error is returned.
+func (b *subscribeBuilder) ExecuteContext(ctx Context) (*Subscription, error) {
+	if len(b.operation.Channels) == 0 && len(b.operation.ChannelGroups) == 0 {
+		return nil, newValidationError(b.opts, StrMissingChannel)
+	}
+
+	changes, stop := b.opts.pubnub.WatchConnectionState()
+	defer stop()
+
+	subscription := b.Execute()
+
+	for {
+		select {
+		case change := <-changes:
+			switch change.Current {
+			case PNReceivingState:
+				return subscription, nil
+			case PNFailedState, PNStoppedState, PNUnsubscribedState:
+				subscription.Unsubscribe()
+				if change.Error != nil {
+					return nil, change.Error
+				}
+				return nil, ErrSubscribeStopped
+			}
+		case <-ctx.Done():
+			subscription.Unsubscribe()
+			return nil, ctx.Err()
+		}
+	}
 }
 
 func (o *subscribeOpts) config() Config {
@@ -106,6 +163,10 @@ func (o *subscribeOpts) client() *http.Client {
 	return o.pubnub.GetSubscribeClient()
 }
 
+func (o *subscribeOpts) transport() http.RoundTripper {
+	return nil
+}
+
 func (o *subscribeOpts) context() Context {
 	return o.ctx
 }
@@ -124,7 +185,12 @@ func (o *subscribeOpts) validate() error {
 	}
 
 	if o.State != nil {
-		state, err := json.Marshal(o.State)
+		encState, err := encryptChannelsState(o.pubnub.Config, o.State)
+		if err != nil {
+			return newValidationError(o, err.Error())
+		}
+
+		state, err := json.Marshal(encState)
 		if err != nil {
 			return newValidationError(o, err.Error())
 		}
@@ -171,13 +237,14 @@ func (o *subscribeOpts) buildQuery() (*url.Values, error) {
 	if o.stringState != "" {
 		q.Set("state", o.stringState)
 	}
+	o.pubnub.tokenManager.setChannelsAuthParam(q, o.Channels, o.ChannelGroups)
 	SetQueryParam(q, o.QueryParam)
 
 	return q, nil
 }
 
-func (o *subscribeOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *subscribeOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *subscribeOpts) buildBody() ([]byte, error) {
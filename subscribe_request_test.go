@@ -1,10 +1,16 @@
 package pubnub
 
 import (
+	"context"
+	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
+	"github.com/sprucehealth/pubnub-go/pnerr"
+	"github.com/sprucehealth/pubnub-go/pubnubtest"
 	h "github.com/sprucehealth/pubnub-go/tests/helpers"
+	"github.com/sprucehealth/pubnub-go/tests/stubs"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -194,3 +200,131 @@ func TestSubscribeValidateState(t *testing.T) {
 
 	assert.Nil(opts.validate())
 }
+
+func newTestServerPubNub(srv *pubnubtest.Server, uuid string) *PubNub {
+	config := NewConfig()
+	config.PublishKey = "pub"
+	config.SubscribeKey = "sub"
+	config.UUID = uuid
+
+	pn := NewPubNub(config)
+	pn.SetClient(srv.Client())
+	pn.SetSubscribeClient(srv.Client())
+
+	return pn
+}
+
+func TestSubscribeExecuteContext(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	pn := newTestServerPubNub(srv, "subscriber")
+	defer pn.Destroy()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	subscription, err := pn.Subscribe().Channels([]string{"ch"}).ExecuteContext(ctx)
+	assert.Nil(err)
+	if assert.NotNil(subscription) {
+		assert.Equal([]string{"ch"}, subscription.Channels())
+	}
+	assert.Equal(PNReceivingState, pn.ConnectionState())
+
+	err = pn.Unsubscribe().Channels([]string{"ch"}).ExecuteContext(ctx)
+	assert.Nil(err)
+	assert.Empty(srv.Occupants("ch"))
+}
+
+func TestSubscribeExecuteContextAccessDenied(t *testing.T) {
+	assert := assert.New(t)
+
+	interceptor := stubs.NewInterceptor()
+	interceptor.AddStub(&stubs.Stub{
+		Method:             "GET",
+		Path:               "/v2/subscribe/sub/ch/0",
+		ResponseBody:       `{"message":"Forbidden","payload":{"channels":["ch"]},"error":true,"service":"Access Manager","status":403}`,
+		IgnoreQueryKeys:    []string{"pnsdk", "uuid", "heartbeat"},
+		ResponseStatusCode: 403,
+	})
+
+	config := NewConfig()
+	config.PublishKey = "pub"
+	config.SubscribeKey = "sub"
+	config.SuppressLeaveEvents = true
+
+	pn := NewPubNub(config)
+	defer pn.Destroy()
+	pn.SetSubscribeClient(&http.Client{Transport: interceptor.Transport})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	subscription, err := pn.Subscribe().Channels([]string{"ch"}).ExecuteContext(ctx)
+	assert.Nil(subscription)
+	assert.True(pnerr.IsAccessDenied(err))
+	assert.Empty(pn.GetSubscribedChannels())
+}
+
+func TestSubscribeExecuteContextDeadline(t *testing.T) {
+	assert := assert.New(t)
+
+	config := NewConfig()
+	config.PublishKey = "pub"
+	config.SubscribeKey = "sub"
+	config.SuppressLeaveEvents = true
+
+	pn := NewPubNub(config)
+	defer pn.Destroy()
+	pn.SetSubscribeClient(&http.Client{Transport: &longPollTransport{interceptor: stubs.NewInterceptor()}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	subscription, err := pn.Subscribe().Channels([]string{"ch"}).ExecuteContext(ctx)
+	assert.Nil(subscription)
+	assert.Equal(context.DeadlineExceeded, err)
+	assert.Empty(pn.GetSubscribedChannels())
+}
+
+func TestSubscribeExecuteContextNoChannels(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+
+	subscription, err := pn.Subscribe().ExecuteContext(context.Background())
+	assert.Nil(subscription)
+	assert.Contains(err.Error(), StrMissingChannel)
+	assert.IsType(&pnerr.ValidationError{}, err)
+	assert.Equal(PNUnsubscribedState, pn.ConnectionState())
+}
+
+func TestSubscribeExecuteContextUnsubscribed(t *testing.T) {
+	assert := assert.New(t)
+
+	config := NewConfig()
+	config.PublishKey = "pub"
+	config.SubscribeKey = "sub"
+	config.SuppressLeaveEvents = true
+
+	pn := NewPubNub(config)
+	defer pn.Destroy()
+	pn.SetSubscribeClient(&http.Client{Transport: &longPollTransport{interceptor: stubs.NewInterceptor()}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() {
+		for pn.ConnectionState() != PNHandshakingState {
+			time.Sleep(10 * time.Millisecond)
+		}
+		pn.UnsubscribeAll()
+	}()
+
+	subscription, err := pn.Subscribe().Channels([]string{"ch"}).ExecuteContext(ctx)
+	assert.Nil(subscription)
+	assert.Equal(ErrSubscribeStopped, err)
+}
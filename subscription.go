@@ -0,0 +1,161 @@
+package pubnub
+
+import (
+	"strings"
+	"sync"
+)
+
+// Subscription is returned by Subscribe. Its listener receives the events of its channels,
+// channel groups and wildcard channels only, and Unsubscribe leaves the channels and groups
+// which no other Subscription still needs.
+type Subscription struct {
+	sync.Mutex
+
+	pubnub       *PubNub
+	listener     *Listener
+	unsubscribed bool
+	refs         []subscriptionRef
+
+	channels      []string
+	channelGroups []string
+	names         map[string]bool
+	withPresence  bool
+
+	unsubscribeOnce sync.Once
+}
+
+func newSubscription(pubnub *PubNub, operation *SubscribeOperation) *Subscription {
+	s := &Subscription{
+		pubnub:        pubnub,
+		channels:      operation.Channels,
+		channelGroups: operation.ChannelGroups,
+		names:         make(map[string]bool, len(operation.Channels)+len(operation.ChannelGroups)),
+		withPresence:  operation.PresenceEnabled,
+	}
+
+	for _, ch := range operation.Channels {
+		s.names[ch] = true
+	}
+	for _, cg := range operation.ChannelGroups {
+		s.names[cg] = true
+	}
+
+	return s
+}
+
+// setListener adds the listener, or a copy of it when it's already added or used by another
+// subscription, with the events filtered for the subscription.
+func (s *Subscription) setListener(listener *Listener) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.unsubscribed {
+		return
+	}
+	s.listener = s.pubnub.subscriptionManager.listenerManager.addFilteredListener(listener, s.accepts)
+}
+
+// Listener returns the listener of the subscription: the one set with the Listener of the builder,
+// its copy if it was already in use, or a listener created on the first call, which receives the
+// events from then on, queued with the ListenerQueueSize and ListenerOverflowPolicy of the Config.
+// After Unsubscribe, the listener receives no event.
+func (s *Subscription) Listener() *Listener {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.listener == nil {
+		listener := NewListenerWithQueue(s.pubnub.Config.ListenerQueueSize, s.pubnub.Config.ListenerOverflowPolicy)
+		if s.unsubscribed {
+			return listener
+		}
+		s.listener = s.pubnub.subscriptionManager.listenerManager.addFilteredListener(listener, s.accepts)
+	}
+
+	return s.listener
+}
+
+// Channels returns the channels of the subscription.
+func (s *Subscription) Channels() []string {
+	return s.channels
+}
+
+// ChannelGroups returns the channel groups of the subscription.
+func (s *Subscription) ChannelGroups() []string {
+	return s.channelGroups
+}
+
+// Unsubscribe removes the listener of the subscription and unsubscribes from the channels
+// and groups no other subscription was created for. Subsequent calls do nothing.
+func (s *Subscription) Unsubscribe() {
+	s.unsubscribeOnce.Do(func() {
+		s.Lock()
+		s.unsubscribed = true
+		listener := s.listener
+		s.Unlock()
+
+		if listener != nil {
+			s.pubnub.RemoveListener(listener)
+		}
+		s.pubnub.subscriptionManager.releaseSubscription(s.refs)
+	})
+}
+
+// matches returns true if the event of the channel, received for the subscription match
+// (a channel group or a wildcard channel), is for this subscription.
+func (s *Subscription) matches(channel, subscription string, presence bool) bool {
+	for _, name := range []string{channel, subscription} {
+		if name == "" {
+			continue
+		}
+		if presence {
+			if s.names[name+"-pnpres"] {
+				return true
+			}
+			if !s.withPresence {
+				continue
+			}
+		}
+		if s.names[name] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesAny returns true if any of the channels or groups is a channel or group of the subscription.
+func (s *Subscription) matchesAny(names []string) bool {
+	for _, name := range names {
+		if s.names[name] || s.names[strings.TrimSuffix(name, "-pnpres")] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// accepts filters the events sent to the listener of the subscription. The statuses which
+// don't affect specific channels are accepted.
+func (s *Subscription) accepts(event listenerEvent) bool {
+	switch e := event.payload.(type) {
+	case *PNStatus:
+		if len(e.AffectedChannels) == 0 && len(e.AffectedChannelGroups) == 0 {
+			return true
+		}
+		return s.matchesAny(e.AffectedChannels) || s.matchesAny(e.AffectedChannelGroups)
+	case *PNMessage:
+		return s.matches(e.Channel, e.Subscription, false)
+	case *PNPresence:
+		return s.matches(e.Channel, e.Subscription, true)
+	case *PNUserEvent:
+		return s.matches(e.Channel, e.Subscription, false)
+	case *PNSpaceEvent:
+		return s.matches(e.Channel, e.Subscription, false)
+	case *PNMembershipEvent:
+		return s.matches(e.Channel, e.Subscription, false)
+	case *PNMessageActionsEvent:
+		return s.matches(e.Channel, e.Subscription, false)
+	}
+
+	return false
+}
@@ -4,13 +4,14 @@ import (
 	"encoding/json"
 	"errors"
 	//"fmt"
-	"github.com/sprucehealth/pubnub-go/utils"
 	"net/http"
 	"reflect"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/sprucehealth/pubnub-go/pnerr"
 )
 
 // SubscriptionManager Events:
@@ -46,6 +47,9 @@ type SubscriptionManager struct {
 
 	listenerManager     *ListenerManager
 	stateManager        *StateManager
+	catchUp             *catchUp
+	connection          *connectionStateMachine
+	dedupCache          *dedupCache
 	pubnub              *PubNub
 	reconnectionManager *ReconnectionManager
 	transport           http.RoundTripper
@@ -62,9 +66,15 @@ type SubscriptionManager struct {
 	// When changing the channel mix, store the timetoken for a later date
 	storedTimetoken int64
 
-	region int8
+	// The region of the timetoken, sent along with it for the next long-poll to
+	// be served from the same region. 0 when unknown.
+	region       int8
+	storedRegion int8
+
+	// disconnected is set by PubNub.Disconnect, the loop isn't restarted by the reconnections
+	// until PubNub.Reconnect or a subscription change.
+	disconnected bool
 
-	subscriptionStateAnnounced   bool
 	heartbeatStopCalled          bool
 	exitSubscriptionManagerMutex sync.Mutex
 	exitSubscriptionManager      chan bool
@@ -107,9 +117,12 @@ func newSubscriptionManager(pubnub *PubNub, ctx Context) *SubscriptionManager {
 	manager.Lock()
 	manager.timetoken = 0
 	manager.storedTimetoken = -1
-	manager.subscriptionStateAnnounced = false
+	manager.connection = newConnectionStateMachine(pubnub)
+	manager.connection.stopped = manager.loopStopped
 	manager.ctx, manager.subscribeCancel = contextWithCancel(backgroundContext)
 	manager.messages = make(chan subscribeMessage, 1000)
+	manager.catchUp = newCatchUp()
+	manager.dedupCache = newDedupCache(pubnub.Config.DedupCacheSize, time.Duration(pubnub.Config.DedupCacheTTL)*time.Second)
 	manager.reconnectionManager = newReconnectionManager(pubnub)
 	manager.channelsOpen = true
 	manager.Unlock()
@@ -117,11 +130,23 @@ func newSubscriptionManager(pubnub *PubNub, ctx Context) *SubscriptionManager {
 	if manager.pubnub.Config.PNReconnectionPolicy != PNNonePolicy {
 
 		manager.reconnectionManager.HandleReconnection(func() {
-			go manager.reconnect()
+			if manager.isDisconnected() {
+				return
+			}
 
+			// the live messages are held from now on, until the missed ones are announced
+			catchUpGeneration := 0
 			manager.Lock()
-			manager.subscriptionStateAnnounced = true
+			catchUpTimetoken := manager.timetoken
 			manager.Unlock()
+			if pubnub.Config.CatchUpOnReconnect && catchUpTimetoken > 0 {
+				catchUpGeneration = manager.catchUp.start()
+			}
+
+			// the Connected status is not announced after the Reconnected one
+			manager.connection.handle(connectionReconnected, nil)
+
+			pubnub.routines.spawn(manager.reconnect)
 			combinedChannels := manager.stateManager.prepareChannelList(true)
 			combinedGroups := manager.stateManager.prepareGroupList(true)
 
@@ -132,13 +157,25 @@ func newSubscriptionManager(pubnub *PubNub, ctx Context) *SubscriptionManager {
 				Category:              PNReconnectedCategory,
 			}
 
-			pubnub.Config.Log.Println("Status: ", pnStatus)
+			pubnub.Config.logger().Info("subscribe reconnected", "channels", combinedChannels, "groups", combinedGroups)
 
 			manager.listenerManager.announceStatus(pnStatus)
+
+			if catchUpGeneration != 0 {
+				pubnub.routines.spawn(func() {
+					manager.runCatchUp(catchUpGeneration, catchUpTimetoken)
+				})
+			}
 		})
 	}
 
+	manager.reconnectionManager.HandleDisconnection(func(err error) {
+		manager.connection.handle(connectionLost, err)
+	})
+
 	manager.reconnectionManager.HandleOnMaxReconnectionExhaustion(func() {
+		manager.connection.handle(connectionFailed, errors.New("reconnection attempts exhausted"))
+
 		combinedChannels := manager.stateManager.prepareChannelList(true)
 		combinedGroups := manager.stateManager.prepareGroupList(true)
 
@@ -148,7 +185,7 @@ func newSubscriptionManager(pubnub *PubNub, ctx Context) *SubscriptionManager {
 			AffectedChannelGroups: combinedGroups,
 			Category:              PNReconnectionAttemptsExhausted,
 		}
-		pubnub.Config.Log.Println("Status: ", pnStatus)
+		pubnub.Config.logger().Error("subscribe reconnection attempts exhausted", "channels", combinedChannels, "groups", combinedGroups)
 
 		manager.listenerManager.announceStatus(pnStatus)
 
@@ -167,26 +204,18 @@ func newSubscriptionManager(pubnub *PubNub, ctx Context) *SubscriptionManager {
 }
 
 func (m *SubscriptionManager) Destroy() {
-	if m.subscribeCancel != nil {
-		m.subscribeCancel()
-	}
-	if m.channelsOpen {
-		m.RLock()
-		m.channelsOpen = false
-		m.RUnlock()
-		if m.exitSubscriptionManager != nil {
-			close(m.exitSubscriptionManager)
-		}
-		if m.listenerManager.exitListener != nil {
-			close(m.listenerManager.exitListener)
-		}
-		if m.listenerManager.exitListenerAnnounce != nil {
-			close(m.listenerManager.exitListenerAnnounce)
-		}
-		if m.reconnectionManager.exitReconnectionManager != nil {
-			close(m.reconnectionManager.exitReconnectionManager)
-		}
+	m.connection.handle(connectionStop, nil)
+	m.stopSubscribeLoop()
+	m.reconnectionManager.stopHeartbeatTimer()
 
+	m.Lock()
+	channelsOpen := m.channelsOpen
+	m.channelsOpen = false
+	m.Unlock()
+
+	if channelsOpen {
+		m.stopMessageWorker()
+		close(m.listenerManager.exitListener)
 	}
 
 }
@@ -196,45 +225,78 @@ func (m *SubscriptionManager) adaptState(stateOperation StateOperation) {
 }
 
 func (m *SubscriptionManager) adaptSubscribe(
-	subscribeOperation *SubscribeOperation) {
-	m.stateManager.adaptSubscribeOperation(subscribeOperation)
-	m.pubnub.Config.Log.Println("adapting a new subscription", subscribeOperation.Channels,
-		subscribeOperation.PresenceEnabled)
+	subscribeOperation *SubscribeOperation) []subscriptionRef {
+	refs := m.stateManager.adaptSubscribeOperation(subscribeOperation)
+	m.pubnub.Config.logger().Debug("adapting a new subscription", "channels", subscribeOperation.Channels,
+		"groups", subscribeOperation.ChannelGroups, "presence", subscribeOperation.PresenceEnabled)
+
+	m.connection.handle(connectionSubscribe, nil)
 
 	m.Lock()
 
-	m.subscriptionStateAnnounced = false
 	m.queryParam = subscribeOperation.QueryParam
 
 	if subscribeOperation.Timetoken != 0 {
 		m.timetoken = subscribeOperation.Timetoken
+		m.region = 0
 	}
 
 	if m.timetoken != 0 {
 		m.storedTimetoken = m.timetoken
+		m.storedRegion = m.region
 	}
 
 	m.timetoken = 0
+	m.region = 0
 
 	m.Unlock()
 
 	m.reconnect()
+
+	return refs
 }
 
 func (m *SubscriptionManager) adaptUnsubscribe(
-	unsubscribeOperation *UnsubscribeOperation) {
-	m.pubnub.Config.Log.Println("before adaptUnsubscribeOperation")
+	unsubscribeOperation *UnsubscribeOperation) <-chan error {
 	m.stateManager.adaptUnsubscribeOperation(unsubscribeOperation)
-	m.pubnub.Config.Log.Println("after adaptUnsubscribeOperation")
+	m.pubnub.Config.logger().Debug("adapted an unsubscription", "channels", unsubscribeOperation.Channels, "groups", unsubscribeOperation.ChannelGroups)
 
-	m.Lock()
-	m.subscriptionStateAnnounced = false
-	m.Unlock()
+	return m.unsubscribe(unsubscribeOperation)
+}
+
+// releaseSubscription unsubscribes from the channels and groups of the refs which no other
+// subscription references.
+func (m *SubscriptionManager) releaseSubscription(refs []subscriptionRef) {
+	unsubscribeOperation := m.stateManager.release(refs)
+	if len(unsubscribeOperation.Channels) == 0 && len(unsubscribeOperation.ChannelGroups) == 0 {
+		return
+	}
+	m.pubnub.Config.logger().Debug("released a subscription", "channels", unsubscribeOperation.Channels, "groups", unsubscribeOperation.ChannelGroups)
+
+	m.unsubscribe(unsubscribeOperation)
+}
+
+// unsubscribe sends the leave of the channels and groups removed from the StateManager and
+// resubscribes to the remaining ones. The error of the leave request is sent on the returned
+// channel once it completes.
+func (m *SubscriptionManager) unsubscribe(unsubscribeOperation *UnsubscribeOperation) <-chan error {
+	if m.stateManager.isEmpty() {
+		m.connection.handle(connectionUnsubscribeAll, nil)
+	} else {
+		m.connection.handle(connectionSubscribe, nil)
+	}
+
+	left := make(chan error, 1)
+
+	m.pubnub.routines.spawn(func() {
+		var err error
+		defer func() {
+			left <- err
+		}()
 
-	go func() {
 		announceAck := false
 		if !m.pubnub.Config.SuppressLeaveEvents {
-			_, err := m.pubnub.Leave().Channels(unsubscribeOperation.Channels).
+			_, err = m.pubnub.Leave().Channels(unsubscribeOperation.Channels).
 				ChannelGroups(unsubscribeOperation.ChannelGroups).QueryParam(unsubscribeOperation.QueryParam).Execute()
 
 			if err != nil {
@@ -246,7 +308,7 @@ func (m *SubscriptionManager) adaptUnsubscribe(
 					AffectedChannels:      unsubscribeOperation.Channels,
 					AffectedChannelGroups: unsubscribeOperation.ChannelGroups,
 				}
-				m.pubnub.Config.Log.Println("Leave: err", err, pnStatus)
+				m.pubnub.Config.logger().Error("leave failed", "error", err)
 				m.listenerManager.announceStatus(pnStatus)
 			} else {
 				announceAck = true
@@ -264,36 +326,36 @@ func (m *SubscriptionManager) adaptUnsubscribe(
 				AffectedChannels:      unsubscribeOperation.Channels,
 				AffectedChannelGroups: unsubscribeOperation.ChannelGroups,
 			}
-			m.pubnub.Config.Log.Println("Leave: ack", pnStatus)
+			m.pubnub.Config.logger().Debug("leave sent", "channels", unsubscribeOperation.Channels, "groups", unsubscribeOperation.ChannelGroups)
 			m.listenerManager.announceStatus(pnStatus)
-			m.pubnub.Config.Log.Println("After Leave: ack", pnStatus)
 		}
-	}()
-	m.pubnub.Config.Log.Println("before storedTimetoken reset")
+	})
 	m.Lock()
 	if m.stateManager.isEmpty() {
-		m.region = 0
 		m.storedTimetoken = -1
-		m.timetoken = 0
+		m.storedRegion = 0
 	} else {
 		m.storedTimetoken = m.timetoken
-		m.timetoken = 0
+		m.storedRegion = m.region
 	}
+	m.timetoken = 0
+	m.region = 0
 	m.Unlock()
-	m.pubnub.Config.Log.Println("after storedTimetoken reset")
 
 	m.reconnect()
-	m.pubnub.Config.Log.Println("after reconnect")
+
+	return left
 }
 
 func (m *SubscriptionManager) startSubscribeLoop() {
-	m.pubnub.Config.Log.Println("startSubscribeLoop")
-	go subscribeMessageWorker(m)
+	m.pubnub.Config.logger().Debug("subscribe loop starting")
+	m.pubnub.routines.spawn(func() {
+		subscribeMessageWorker(m)
+	})
 
-	go m.reconnectionManager.startPolling()
+	m.pubnub.routines.spawn(m.reconnectionManager.startPolling)
 
 	for {
-		m.pubnub.Config.Log.Println("startSubscribeLoop looping...")
 		combinedChannels := m.stateManager.prepareChannelList(true)
 		combinedGroups := m.stateManager.prepareGroupList(true)
 
@@ -301,14 +363,15 @@ func (m *SubscriptionManager) startSubscribeLoop() {
 			m.listenerManager.announceStatus(&PNStatus{
 				Category: PNDisconnectedCategory,
 			})
-			m.pubnub.Config.Log.Println("no channels left to subscribe")
-			m.reconnectionManager.stopHeartbeatTimer()
+			m.pubnub.Config.logger().Debug("subscribe loop stopped, no channels left")
+			m.connection.handle(connectionUnsubscribeAll, nil)
 
 			break
 		}
 
 		m.Lock()
 		tt := m.timetoken
+		region := m.region
 		ctx := m.ctx
 		m.Unlock()
 
@@ -323,6 +386,10 @@ func (m *SubscriptionManager) startSubscribeLoop() {
 			QueryParam:       m.queryParam,
 		}
 
+		if tt != 0 && region != 0 {
+			opts.Region = strconv.Itoa(int(region))
+		}
+
 		if s := m.stateManager.createStatePayload(); len(s) > 0 {
 			opts.State = s
 		}
@@ -330,76 +397,63 @@ func (m *SubscriptionManager) startSubscribeLoop() {
 		m.requestSentAt = time.Now().Unix()
 		m.hbDataMutex.Unlock()
 
-		res, _, err := executeRequest(opts)
+		res, status, err := executeRequest(opts)
 		if err != nil {
-			m.pubnub.Config.Log.Println(err.Error())
 
-			if strings.Contains(err.Error(), "timeout") || strings.Contains(err.Error(), "request canceled") {
-				m.listenerManager.announceStatus(&PNStatus{
-					Category: PNTimeoutCategory,
-				})
-				m.pubnub.Config.Log.Println("continue")
-				continue
-			} else {
-
-				if strings.Contains(err.Error(), "context canceled") {
-					pnStatus := &PNStatus{
-						Category: PNCancelledCategory,
-					}
-					m.pubnub.Config.Log.Println("Status:", pnStatus)
-					m.listenerManager.announceStatus(pnStatus)
-					m.pubnub.Config.Log.Println("context canceled")
-					break
-				} else if strings.Contains(err.Error(), "Forbidden") ||
-					strings.Contains(err.Error(), "403") {
-					pnStatus := &PNStatus{
-						Category: PNAccessDeniedCategory,
-					}
-					m.pubnub.Config.Log.Println("Status:", pnStatus)
-					m.listenerManager.announceStatus(pnStatus)
-					m.unsubscribeAll()
-					break
-				} else if strings.Contains(err.Error(), "400") ||
-					strings.Contains(err.Error(), "Bad Request") {
-					pnStatus := &PNStatus{
-						Category: PNBadRequestCategory,
-					}
-					m.pubnub.Config.Log.Println("Status:", pnStatus)
-					m.listenerManager.announceStatus(pnStatus)
-					m.unsubscribeAll()
-					break
-				} else if strings.Contains(err.Error(), "530") || strings.Contains(err.Error(), "No Stub Matched") {
-					pnStatus := &PNStatus{
-						Category: PNNoStubMatchedCategory,
-					}
-					m.pubnub.Config.Log.Println("Status:", pnStatus)
-					m.listenerManager.announceStatus(pnStatus)
-					m.unsubscribeAll()
-					break
-				} else {
-					pnStatus := &PNStatus{
-						Category: PNUnknownCategory,
-					}
-					m.pubnub.Config.Log.Println("Status:", pnStatus)
-					m.listenerManager.announceStatus(pnStatus)
+			pnStatus := newSubscribeErrorStatus(status, err, combinedChannels, combinedGroups)
 
-					break
+			switch {
+			case pnerr.IsTimeout(err):
+				pnStatus.Category = PNTimeoutCategory
+				m.listenerManager.announceStatus(pnStatus)
+				m.pubnub.Config.logger().Debug("subscribe timed out, resubscribing")
+				continue
+			case pnerr.IsCancelled(err):
+				pnStatus.Category = PNCancelledCategory
+				m.pubnub.Config.logger().Debug("subscribe cancelled")
+				m.listenerManager.announceStatus(pnStatus)
+			case pnerr.IsAccessDenied(err):
+				pnStatus.Category = PNAccessDeniedCategory
+				m.connection.handle(connectionFailed, err)
+				m.pubnub.Config.logger().Error("subscribe access denied, unsubscribing", "channels", pnStatus.AffectedChannels, "groups", pnStatus.AffectedChannelGroups, "error", err)
+				m.listenerManager.announceStatus(pnStatus)
+				m.unsubscribeAll()
+			case pnerr.IsBadRequest(err):
+				pnStatus.Category = PNBadRequestCategory
+				m.connection.handle(connectionFailed, err)
+				m.pubnub.Config.logger().Error("subscribe bad request, unsubscribing", "error", err)
+				m.listenerManager.announceStatus(pnStatus)
+				m.unsubscribeAll()
+			case pnerr.StatusCode(err) == 530:
+				pnStatus.Category = PNNoStubMatchedCategory
+				m.connection.handle(connectionFailed, err)
+				m.pubnub.Config.logger().Error("subscribe stub not matched, unsubscribing", "error", err)
+				m.listenerManager.announceStatus(pnStatus)
+				m.unsubscribeAll()
+			case pnerr.IsRateLimited(err):
+				pnStatus.Category = PNRateLimitedCategory
+				delay := m.rateLimitDelay(err)
+				m.pubnub.Config.logger().Warn("subscribe rate limited, resubscribing", "delay", delay, "error", err)
+				m.listenerManager.announceStatus(pnStatus)
+				if waitForRetry(ctx, delay) {
+					continue
 				}
+				m.pubnub.Config.logger().Debug("subscribe cancelled while rate limited")
+			default:
+				pnStatus.Category = PNUnknownCategory
+				m.connectionLost(err)
+				m.pubnub.Config.logger().Error("subscribe failed", "error", err)
+				m.listenerManager.announceStatus(pnStatus)
 			}
 
+			break
 		}
 
-		m.Lock()
-		announced := m.subscriptionStateAnnounced
-
-		if announced == false {
-
+		if previous, changed := m.connection.handle(connectionReceived, nil); changed && previous == PNHandshakingState {
 			m.listenerManager.announceStatus(&PNStatus{
 				Category: PNConnectedCategory,
 			})
-			m.subscriptionStateAnnounced = true
 		}
-		m.Unlock()
 
 		var envelope subscribeEnvelope
 		err = json.Unmarshal(res, &envelope)
@@ -412,7 +466,7 @@ func (m *SubscriptionManager) startSubscribeLoop() {
 				AffectedChannels:      combinedChannels,
 				AffectedChannelGroups: combinedGroups,
 			}
-			m.pubnub.Config.Log.Println("Unmarshal: err", err, pnStatus)
+			m.pubnub.Config.logger().Error("subscribe response parsing failed", "body", string(res), "error", err)
 
 			m.listenerManager.announceStatus(pnStatus)
 		}
@@ -425,7 +479,7 @@ func (m *SubscriptionManager) startSubscribeLoop() {
 					AffectedChannelGroups: combinedGroups,
 					Category:              PNRequestMessageCountExceededCategory,
 				}
-				m.pubnub.Config.Log.Println("Status: ", pnStatus)
+				m.pubnub.Config.logger().Warn("subscribe message count exceeded", "count", messageCount, "max", m.pubnub.Config.MessageQueueOverflowCount)
 
 				m.listenerManager.announceStatus(pnStatus)
 			}
@@ -438,7 +492,9 @@ func (m *SubscriptionManager) startSubscribeLoop() {
 		if m.storedTimetoken != -1 {
 
 			m.timetoken = m.storedTimetoken
+			m.region = m.storedRegion
 			m.storedTimetoken = -1
+			m.storedRegion = 0
 		} else {
 			tt, err := strconv.ParseInt(envelope.Metadata.Timetoken, 10, 64)
 			if err != nil {
@@ -451,14 +507,13 @@ func (m *SubscriptionManager) startSubscribeLoop() {
 					AffectedChannels:      combinedChannels,
 					AffectedChannelGroups: combinedGroups,
 				}
-				m.pubnub.Config.Log.Println("ParseInt: err", err, pnStatus)
+				m.pubnub.Config.logger().Error("subscribe timetoken parsing failed", "timetoken", envelope.Metadata.Timetoken, "error", err)
 				m.listenerManager.announceStatus(pnStatus)
 			}
 
 			m.timetoken = tt
+			m.region = envelope.Metadata.Region
 		}
-
-		m.region = envelope.Metadata.Region
 		m.Unlock()
 	}
 }
@@ -476,13 +531,56 @@ type subscribeMessage struct {
 	SubscriptionMatch string        `json:"b"`
 	Channel           string        `json:"c"`
 	IssuingClientID   string        `json:"i"`
+	SequenceNumber    int64         `json:"s"`
 	SubscribeKey      string        `json:"k"`
 	Flags             int           `json:"f"`
 	Payload           interface{}   `json:"d"`
 	UserMetadata      interface{}   `json:"u"`
 	MessageType       PNMessageType `json:"e"`
 
-	PublishMetaData publishMetadata `json:"p"`
+	PublishMetaData     publishMetadata      `json:"p"`
+	OriginationMetaData *originationMetadata `json:"o"`
+
+	// rawPayload is the JSON of the Payload.
+	rawPayload json.RawMessage
+}
+
+// UnmarshalJSON decodes the message keeping the JSON of its payload.
+func (m *subscribeMessage) UnmarshalJSON(b []byte) error {
+	type message subscribeMessage
+	var raw struct {
+		message
+		Payload json.RawMessage `json:"d"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*m = subscribeMessage(raw.message)
+	if len(raw.Payload) > 0 {
+		m.rawPayload = raw.Payload
+		return json.Unmarshal(raw.Payload, &m.Payload)
+	}
+
+	return nil
+}
+
+// publishMetadata returns the metadata of the publish of the message.
+func (m subscribeMessage) publishMetadata() PNPublishMetadata {
+	metadata := PNPublishMetadata{
+		Shard:          m.Shard,
+		Flags:          m.Flags,
+		SubscribeKey:   m.SubscribeKey,
+		SequenceNumber: m.SequenceNumber,
+		Region:         m.PublishMetaData.Region,
+	}
+
+	if m.OriginationMetaData != nil {
+		metadata.OriginationTimetoken, _ = strconv.ParseInt(m.OriginationMetaData.Timetoken, 10, 64)
+		metadata.OriginationRegion = m.OriginationMetaData.Region
+	}
+
+	return metadata
 }
 
 type presenceEnvelope struct {
@@ -499,50 +597,57 @@ type publishMetadata struct {
 }
 
 type originationMetadata struct {
-	Timetoken int64 `json:"t"`
-	Region    int   `json:"r"`
+	Timetoken string `json:"t"`
+	Region    int    `json:"r"`
 }
 
 func subscribeMessageWorker(m *SubscriptionManager) {
 	m.Lock()
 	if m.ctx == nil && m.subscribeCancel == nil {
-		m.pubnub.Config.Log.Println("subscribeMessageWorker setting context")
 		m.ctx, m.subscribeCancel = contextWithCancel(backgroundContext)
-		m.pubnub.Config.Log.Println("subscribeMessageWorker after setting context")
 	}
 
-	m.pubnub.Config.Log.Println("subscribeMessageWorker")
+	m.pubnub.Config.logger().Debug("subscribe message worker starting")
 
-	m.Unlock()
+	// the previous worker is stopped, and waited for to keep the messages in order
 	if m.exitSubscriptionManager != nil {
-		m.exitSubscriptionManager <- true
-		m.pubnub.Config.Log.Println("close exitSubscriptionManager")
+		close(m.exitSubscriptionManager)
 	}
-	m.pubnub.Config.Log.Println("acquiring lock exitSubscriptionManagerMutex")
+	exit := make(chan bool)
+	m.exitSubscriptionManager = exit
+	m.Unlock()
+
 	m.exitSubscriptionManagerMutex.Lock()
-	m.pubnub.Config.Log.Println("make channel exitSubscriptionManager")
-	m.exitSubscriptionManager = make(chan bool)
-	for m.exitSubscriptionManager != nil {
-		m.pubnub.Config.Log.Println("subscribeMessageWorker looping...")
+	defer m.exitSubscriptionManagerMutex.Unlock()
+	for {
 		combinedChannels := m.stateManager.prepareChannelList(true)
 		combinedGroups := m.stateManager.prepareGroupList(true)
 
 		if len(combinedChannels) == 0 && len(combinedGroups) == 0 {
-			m.pubnub.Config.Log.Println("subscribeMessageWorker all channels unsubscribed")
-			break
+			m.pubnub.Config.logger().Debug("subscribe message worker stopped, all channels unsubscribed")
+			return
 		}
 		select {
-		case <-m.exitSubscriptionManager:
-			m.pubnub.Config.Log.Println("subscribeMessageWorker context done")
-			m.exitSubscriptionManager = nil
-			break
+		case <-exit:
+			m.pubnub.Config.logger().Debug("subscribe message worker stopped")
+			return
+		case <-m.pubnub.ctx.Done():
+			m.pubnub.Config.logger().Debug("subscribe message worker stopped by the PubNub context")
+			return
 		case message := <-m.messages:
-			m.pubnub.Config.Log.Println("subscribeMessageWorker messages")
 			processSubscribePayload(m, message)
 		}
 	}
-	m.pubnub.Config.Log.Println("subscribeMessageWorker after for")
-	m.exitSubscriptionManagerMutex.Unlock()
+}
+
+// stopMessageWorker stops the running subscribeMessageWorker, if any.
+func (m *SubscriptionManager) stopMessageWorker() {
+	m.Lock()
+	if m.exitSubscriptionManager != nil {
+		close(m.exitSubscriptionManager)
+		m.exitSubscriptionManager = nil
+	}
+	m.Unlock()
 }
 
 func processSubscribePayload(m *SubscriptionManager, payload subscribeMessage) {
@@ -574,9 +679,14 @@ func processSubscribePayload(m *SubscriptionManager, payload subscribeMessage) {
 
 		action, _ = presencePayload["action"].(string)
 		uuid, _ = presencePayload["uuid"].(string)
-		occupancy, _ = presencePayload["occupancy"].(int)
+		// the numbers are decoded as float64, int is kept for the payloads built by the tests
+		switch o := presencePayload["occupancy"].(type) {
+		case int:
+			occupancy = o
+		case float64:
+			occupancy = int(o)
+		}
 		if presencePayload["timestamp"] != nil {
-			m.pubnub.Config.Log.Println("presencePayload['timestamp'] type", reflect.TypeOf(presencePayload["timestamp"]).Kind())
 			switch presencePayload["timestamp"].(type) {
 			case int:
 				timestamp = int64(presencePayload["timestamp"].(int))
@@ -592,9 +702,7 @@ func processSubscribePayload(m *SubscriptionManager, payload subscribeMessage) {
 		}
 
 		data = presencePayload["data"]
-		if presencePayload["here_now_refresh"] != nil {
-			hereNowRefresh = presencePayload["here_now_refresh"].(bool)
-		}
+		hereNowRefresh, _ = presencePayload["here_now_refresh"].(bool)
 		timetoken, _ := strconv.ParseInt(publishMetadata.PublishTimetoken, 10, 64)
 
 		strippedPresenceChannel := ""
@@ -618,19 +726,35 @@ func processSubscribePayload(m *SubscriptionManager, payload subscribeMessage) {
 			SubscribedChannel: subscribedChannel,
 			Channel:           strippedPresenceChannel,
 			Subscription:      strippedPresenceSubscription,
-			State:             data,
+			State:             decryptState(m.pubnub.Config, data),
 			Timetoken:         timetoken,
 			Occupancy:         occupancy,
 			UUID:              uuid,
 			Timestamp:         timestamp,
+			Join:              presenceUUIDs(presencePayload["join"]),
+			Leave:             presenceUUIDs(presencePayload["leave"]),
+			Timeout:           presenceUUIDs(presencePayload["timeout"]),
 			HereNowRefresh:    hereNowRefresh,
+			PublishMetadata:   payload.publishMetadata(),
+			Raw:               payload.rawPayload,
 		}
 		m.listenerManager.announcePresence(pnPresenceResult)
+
+		if hereNowRefresh && m.pubnub.Config.RefreshHereNowOnInterval {
+			m.pubnub.routines.spawn(func() {
+				m.refreshHereNow(pnPresenceResult)
+			})
+		}
 	} else {
 		actualCh := ""
 		subscribedCh := channel
 		timetoken, _ := strconv.ParseInt(publishMetadata.PublishTimetoken, 10, 64)
 
+		if m.dedupCache.seen(messageDedupKey(channel, timetoken, payload.IssuingClientID, payload.UserMetadata)) {
+			m.pubnub.Config.logger().Debug("dropping duplicate message", "channel", channel, "timetoken", timetoken)
+			return
+		}
+
 		if subscriptionMatch != "" {
 			actualCh = channel
 			subscribedCh = subscriptionMatch
@@ -640,25 +764,30 @@ func processSubscribePayload(m *SubscriptionManager, payload subscribeMessage) {
 		switch payload.MessageType {
 		case PNMessageTypeSignal:
 			pnMessageResult := createPNMessageResult(payload.Payload, actualCh, subscribedCh, channel, subscriptionMatch, payload.IssuingClientID, payload.UserMetadata, timetoken)
-			m.pubnub.Config.Log.Println("announceSignal,", pnMessageResult)
+			pnMessageResult.PublishMetadata = payload.publishMetadata()
+			pnMessageResult.Raw = payload.rawPayload
+			m.pubnub.Config.logger().Debug("announcing signal", "channel", channel, "timetoken", timetoken)
 			m.listenerManager.announceSignal(pnMessageResult)
 		case PNMessageTypeObjects:
 			pnUserEvent, pnSpaceEvent, pnMembershipEvent, eventType := createPNObjectsResult(payload.Payload, m, actualCh, subscribedCh, channel, subscriptionMatch)
-			m.pubnub.Config.Log.Println("announceObjects,", pnUserEvent, pnSpaceEvent, pnMembershipEvent, eventType)
+			m.pubnub.Config.logger().Debug("announcing objects event", "channel", channel, "type", eventType)
 			//go func() {
 			switch eventType {
 			case PNObjectsUserEvent:
-				m.pubnub.Config.Log.Println("pnUserEvent:", pnUserEvent)
 				m.listenerManager.announceUserEvent(pnUserEvent)
 			case PNObjectsSpaceEvent:
-				m.pubnub.Config.Log.Println("pnSpaceEvent:", pnSpaceEvent)
 				m.listenerManager.announceSpaceEvent(pnSpaceEvent)
 			case PNObjectsMembershipEvent:
-				m.pubnub.Config.Log.Println("pnMembershipEvent:", pnMembershipEvent)
 				m.listenerManager.announceMembershipEvent(pnMembershipEvent)
 			}
 			//}()
-
+		case PNMessageTypeActions:
+			pnMessageActionsEvent := createPNMessageActionsEventResult(payload.Payload, m, actualCh, subscribedCh, channel, subscriptionMatch, payload.IssuingClientID)
+			if pnMessageActionsEvent == nil {
+				return
+			}
+			m.pubnub.Config.logger().Debug("announcing message actions event", "channel", channel)
+			m.listenerManager.announceMessageActionsEvent(pnMessageActionsEvent)
 		default:
 			var err error
 			messagePayload, err = parseCipherInterface(payload.Payload, m.pubnub.Config)
@@ -670,12 +799,21 @@ func processSubscribePayload(m *SubscriptionManager, payload subscribeMessage) {
 					Operation:        PNSubscribeOperation,
 					AffectedChannels: []string{channel},
 				}
-				m.pubnub.Config.Log.Println("DecryptString: err", err, pnStatus)
+				m.pubnub.Config.logger().Error("message decryption failed", "channel", channel, "error", err)
 				m.listenerManager.announceStatus(pnStatus)
 
 			}
 			pnMessageResult := createPNMessageResult(messagePayload, actualCh, subscribedCh, channel, subscriptionMatch, payload.IssuingClientID, payload.UserMetadata, timetoken)
-			m.pubnub.Config.Log.Println("announceMessage,", pnMessageResult)
+			pnMessageResult.PublishMetadata = payload.publishMetadata()
+			pnMessageResult.Raw = payload.rawPayload
+			if m.pubnub.Config.cryptoModule("") != nil && err == nil {
+				// the received JSON is encrypted, the decrypted message is encoded again
+				pnMessageResult.Raw, _ = json.Marshal(messagePayload)
+			}
+			if !m.catchUp.live(pnMessageResult) {
+				return
+			}
+			m.pubnub.Config.logger().Debug("announcing message", "channel", channel, "timetoken", timetoken)
 			m.listenerManager.announceMessage(pnMessageResult)
 		}
 
@@ -707,10 +845,89 @@ func processSubscribePayload(m *SubscriptionManager, payload subscribeMessage) {
 		// 	m.pubnub.Config.Log.Println("announceMessage,", pnMessageResult)
 		// 	m.listenerManager.announceMessage(pnMessageResult)
 		// }
-		m.pubnub.Config.Log.Println("after announceMessage")
 	}
 }
 
+// messageDedupKey returns the key of a message in the dedup cache.
+func messageDedupKey(channel string, timetoken int64, issuer string, meta interface{}) dedupKey {
+	if dedupe := dedupeKey(meta); dedupe != "" {
+		return dedupKey{channel: channel, issuer: issuer, dedupe: dedupe}
+	}
+
+	return dedupKey{channel: channel, timetoken: timetoken, issuer: issuer}
+}
+
+// dedupeKey returns the pn_dedupe_key of the Meta of a message published with a DedupeKey.
+func dedupeKey(meta interface{}) string {
+	m, ok := meta.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	key, _ := m[dedupeKeyMetaField].(string)
+
+	return key
+}
+
+// presenceUUIDs returns the UUIDs of the join, leave or timeout deltas of an interval presence event.
+func presenceUUIDs(v interface{}) []string {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	uuids := make([]string, 0, len(items))
+	for _, item := range items {
+		if uuid, ok := item.(string); ok {
+			uuids = append(uuids, uuid)
+		}
+	}
+
+	return uuids
+}
+
+// refreshHereNow calls HereNow for the channel of the interval presence event, whose deltas
+// were not sent as there were too many of them, and announces the occupants in a PNPresence
+// with the PNHereNowEvent event.
+func (m *SubscriptionManager) refreshHereNow(interval *PNPresence) {
+	res, status, err := m.pubnub.HereNowWithContext(m.pubnub.ctx).
+		Channels([]string{interval.Channel}).
+		IncludeUUIDs(true).
+		IncludeState(true).
+		Execute()
+	if err != nil {
+		m.pubnub.Config.logger().Error("here now refresh failed", "channel", interval.Channel, "error", err)
+		m.listenerManager.announceStatus(&PNStatus{
+			Category:         status.Category,
+			Operation:        PNHereNowOperation,
+			StatusCode:       status.StatusCode,
+			Error:            true,
+			ErrorData:        err,
+			AffectedChannels: []string{interval.Channel},
+		})
+		return
+	}
+
+	refresh := &PNPresence{
+		Event:             PNHereNowEvent,
+		ActualChannel:     interval.ActualChannel,
+		SubscribedChannel: interval.SubscribedChannel,
+		Channel:           interval.Channel,
+		Subscription:      interval.Subscription,
+		Timetoken:         interval.Timetoken,
+		Timestamp:         interval.Timestamp,
+		Occupants:         []HereNowOccupantsData{},
+	}
+	for _, ch := range res.Channels {
+		if ch.ChannelName == interval.Channel {
+			refresh.Occupancy = ch.Occupancy
+			refresh.Occupants = ch.Occupants
+		}
+	}
+
+	m.pubnub.Config.logger().Debug("announcing here now refresh", "channel", interval.Channel, "occupancy", refresh.Occupancy)
+	m.listenerManager.announcePresence(refresh)
+}
+
 func createPNObjectsResult(objPayload interface{}, m *SubscriptionManager, actualCh, subscribedCh, channel, subscriptionMatch string) (*PNUserEvent, *PNSpaceEvent, *PNMembershipEvent, PNObjectsEventType) {
 	var objectsPayload map[string]interface{}
 	var ok bool
@@ -839,6 +1056,44 @@ func createPNObjectsResult(objPayload interface{}, m *SubscriptionManager, actua
 	return pnUserEvent, pnSpaceEvent, pnMembershipEvent, eventType
 }
 
+func createPNMessageActionsEventResult(actionsPayload interface{}, m *SubscriptionManager, actualCh, subscribedCh, channel, subscriptionMatch, issuingClientID string) *PNMessageActionsEvent {
+	var eventPayload, data map[string]interface{}
+	var ok bool
+	if eventPayload, ok = actionsPayload.(map[string]interface{}); !ok {
+		m.listenerManager.announceStatus(&PNStatus{
+			Category:         PNUnknownCategory,
+			ErrorData:        errors.New("Message Actions response parsing error"),
+			Error:            true,
+			Operation:        PNSubscribeOperation,
+			AffectedChannels: []string{channel},
+		})
+		return nil
+	}
+
+	event, _ := eventPayload["event"].(string)
+	data, _ = eventPayload["data"].(map[string]interface{})
+
+	actionType, _ := data["type"].(string)
+	actionValue, _ := data["value"].(string)
+	actionTimetoken, _ := data["actionTimetoken"].(string)
+	messageTimetoken, _ := data["messageTimetoken"].(string)
+
+	return &PNMessageActionsEvent{
+		Event: PNMessageActionsEventType(event),
+		Data: PNMessageActionsResponse{
+			ActionType:       actionType,
+			ActionValue:      actionValue,
+			ActionTimetoken:  actionTimetoken,
+			MessageTimetoken: messageTimetoken,
+			UUID:             issuingClientID,
+		},
+		ActualChannel:     actualCh,
+		SubscribedChannel: subscribedCh,
+		Channel:           channel,
+		Subscription:      subscriptionMatch,
+	}
+}
+
 func createPNMessageResult(messagePayload interface{}, actualCh, subscribedCh, channel, subscriptionMatch, issuingClientID string, userMetadata interface{}, timetoken int64) *PNMessage {
 
 	pnMessageResult := &PNMessage{
@@ -856,17 +1111,42 @@ func createPNMessageResult(messagePayload interface{}, actualCh, subscribedCh, c
 
 }
 
-// parseCipherInterface handles the decryption in case a cipher key is used
+// newSubscribeErrorStatus creates the status announced when the subscribe request fails.
+// The affected channels and groups are the ones reported by the server, for ex. the channels
+// which are not granted, or all the subscribed ones otherwise.
+func newSubscribeErrorStatus(status StatusResponse, err error, channels, groups []string) *PNStatus {
+	pnStatus := &PNStatus{
+		Category:              status.Category,
+		Operation:             PNSubscribeOperation,
+		ErrorData:             err,
+		Error:                 true,
+		StatusCode:            status.StatusCode,
+		TLSEnabled:            status.TLSEnabled,
+		UUID:                  status.UUID,
+		AuthKey:               status.AuthKey,
+		Origin:                status.Origin,
+		AffectedChannels:      status.AffectedChannels,
+		AffectedChannelGroups: status.AffectedChannelGroups,
+	}
+
+	if len(pnStatus.AffectedChannels) == 0 && len(pnStatus.AffectedChannelGroups) == 0 {
+		pnStatus.AffectedChannels = channels
+		pnStatus.AffectedChannelGroups = groups
+	}
+
+	return pnStatus
+}
+
+// parseCipherInterface handles the decryption in case a cipher key or a cryptor is used
 // in case of error it returns data as is.
 //
 // parameters
 // data: the data to decrypt as interface.
-// cipherKey: cipher key to use to decrypt.
+// pnConf: config holding the cipher key or the cryptors to use to decrypt.
 //
 // returns the decrypted data as interface and error.
 func parseCipherInterface(data interface{}, pnConf *Config) (interface{}, error) {
-	if pnConf.CipherKey != "" {
-		pnConf.Log.Println("reflect.TypeOf(data).Kind()", reflect.TypeOf(data).Kind(), data)
+	if module := pnConf.cryptoModule(""); module != nil {
 		switch v := data.(type) {
 		case map[string]interface{}:
 
@@ -874,51 +1154,47 @@ func parseCipherInterface(data interface{}, pnConf *Config) (interface{}, error)
 				//decrypt pn_other only
 				msg, ok := v["pn_other"].(string)
 				if ok {
-					pnConf.Log.Println("v[pn_other]", v["pn_other"], v, msg)
-					decrypted, errDecryption := utils.DecryptString(pnConf.CipherKey, msg)
+					pnConf.logger().Debug("decrypting pn_other only")
+					decrypted, errDecryption := module.DecryptString(msg)
 					if errDecryption != nil {
-						pnConf.Log.Println(errDecryption, msg)
+						pnConf.logger().Error("message decryption failed", "error", errDecryption)
 						return v, errDecryption
 					} else {
 						var intf interface{}
-						err := json.Unmarshal([]byte(decrypted.(string)), &intf)
+						err := json.Unmarshal([]byte(decrypted), &intf)
 						if err != nil {
-							pnConf.Log.Println("Unmarshal: err", err)
+							pnConf.logger().Error("decrypted message parsing failed", "error", err)
 							return intf, err
 						}
 						v["pn_other"] = intf
 
-						pnConf.Log.Println("reflect.TypeOf(v).Kind()", reflect.TypeOf(v).Kind(), v)
 						return v, nil
 					}
 				}
 				return v, nil
 			}
-			pnConf.Log.Println("return as is reflect.TypeOf(v).Kind()", reflect.TypeOf(v).Kind(), v)
 			return v, nil
 		case string:
 			var intf interface{}
-			decrypted, errDecryption := utils.DecryptString(pnConf.CipherKey, data.(string))
+			decrypted, errDecryption := module.DecryptString(v)
 			if errDecryption != nil {
-				pnConf.Log.Println(errDecryption, intf)
+				pnConf.logger().Error("message decryption failed", "error", errDecryption)
 				intf = data
 				return intf, errDecryption
 			}
-			pnConf.Log.Println("reflect.TypeOf(intf).Kind()", reflect.TypeOf(decrypted).Kind(), decrypted)
 
-			err := json.Unmarshal([]byte(decrypted.(string)), &intf)
+			err := json.Unmarshal([]byte(decrypted), &intf)
 			if err != nil {
-				pnConf.Log.Println("Unmarshal: err", err)
+				pnConf.logger().Error("decrypted message parsing failed", "error", err)
 				return intf, err
 			}
 
 			return intf, nil
 		default:
-			pnConf.Log.Println("returning as is", reflect.TypeOf(v).Kind())
+			pnConf.logger().Debug("message not encrypted, returned as is", "kind", reflect.TypeOf(v).Kind())
 			return v, nil
 		}
 	} else {
-		pnConf.Log.Println("No Cipher, returning as is ", data)
 		return data, nil
 	}
 }
@@ -928,9 +1204,7 @@ func (m *SubscriptionManager) AddListener(listener *Listener) {
 }
 
 func (m *SubscriptionManager) RemoveListener(listener *Listener) {
-	m.listenerManager.Lock()
 	m.listenerManager.removeListener(listener)
-	m.listenerManager.Unlock()
 }
 
 func (m *SubscriptionManager) RemoveAllListeners() {
@@ -938,33 +1212,39 @@ func (m *SubscriptionManager) RemoveAllListeners() {
 }
 
 func (m *SubscriptionManager) GetListeners() map[*Listener]bool {
-	listn := m.listenerManager.listeners
-	return listn
+	return m.listenerManager.getListeners()
 }
 
 func (m *SubscriptionManager) reconnect() {
-	m.pubnub.Config.Log.Println("reconnect")
-	m.reconnectionManager.stopHeartbeatTimer()
-	m.pubnub.Config.Log.Println("after stopHeartbeatTimer")
+	m.pubnub.Config.logger().Debug("reconnecting subscribe loop")
 	m.stopSubscribeLoop()
 
 	combinedChannels := m.stateManager.prepareChannelList(true)
 	combinedGroups := m.stateManager.prepareGroupList(true)
 
+	m.Lock()
+	m.disconnected = false
+	m.Unlock()
+
 	if len(combinedChannels) == 0 && len(combinedGroups) == 0 {
-		m.pubnub.Config.Log.Println("All channels or channel groups unsubscribed.")
+		m.pubnub.Config.logger().Debug("all channels and channel groups unsubscribed")
 	} else {
-		go m.startSubscribeLoop()
-		go m.pubnub.heartbeatManager.startHeartbeatTimer(false)
+		// the context is created here for the new loop to be cancelled by the next reconnect
+		m.Lock()
+		m.ctx, m.subscribeCancel = contextWithCancel(backgroundContext)
+		m.Unlock()
+
+		m.pubnub.routines.spawn(m.startSubscribeLoop)
+		m.pubnub.routines.spawn(func() {
+			m.pubnub.heartbeatManager.startHeartbeatTimer(false)
+		})
 	}
 }
 
 func (m *SubscriptionManager) Disconnect() {
-	m.pubnub.Config.Log.Println("disconnect")
+	m.pubnub.Config.logger().Debug("disconnecting subscribe loop")
 
-	if m.exitSubscriptionManager != nil {
-		m.exitSubscriptionManager <- true
-	}
+	m.stopMessageWorker()
 	m.reconnectionManager.stopHeartbeatTimer()
 
 	m.pubnub.heartbeatManager.stopHeartbeat(false, false)
@@ -973,15 +1253,120 @@ func (m *SubscriptionManager) Disconnect() {
 
 }
 
+// rateLimitDelay returns the delay before resubscribing after the rate limited err: the Retry-After
+// of the response, or the first delay of the ReconnectionBackoff.
+func (m *SubscriptionManager) rateLimitDelay(err error) time.Duration {
+	if se := pnerr.AsServerError(err); se != nil {
+		if d, ok := retryAfterHeader(se.Header); ok {
+			return d
+		}
+	}
+
+	return m.pubnub.Config.ReconnectionBackoff.delay(m.pubnub.Config.PNReconnectionPolicy, 1)
+}
+
+// loopStopped stops the heartbeats of the subscribed channels and the connectivity probes once the
+// ConnectionState says the subscribe loop stopped, they are started again with the loop by reconnect.
+func (m *SubscriptionManager) loopStopped() {
+	m.reconnectionManager.stopHeartbeatTimer()
+	if m.pubnub.heartbeatManager != nil {
+		m.pubnub.heartbeatManager.stopSubscribeHeartbeat()
+	}
+}
+
+// connectionLost moves to PNReconnectingState after the subscribe request failed, or to PNStoppedState
+// when the loop won't be restarted without a reconnection policy.
+func (m *SubscriptionManager) connectionLost(err error) {
+	if m.pubnub.Config.PNReconnectionPolicy == PNNonePolicy {
+		m.connection.handle(connectionStop, err)
+	} else {
+		m.connection.handle(connectionLost, err)
+	}
+}
+
 func (m *SubscriptionManager) stopSubscribeLoop() {
 	m.log("loop stop")
 
+	m.Lock()
 	if m.ctx != nil && m.subscribeCancel != nil {
 		m.subscribeCancel()
 		m.ctx = nil
 		m.subscribeCancel = nil
 	}
+	m.Unlock()
+
+}
+
+func (m *SubscriptionManager) isDisconnected() bool {
+	m.RLock()
+	defer m.RUnlock()
+
+	return m.disconnected
+}
+
+// pause stops the subscribe loop, the heartbeats and the reconnections, the subscriptions and
+// the timetoken are kept for resume.
+func (m *SubscriptionManager) pause() {
+	m.Lock()
+	m.disconnected = true
+	m.Unlock()
+
+	m.pubnub.Config.logger().Info("subscribe loop disconnected by the app")
+	m.connection.handle(connectionStop, nil)
+	m.reconnectionManager.stopHeartbeatTimer()
+	m.pubnub.heartbeatManager.stopHeartbeat(false, true)
+	m.stopSubscribeLoop()
+
+	m.listenerManager.announceStatus(&PNStatus{
+		Category:              PNDisconnectedCategory,
+		AffectedChannels:      m.stateManager.prepareChannelList(true),
+		AffectedChannelGroups: m.stateManager.prepareGroupList(true),
+	})
+}
+
+// resume restarts the subscribe loop from its timetoken. While reconnecting, the connectivity
+// is probed right away instead, the loop is restarted by the reconnection.
+func (m *SubscriptionManager) resume() {
+	if m.stateManager.isEmpty() {
+		m.pubnub.Config.logger().Debug("nothing to reconnect, no channels subscribed")
+		return
+	}
+
+	switch m.connection.current() {
+	case PNReconnectingState:
+		m.pubnub.Config.logger().Info("probing the connectivity to reconnect")
+		m.reconnectionManager.probeImmediately()
+		return
+	case PNStoppedState, PNFailedState:
+		m.connection.handle(connectionSubscribe, nil)
+	}
+
+	m.pubnub.Config.logger().Info("subscribe loop reconnecting", "timetoken", m.currentTimetoken())
+	m.reconnect()
+}
+
+// networkChanged aborts the pending subscribe request to send it again on the new network, or
+// probes the connectivity while reconnecting. Nothing is done while disconnected.
+func (m *SubscriptionManager) networkChanged() {
+	if m.isDisconnected() || m.stateManager.isEmpty() {
+		return
+	}
+
+	switch m.connection.current() {
+	case PNReconnectingState:
+		m.pubnub.Config.logger().Info("network changed, probing the connectivity")
+		m.reconnectionManager.probeImmediately()
+	case PNHandshakingState, PNReceivingState:
+		m.pubnub.Config.logger().Info("network changed, restarting the subscribe request")
+		m.reconnect()
+	}
+}
+
+func (m *SubscriptionManager) currentTimetoken() int64 {
+	m.RLock()
+	defer m.RUnlock()
 
+	return m.timetoken
 }
 
 func (m *SubscriptionManager) getSubscribedChannels() []string {
@@ -992,17 +1377,16 @@ func (m *SubscriptionManager) getSubscribedGroups() []string {
 	return m.stateManager.prepareGroupList(false)
 }
 
-func (m *SubscriptionManager) unsubscribeAll() {
-	m.adaptUnsubscribe(&UnsubscribeOperation{
+func (m *SubscriptionManager) unsubscribeAll() <-chan error {
+	return m.adaptUnsubscribe(&UnsubscribeOperation{
 		Channels:      m.stateManager.prepareChannelList(true),
 		ChannelGroups: m.stateManager.prepareGroupList(true),
 	})
 }
 
 func (m *SubscriptionManager) log(message string) {
-	m.pubnub.Config.Log.Printf("pubnub: subscribe: %s: %s: %s/%s\n",
-		message,
-		m.pubnub.Config.UUID,
-		m.stateManager.prepareChannelList(true),
-		m.stateManager.prepareGroupList(true))
+	m.pubnub.Config.logger().Debug("subscribe: "+message,
+		"uuid", m.pubnub.Config.UUID,
+		"channels", m.stateManager.prepareChannelList(true),
+		"groups", m.stateManager.prepareGroupList(true))
 }
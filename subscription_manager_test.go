@@ -1,10 +1,22 @@
 package pubnub
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/sprucehealth/pubnub-go/pnerr"
+	"github.com/sprucehealth/pubnub-go/pubnubtest"
+	"github.com/sprucehealth/pubnub-go/tests/stubs"
+	"github.com/sprucehealth/pubnub-go/utils"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type customStruct struct {
@@ -12,6 +24,54 @@ type customStruct struct {
 	Bar []int
 }
 
+// testXORCryptor is a custom Cryptor registered on the Config in the tests.
+type testXORCryptor struct{}
+
+func (c testXORCryptor) ID() string {
+	return "TXOR"
+}
+
+func (c testXORCryptor) Encrypt(data []byte) (*utils.EncryptedData, error) {
+	return &utils.EncryptedData{Data: c.xor(data)}, nil
+}
+
+func (c testXORCryptor) Decrypt(data *utils.EncryptedData) ([]byte, error) {
+	return c.xor(data.Data), nil
+}
+
+func (c testXORCryptor) xor(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[i] = b ^ 0x5a
+	}
+	return out
+}
+
+func TestNewSubscribeErrorStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	err := pnerr.NewServerError(403, ioutil.NopCloser(bytes.NewBufferString(
+		`{"message":"Forbidden","payload":{"channels":["ch2"]},"error":true,"service":"Access Manager","status":403}`)))
+	status := createStatus(PNAccessDeniedCategory, "", ResponseInfo{StatusCode: 403}, err)
+
+	pnStatus := newSubscribeErrorStatus(status, err, []string{"ch1", "ch2"}, []string{"cg1"})
+	assert.Equal(PNAccessDeniedCategory, pnStatus.Category)
+	assert.Equal(PNSubscribeOperation, pnStatus.Operation)
+	assert.Equal(403, pnStatus.StatusCode)
+	assert.True(pnStatus.Error)
+	assert.Equal(err, pnStatus.ErrorData)
+	assert.Equal([]string{"ch2"}, pnStatus.AffectedChannels)
+	assert.Empty(pnStatus.AffectedChannelGroups)
+
+	// the subscribed channels are affected when the server does not report them
+	err = pnerr.NewServerError(500, ioutil.NopCloser(bytes.NewBufferString("Internal Server Error")))
+	status = createStatus(PNUnknownCategory, "", ResponseInfo{StatusCode: 500}, err)
+
+	pnStatus = newSubscribeErrorStatus(status, err, []string{"ch1", "ch2"}, []string{"cg1"})
+	assert.Equal([]string{"ch1", "ch2"}, pnStatus.AffectedChannels)
+	assert.Equal([]string{"cg1"}, pnStatus.AffectedChannelGroups)
+}
+
 func TestParseCipherInterfaceCipherWithCipher(t *testing.T) {
 	assert := assert.New(t)
 	s := "Wi24KS4pcTzvyuGOHubiXg=="
@@ -25,6 +85,39 @@ func TestParseCipherInterfaceCipherWithCipher(t *testing.T) {
 	assert.Equal("yay!", intf.(string))
 }
 
+func TestParseCipherInterfaceRandomIVCipherWithCipher(t *testing.T) {
+	assert := assert.New(t)
+	s, err := utils.EncryptStringWithRandomIV("enigma", `"yay!"`)
+	assert.Nil(err)
+
+	pn := NewPubNub(NewDemoConfig())
+	pn.Config.CipherKey = "enigma"
+
+	intf, err := parseCipherInterface(s, pn.Config)
+
+	assert.Nil(err)
+	assert.Equal("yay!", intf.(string))
+}
+
+func TestParseCipherInterfaceCryptor(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	pn.Config.Cryptor = testXORCryptor{}
+	pn.Config.Decryptors = []utils.Cryptor{utils.NewLegacyCryptor("enigma")}
+
+	s, err := utils.NewCryptoModule(testXORCryptor{}).EncryptString(`"yay!"`)
+	assert.Nil(err)
+
+	intf, err := parseCipherInterface(s, pn.Config)
+	assert.Nil(err)
+	assert.Equal("yay!", intf.(string))
+
+	intf, err = parseCipherInterface("Wi24KS4pcTzvyuGOHubiXg==", pn.Config)
+	assert.Nil(err)
+	assert.Equal("yay!", intf.(string))
+}
+
 func TestParseCipherInterfacePlainWithCipher(t *testing.T) {
 	assert := assert.New(t)
 	s := "yay!"
@@ -559,3 +652,483 @@ func TestProcessSubscribePayloadCipherErr(t *testing.T) {
 	<-done
 	//pn.Destroy()
 }
+
+func TestProcessSubscribePayloadMessageActions(t *testing.T) {
+	assert := assert.New(t)
+	done := make(chan bool)
+	pn := NewPubNub(NewDemoConfig())
+	listener := NewListener()
+
+	go func() {
+		for {
+			select {
+			case status := <-listener.Status:
+				assert.Fail("Unexpected status", status)
+				done <- true
+				break
+			case _ = <-listener.Message:
+				assert.Fail("Unexpected message")
+				done <- true
+				break
+			case event := <-listener.MessageAction:
+				assert.Equal(PNMessageActionsAdded, event.Event)
+				assert.Equal("ch", event.Channel)
+				assert.Equal("reaction", event.Data.ActionType)
+				assert.Equal("smiley_face", event.Data.ActionValue)
+				assert.Equal("15698453605345560", event.Data.ActionTimetoken)
+				assert.Equal("15698453474046033", event.Data.MessageTimetoken)
+				assert.Equal("pn-871b8325-a11f-48cb-9c15-64984790703e", event.Data.UUID)
+				done <- true
+				break
+			}
+		}
+	}()
+
+	pn.AddListener(listener)
+
+	payload := map[string]interface{}{
+		"source":  "actions",
+		"version": "1.0",
+		"event":   "added",
+		"data": map[string]interface{}{
+			"messageTimetoken": "15698453474046033",
+			"type":             "reaction",
+			"value":            "smiley_face",
+			"actionTimetoken":  "15698453605345560",
+		},
+	}
+
+	sm := &subscribeMessage{
+		Shard:           "1",
+		Channel:         "ch",
+		IssuingClientID: "pn-871b8325-a11f-48cb-9c15-64984790703e",
+		MessageType:     PNMessageTypeActions,
+		Payload:         payload,
+	}
+
+	processSubscribePayload(pn.subscriptionManager, *sm)
+	<-done
+}
+
+func TestProcessSubscribePayloadInvalidMessageActions(t *testing.T) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+	listener := NewListenerWithQueue(10, PNOverflowBlock)
+	pn.AddListener(listener)
+
+	sm := subscribeMessage{
+		Shard:       "1",
+		Channel:     "ch",
+		MessageType: PNMessageTypeActions,
+		Payload:     "not an event",
+	}
+	processSubscribePayload(pn.subscriptionManager, sm)
+
+	status := <-listener.Status
+	assert.True(status.Error)
+	assert.Equal([]string{"ch"}, status.AffectedChannels)
+
+	// the action event isn't announced
+	processSubscribePayload(pn.subscriptionManager, subscribeMessage{Shard: "1", Channel: "ch", Payload: "next", PublishMetaData: publishMetadata{PublishTimetoken: "1"}})
+	select {
+	case event := <-listener.MessageAction:
+		assert.Fail("Unexpected message action", event)
+	case message := <-listener.Message:
+		assert.Equal("next", message.Message)
+	}
+}
+
+// longPollTransport records the tt and tr params of the subscribe requests, and holds the
+// requests matching no stub like a long-poll without messages, until they are cancelled.
+type longPollTransport struct {
+	sync.Mutex
+	interceptor *stubs.Interceptor
+	requests    []string
+}
+
+func (t *longPollTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	q := req.URL.Query()
+	t.Lock()
+	t.requests = append(t.requests, fmt.Sprintf("tt=%s tr=%s", q.Get("tt"), q.Get("tr")))
+	t.Unlock()
+
+	res, err := t.interceptor.Transport.RoundTrip(req)
+	if err == nil && res.StatusCode == 530 {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}
+	return res, err
+}
+
+func (t *longPollTransport) sent(request string) bool {
+	return t.count(request) > 0
+}
+
+func (t *longPollTransport) count(request string) int {
+	t.Lock()
+	defer t.Unlock()
+
+	n := 0
+	for _, r := range t.requests {
+		if r == request {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSubscribeKeepsRegion(t *testing.T) {
+	assert := assert.New(t)
+
+	interceptor := stubs.NewInterceptor()
+	addStub := func(channels, query, body string) {
+		interceptor.AddStub(&stubs.Stub{
+			Method:             "GET",
+			Path:               fmt.Sprintf("/v2/subscribe/sub/%s/0", channels),
+			Query:              query,
+			ResponseBody:       body,
+			MixedPathPositions: []int{4},
+			IgnoreQueryKeys:    []string{"pnsdk", "uuid", "heartbeat"},
+			ResponseStatusCode: 200,
+		})
+	}
+	addStub("ch", "", `{"t":{"t":"100","r":12},"m":[]}`)
+	addStub("ch", "tt=100&tr=12", `{"t":{"t":"200","r":12},"m":[{"a":"1","c":"ch","d":"first","p":{"t":"150","r":12}}]}`)
+	// the handshake of the new channel mix is served by another region, the stored timetoken
+	// must still be sent with its own region
+	addStub("ch,ch2", "", `{"t":{"t":"300","r":4},"m":[]}`)
+	addStub("ch,ch2", "tt=200&tr=12", `{"t":{"t":"400","r":12},"m":[{"a":"1","c":"ch2","d":"second","p":{"t":"350","r":12}}]}`)
+
+	transport := &longPollTransport{interceptor: interceptor}
+
+	config := NewConfig()
+	config.PublishKey = "pub"
+	config.SubscribeKey = "sub"
+	config.SuppressLeaveEvents = true
+
+	pn := NewPubNub(config)
+	defer pn.Destroy()
+	pn.SetSubscribeClient(&http.Client{Transport: transport})
+
+	listener := NewListener()
+	pn.AddListener(listener)
+
+	pn.Subscribe().Channels([]string{"ch"}).Execute()
+	assert.Equal("first", waitForSubscriptionMessage(t, listener).Message)
+	waitUntil(t, func() bool {
+		return transport.sent("tt=200 tr=12")
+	})
+
+	pn.Subscribe().Channels([]string{"ch2"}).Execute()
+	assert.Equal("second", waitForSubscriptionMessage(t, listener).Message)
+	waitUntil(t, func() bool {
+		return transport.sent("tt=400 tr=12")
+	})
+
+	assert.False(transport.sent("tt= tr=12"))
+	assert.False(transport.sent("tt=300 tr=4"))
+
+	pn.UnsubscribeAll()
+	waitUntil(t, func() bool {
+		return len(pn.GetSubscribedChannels()) == 0
+	})
+	pn.subscriptionManager.Lock()
+	assert.Equal(int64(0), pn.subscriptionManager.timetoken)
+	assert.Equal(int64(-1), pn.subscriptionManager.storedTimetoken)
+	assert.Equal(int8(0), pn.subscriptionManager.region)
+	pn.subscriptionManager.Unlock()
+}
+
+func TestDisconnectReconnect(t *testing.T) {
+	assert := assert.New(t)
+
+	interceptor := stubs.NewInterceptor()
+	for _, stub := range []struct{ query, body string }{
+		{"", `{"t":{"t":"100","r":12},"m":[]}`},
+		{"tt=100&tr=12", `{"t":{"t":"200","r":12},"m":[{"a":"1","c":"ch","d":"first","p":{"t":"150","r":12}}]}`},
+	} {
+		interceptor.AddStub(&stubs.Stub{
+			Method:             "GET",
+			Path:               "/v2/subscribe/sub/ch/0",
+			Query:              stub.query,
+			ResponseBody:       stub.body,
+			IgnoreQueryKeys:    []string{"pnsdk", "uuid", "heartbeat"},
+			ResponseStatusCode: 200,
+		})
+	}
+	transport := &longPollTransport{interceptor: interceptor}
+
+	config := NewConfig()
+	config.PublishKey = "pub"
+	config.SubscribeKey = "sub"
+	config.SuppressLeaveEvents = true
+
+	pn := NewPubNub(config)
+	defer pn.Destroy()
+	pn.SetSubscribeClient(&http.Client{Transport: transport})
+
+	listener := NewListener()
+	pn.AddListener(listener)
+
+	pn.Subscribe().Channels([]string{"ch"}).Execute()
+	assert.Equal("first", waitForSubscriptionMessage(t, listener).Message)
+	waitUntil(t, func() bool {
+		return transport.sent("tt=200 tr=12")
+	})
+
+	// the pending long-poll is aborted and sent again from the same timetoken
+	pn.NetworkChanged()
+	waitUntil(t, func() bool {
+		return transport.count("tt=200 tr=12") == 2
+	})
+	assert.Equal(PNReceivingState, pn.ConnectionState())
+
+	pn.Disconnect()
+	assert.Equal(PNStoppedState, pn.ConnectionState())
+	assert.Equal([]string{"ch"}, pn.GetSubscribedChannels())
+	pn.NetworkChanged()
+
+	pn.Reconnect()
+	waitUntil(t, func() bool {
+		return transport.count("tt=200 tr=12") == 3
+	})
+	assert.Equal(PNHandshakingState, pn.ConnectionState())
+	assert.Equal(1, transport.count("tt= tr="))
+}
+
+func TestReconnectProbesImmediately(t *testing.T) {
+	assert := assert.New(t)
+
+	var online int32
+	var probes int32
+	config := NewConfig()
+	config.PublishKey = "pub"
+	config.SubscribeKey = "sub"
+	config.SuppressLeaveEvents = true
+	config.PNReconnectionPolicy = PNLinearPolicy
+	config.ConnectivityProbe = func(ctx Context) error {
+		atomic.AddInt32(&probes, 1)
+		if atomic.LoadInt32(&online) == 0 {
+			return errors.New("offline")
+		}
+		return nil
+	}
+
+	pn := NewPubNub(config)
+	defer pn.Destroy()
+	// the handshake is held until it's cancelled
+	transport := &longPollTransport{interceptor: stubs.NewInterceptor()}
+	pn.SetSubscribeClient(&http.Client{Transport: transport})
+
+	changes, stop := pn.WatchConnectionState()
+	defer stop()
+
+	pn.Subscribe().Channels([]string{"ch"}).Execute()
+	waitForConnectionState(t, changes, PNReconnectingState)
+
+	atomic.StoreInt32(&online, 1)
+	pn.Reconnect()
+	waitForConnectionState(t, changes, PNReceivingState)
+	waitUntil(t, func() bool {
+		return transport.count("tt= tr=") == 2
+	})
+	// the polling kept running, the resubscribe didn't probe again
+	assert.Equal(int32(2), atomic.LoadInt32(&probes))
+}
+
+func TestSubscribeMessageUnmarshalJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	var sm subscribeMessage
+	err := json.Unmarshal([]byte(`{"a":"4","b":"news.*","c":"news.sports","d":{"score":2},"e":0,"f":512,"i":"publisher","k":"sub","s":7,"u":{"lang":"en"},"p":{"t":"15000000000000001","r":12},"o":{"t":"15000000000000000","r":4}}`), &sm)
+	assert.Nil(err)
+
+	assert.Equal("news.sports", sm.Channel)
+	assert.Equal("news.*", sm.SubscriptionMatch)
+	assert.Equal(map[string]interface{}{"score": float64(2)}, sm.Payload)
+	assert.Equal(`{"score":2}`, string(sm.rawPayload))
+	assert.Equal(PNPublishMetadata{
+		Shard:                "4",
+		Flags:                512,
+		SubscribeKey:         "sub",
+		SequenceNumber:       7,
+		Region:               12,
+		OriginationTimetoken: 15000000000000000,
+		OriginationRegion:    4,
+	}, sm.publishMetadata())
+}
+
+func TestProcessSubscribePayloadRaw(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+
+	listener := NewListener()
+	pn.AddListener(listener)
+
+	var message, presence subscribeMessage
+	assert.Nil(json.Unmarshal([]byte(`{"a":"1","c":"ch","d":{"name":"score","value":2},"i":"publisher","s":3,"p":{"t":"15000000000000001","r":12}}`), &message))
+	assert.Nil(json.Unmarshal([]byte(`{"a":"1","c":"ch-pnpres","d":{"action":"join","uuid":"publisher","occupancy":1,"timestamp":1500000000},"p":{"t":"15000000000000002","r":12}}`), &presence))
+
+	processSubscribePayload(pn.subscriptionManager, message)
+	processSubscribePayload(pn.subscriptionManager, presence)
+
+	m := <-listener.Message
+	assert.Equal(`{"name":"score","value":2}`, string(m.Raw))
+	assert.Equal(int64(3), m.PublishMetadata.SequenceNumber)
+	assert.Equal(12, m.PublishMetadata.Region)
+
+	var score struct {
+		Name  string
+		Value int
+	}
+	assert.Nil(m.Decode(&score))
+	assert.Equal("score", score.Name)
+	assert.Equal(2, score.Value)
+
+	p := <-listener.Presence
+	var event struct {
+		Action string
+		UUID   string
+	}
+	assert.Nil(p.Decode(&event))
+	assert.Equal("join", event.Action)
+	assert.Equal("publisher", event.UUID)
+	assert.Equal(12, p.PublishMetadata.Region)
+}
+
+func TestProcessSubscribePayloadRawDecrypted(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+	pn.Config.Cryptor = testXORCryptor{}
+
+	listener := NewListener()
+	pn.AddListener(listener)
+
+	encrypted, err := utils.NewCryptoModule(testXORCryptor{}).EncryptString(`{"value":2}`)
+	assert.Nil(err)
+	b, _ := json.Marshal(encrypted)
+
+	var message subscribeMessage
+	assert.Nil(json.Unmarshal([]byte(`{"a":"1","c":"ch","d":`+string(b)+`,"p":{"t":"15000000000000001","r":12}}`), &message))
+	processSubscribePayload(pn.subscriptionManager, message)
+
+	m := <-listener.Message
+	assert.Equal(`{"value":2}`, string(m.Raw))
+}
+
+func TestPNMessageDecodeWithoutRaw(t *testing.T) {
+	assert := assert.New(t)
+
+	m := &PNMessage{Message: map[string]interface{}{"value": 2}}
+	var v struct {
+		Value int
+	}
+	assert.Nil(m.Decode(&v))
+	assert.Equal(2, v.Value)
+}
+
+func TestProcessSubscribePayloadPresenceInterval(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := NewPubNub(NewDemoConfig())
+	defer pn.Destroy()
+
+	listener := NewListener()
+	pn.AddListener(listener)
+
+	var sm subscribeMessage
+	assert.Nil(json.Unmarshal([]byte(`{"a":"1","c":"room-pnpres","d":{"action":"interval","timestamp":1500000000,"occupancy":42,"join":["a","b"],"leave":["c"],"timeout":["d"]},"p":{"t":"15000000000000001","r":12}}`), &sm))
+	processSubscribePayload(pn.subscriptionManager, sm)
+
+	p := <-listener.Presence
+	assert.Equal("interval", p.Event)
+	assert.Equal("room", p.Channel)
+	assert.Equal(42, p.Occupancy)
+	assert.Equal([]string{"a", "b"}, p.Join)
+	assert.Equal([]string{"c"}, p.Leave)
+	assert.Equal([]string{"d"}, p.Timeout)
+	assert.False(p.HereNowRefresh)
+}
+
+func TestProcessSubscribePayloadHereNowRefresh(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	other := newTestServerPubNub(srv, "other")
+	defer other.Destroy()
+	other.Subscribe().Channels([]string{"room"}).Execute()
+	waitUntil(t, func() bool {
+		return len(srv.Occupants("room")) == 1
+	})
+
+	pn := newTestServerPubNub(srv, "subscriber")
+	pn.Config.RefreshHereNowOnInterval = true
+	defer pn.Destroy()
+
+	listener := NewListener()
+	pn.AddListener(listener)
+
+	var sm subscribeMessage
+	assert.Nil(json.Unmarshal([]byte(`{"a":"1","c":"room-pnpres","d":{"action":"interval","timestamp":1500000000,"occupancy":1,"here_now_refresh":true},"p":{"t":"15000000000000001","r":12}}`), &sm))
+	processSubscribePayload(pn.subscriptionManager, sm)
+
+	p := <-listener.Presence
+	assert.Equal("interval", p.Event)
+	assert.True(p.HereNowRefresh)
+
+	select {
+	case p = <-listener.Presence:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the here now refresh")
+	}
+	assert.Equal(PNHereNowEvent, p.Event)
+	assert.Equal("room", p.Channel)
+	assert.Equal(1, p.Occupancy)
+	if assert.Len(p.Occupants, 1) {
+		assert.Equal("other", p.Occupants[0].UUID)
+	}
+}
+
+func TestSubscribeRateLimitedResubscribes(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+	srv.FailNext(1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}}, "v2", "subscribe")
+
+	pn := newTestServerPubNub(srv, "subscriber")
+	defer pn.Destroy()
+	listener := NewListener()
+	pn.AddListener(listener)
+
+	start := time.Now()
+	pn.Subscribe().Channels([]string{"ch"}).Execute()
+
+	var categories []StatusCategory
+	timeout := time.After(5 * time.Second)
+	for len(categories) == 0 || categories[len(categories)-1] != PNConnectedCategory {
+		select {
+		case status := <-listener.Status:
+			categories = append(categories, status.Category)
+			if status.Category == PNRateLimitedCategory {
+				// the loop waits for the Retry-After without reconnecting
+				assert.Equal(PNHandshakingState, pn.ConnectionState())
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for the resubscribe, got %v", categories)
+		}
+	}
+	assert.Equal([]StatusCategory{PNRateLimitedCategory, PNConnectedCategory}, categories)
+	assert.True(time.Since(start) >= time.Second)
+
+	_, err := srv.Publish("ch", "publisher", "after the rate limit")
+	assert.Nil(err)
+	assert.Equal("after the rate limit", waitForSubscriptionMessage(t, listener).Message)
+}
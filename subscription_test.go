@@ -0,0 +1,242 @@
+package pubnub
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sprucehealth/pubnub-go/pubnubtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateManagerReleaseSharedChannels(t *testing.T) {
+	assert := assert.New(t)
+
+	m := newStateManager()
+	refsA := m.adaptSubscribeOperation(&SubscribeOperation{Channels: []string{"a", "b"}, ChannelGroups: []string{"g"}})
+	refsB := m.adaptSubscribeOperation(&SubscribeOperation{Channels: []string{"b", "c"}})
+
+	unsubscribe := m.release(refsA)
+	assert.Equal([]string{"a"}, unsubscribe.Channels)
+	assert.Equal([]string{"g"}, unsubscribe.ChannelGroups)
+
+	channels := m.prepareChannelList(true)
+	sort.Strings(channels)
+	assert.Equal([]string{"b", "c"}, channels)
+
+	unsubscribe = m.release(refsB)
+	assert.Equal([]string{"b", "c"}, unsubscribe.Channels)
+	assert.Empty(m.prepareChannelList(true))
+	assert.Empty(m.prepareGroupList(true))
+}
+
+func TestStateManagerReleasePresence(t *testing.T) {
+	assert := assert.New(t)
+
+	m := newStateManager()
+	refsA := m.adaptSubscribeOperation(&SubscribeOperation{Channels: []string{"a"}, PresenceEnabled: true})
+	m.adaptSubscribeOperation(&SubscribeOperation{Channels: []string{"a"}})
+
+	unsubscribe := m.release(refsA)
+	assert.Equal([]string{"a-pnpres"}, unsubscribe.Channels)
+	assert.Equal([]string{"a"}, m.prepareChannelList(true))
+}
+
+func TestStateManagerReleaseAfterUnsubscribe(t *testing.T) {
+	assert := assert.New(t)
+
+	m := newStateManager()
+	refsA := m.adaptSubscribeOperation(&SubscribeOperation{Channels: []string{"a"}})
+	m.adaptUnsubscribeOperation(&UnsubscribeOperation{Channels: []string{"a"}})
+	m.adaptSubscribeOperation(&SubscribeOperation{Channels: []string{"a"}})
+
+	unsubscribe := m.release(refsA)
+	assert.Empty(unsubscribe.Channels)
+	assert.Equal([]string{"a"}, m.prepareChannelList(true))
+}
+
+func TestStateManagerKeepsState(t *testing.T) {
+	assert := assert.New(t)
+
+	m := newStateManager()
+	state := map[string]interface{}{"mood": "happy"}
+	m.adaptSubscribeOperation(&SubscribeOperation{Channels: []string{"a"}, State: state})
+	m.adaptSubscribeOperation(&SubscribeOperation{Channels: []string{"a"}})
+
+	assert.Equal(map[string]interface{}{"a": state}, m.createStatePayload())
+}
+
+func TestSubscriptionAccepts(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newSubscription(nil, &SubscribeOperation{
+		Channels:      []string{"a", "news.*"},
+		ChannelGroups: []string{"g"},
+	})
+
+	message := func(channel, subscription string) listenerEvent {
+		return listenerEvent{kind: listenerMessageEvent, payload: &PNMessage{Channel: channel, Subscription: subscription}}
+	}
+	presence := func(channel, subscription string) listenerEvent {
+		return listenerEvent{kind: listenerPresenceEvent, payload: &PNPresence{Channel: channel, Subscription: subscription}}
+	}
+	status := func(channels ...string) listenerEvent {
+		return listenerEvent{kind: listenerStatusEvent, payload: &PNStatus{AffectedChannels: channels}}
+	}
+
+	assert.True(s.accepts(message("a", "")))
+	assert.True(s.accepts(message("news.sports", "news.*")))
+	assert.True(s.accepts(message("c", "g")))
+	assert.False(s.accepts(message("b", "")))
+	assert.False(s.accepts(message("c", "h")))
+	assert.False(s.accepts(presence("a", "")))
+
+	assert.True(s.accepts(status()))
+	assert.True(s.accepts(status("b", "a")))
+	assert.False(s.accepts(status("b")))
+
+	s = newSubscription(nil, &SubscribeOperation{Channels: []string{"a"}, PresenceEnabled: true})
+	assert.True(s.accepts(presence("a", "")))
+
+	s = newSubscription(nil, &SubscribeOperation{Channels: []string{"a-pnpres"}})
+	assert.True(s.accepts(presence("a", "")))
+	assert.False(s.accepts(message("a", "")))
+}
+
+// waitUntil polls the condition until it is true, the test fails after 5 seconds.
+func waitUntil(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func waitForSubscriptionMessage(t *testing.T, l *Listener) *PNMessage {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case m := <-l.Message:
+			return m
+		case <-l.Status:
+		case <-l.Presence:
+		case <-timeout:
+			t.Fatal("timed out waiting for a message")
+		}
+	}
+}
+
+func TestSubscriptionUnsubscribe(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	config := NewConfig()
+	config.PublishKey = "pub"
+	config.SubscribeKey = "sub"
+	config.UUID = "subscriber"
+
+	pn := NewPubNub(config)
+	defer pn.Destroy()
+	pn.SetClient(srv.Client())
+	pn.SetSubscribeClient(srv.Client())
+
+	subA := pn.Subscribe().Channels([]string{"a", "shared"}).Execute()
+	subB := pn.Subscribe().Channels([]string{"b", "shared"}).Execute()
+	listenerA := subA.Listener()
+	listenerB := subB.Listener()
+	assert.Len(pn.GetListeners(), 2)
+
+	waitUntil(t, func() bool {
+		return len(srv.Occupants("a")) == 1 && len(srv.Occupants("b")) == 1
+	})
+
+	_, err := srv.Publish("b", "publisher", "for b")
+	assert.Nil(err)
+	_, err = srv.Publish("a", "publisher", "for a")
+	assert.Nil(err)
+
+	assert.Equal("for a", waitForSubscriptionMessage(t, listenerA).Message)
+	assert.Equal("for b", waitForSubscriptionMessage(t, listenerB).Message)
+
+	subA.Unsubscribe()
+	subA.Unsubscribe()
+	assert.Len(pn.GetListeners(), 1)
+
+	waitUntil(t, func() bool {
+		return len(srv.Occupants("a")) == 0
+	})
+	assert.Equal([]string{"subscriber"}, srv.Occupants("shared"))
+
+	channels := pn.GetSubscribedChannels()
+	sort.Strings(channels)
+	assert.Equal([]string{"b", "shared"}, channels)
+
+	// the messages received before the resubscribe can be received again
+	_, err = srv.Publish("shared", "publisher", "for b again")
+	assert.Nil(err)
+	for waitForSubscriptionMessage(t, listenerB).Message != "for b again" {
+	}
+}
+
+func TestSubscriptionListenerCreatedOnDemand(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	pn := newTestServerPubNub(srv, "subscriber")
+	defer pn.Destroy()
+
+	sub := pn.Subscribe().Channels([]string{"a"}).Execute()
+	pn.Subscribe().Channels([]string{"b"}).Execute()
+	assert.Empty(pn.GetListeners())
+
+	l := sub.Listener()
+	assert.Equal(l, sub.Listener())
+	assert.Len(pn.GetListeners(), 1)
+	assert.Equal(pn.Config.ListenerOverflowPolicy, l.overflowPolicy)
+
+	sub.Unsubscribe()
+	assert.Empty(pn.GetListeners())
+
+	sub = pn.Subscribe().Channels([]string{"c"}).Execute()
+	sub.Unsubscribe()
+	assert.NotNil(sub.Listener())
+	assert.Empty(pn.GetListeners())
+}
+
+func TestSubscriptionListenerInUse(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := pubnubtest.NewServer()
+	defer srv.Close()
+
+	pn := newTestServerPubNub(srv, "subscriber")
+	defer pn.Destroy()
+
+	l := NewListener()
+	pn.AddListener(l)
+
+	subA := pn.Subscribe().Channels([]string{"a"}).Listener(l).Execute()
+	subB := pn.Subscribe().Channels([]string{"b"}).Listener(l).Execute()
+
+	// the listener added with AddListener keeps receiving the events of all the channels
+	assert.Nil(l.filter)
+	assert.True(subA.Listener() != l)
+	assert.True(subB.Listener() != subA.Listener())
+	assert.Equal(l.Message, subA.Listener().Message)
+	assert.Len(pn.GetListeners(), 3)
+
+	subA.Unsubscribe()
+	assert.Len(pn.GetListeners(), 2)
+	assert.True(pn.GetListeners()[l])
+
+	// a listener used by a single subscription isn't copied
+	own := NewListener()
+	subC := pn.Subscribe().Channels([]string{"c"}).Listener(own).Execute()
+	assert.Equal(own, subC.Listener())
+}
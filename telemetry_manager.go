@@ -29,6 +29,8 @@ type TelemetryManager struct {
 	operations map[string][]LatencyEntry
 
 	ctx Context
+	// routines counts the clean up goroutine, for Close to wait for it.
+	routines *routineGroup
 
 	cleanUpTimer *time.Ticker
 
@@ -36,14 +38,15 @@ type TelemetryManager struct {
 	IsRunning         bool
 }
 
-func newTelemetryManager(maxLatencyDataAge int, ctx Context) *TelemetryManager {
+func newTelemetryManager(maxLatencyDataAge int, ctx Context, routines *routineGroup) *TelemetryManager {
 	manager := &TelemetryManager{
 		maxLatencyDataAge: maxLatencyDataAge,
 		operations:        make(map[string][]LatencyEntry),
 		ctx:               ctx,
+		routines:          routines,
 	}
 
-	go manager.startCleanUpTimer()
+	manager.startCleanUpTimer()
 
 	return manager
 }
@@ -116,7 +119,7 @@ func (m *TelemetryManager) startCleanUpTimer() {
 		time.Duration(
 			cleanUpInterval*cleanUpIntervalMultiplier) * time.Millisecond)
 
-	go func() {
+	m.routines.spawn(func() {
 	CleanUpTimerLabel:
 		for {
 			timerCh := m.cleanUpTimer.C
@@ -129,7 +132,7 @@ func (m *TelemetryManager) startCleanUpTimer() {
 				break CleanUpTimerLabel
 			}
 		}
-	}()
+	})
 }
 
 func telemetryEndpointNameForOperation(t OperationType) string {
@@ -174,6 +177,8 @@ func telemetryEndpointNameForOperation(t OperationType) string {
 	case PNAccessManagerRevoke:
 		fallthrough
 	case PNAccessManagerGrant:
+		fallthrough
+	case PNAccessManagerGrantToken:
 		endpoint = "pam"
 		break
 	case PNSignalOperation:
@@ -208,6 +213,13 @@ func telemetryEndpointNameForOperation(t OperationType) string {
 	case PNManageMembersOperation:
 		endpoint = "obj"
 		break
+	case PNAddMessageActionsOperation:
+		fallthrough
+	case PNGetMessageActionsOperation:
+		fallthrough
+	case PNRemoveMessageActionsOperation:
+		endpoint = "msga"
+		break
 	default:
 		endpoint = "time"
 		break
@@ -24,7 +24,7 @@ func TestAverageLatency(t *testing.T) {
 func TestCleanUp(t *testing.T) {
 	assert := assert.New(t)
 	ctx, _ := contextWithCancel(backgroundContext)
-	manager := newTelemetryManager(1, ctx)
+	manager := newTelemetryManager(1, ctx, &routineGroup{})
 
 	for i := 0; i < 10; i++ {
 		manager.StoreLatency(float64(i), PNPublishOperation)
@@ -41,7 +41,7 @@ func TestCleanUp(t *testing.T) {
 func TestValidQueries(t *testing.T) {
 	assert := assert.New(t)
 	ctx, _ := contextWithCancel(backgroundContext)
-	manager := newTelemetryManager(60, ctx)
+	manager := newTelemetryManager(60, ctx, &routineGroup{})
 
 	manager.StoreLatency(float64(1), PNPublishOperation)
 	manager.StoreLatency(float64(2), PNPublishOperation)
@@ -15,7 +15,7 @@ func TestAddChannelToChannelGroupNotStubbed(t *testing.T) {
 	assert := assert.New(t)
 
 	pn := pubnub.NewPubNub(configCopy())
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	_, _, err := pn.AddChannelToChannelGroup().
 		Channels([]string{"ch"}).
@@ -95,7 +95,7 @@ func TestAddChannelToChannelGroupSuperCall(t *testing.T) {
 func TestAddChannelToChannelGroupSuccessAdded(t *testing.T) {
 	assert := assert.New(t)
 	pn := pubnub.NewPubNub(configCopy())
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	interceptor := stubs.NewInterceptor()
 	interceptor.AddStub(&stubs.Stub{
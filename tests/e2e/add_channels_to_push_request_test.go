@@ -14,7 +14,7 @@ func TestAddChannelToPushNotStubbed(t *testing.T) {
 	assert := assert.New(t)
 
 	pn := pubnub.NewPubNub(configCopy())
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	_, _, err := pn.AddPushNotificationsOnChannels().
 		Channels([]string{"ch"}).
@@ -30,7 +30,7 @@ func TestAddChannelToPushNotStubbedContext(t *testing.T) {
 
 	pn := pubnub.NewPubNub(configCopy())
 
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	_, _, err := pn.AddPushNotificationsOnChannelsWithContext(backgroundContext).
 		Channels([]string{"ch1"}).
@@ -50,7 +50,7 @@ func TestAddChannelToPushNotStubbedContextWithQueryParam(t *testing.T) {
 		"q1": "v1",
 		"q2": "v2",
 	}
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	_, _, err := pn.AddPushNotificationsOnChannelsWithContext(backgroundContext).
 		Channels([]string{"ch1"}).
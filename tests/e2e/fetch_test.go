@@ -32,7 +32,7 @@ func TestFetch(t *testing.T) {
 	assert := a.New(t)
 
 	pn := pubnub.NewPubNub(configCopy())
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 	reverse := true
 
 	r := GenRandom()
@@ -24,7 +24,7 @@ func TestGrantParseLogsForAuthKey(t *testing.T) {
 	pn.Config.SecretKey = "sec-key"
 	pn.Config.AuthKey = "myAuthKey"
 
-	pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	pn.Grant().
 		Read(true).Write(true).Manage(true).
@@ -71,7 +71,7 @@ func TestGrantParseLogsForMultipleAuthKeys(t *testing.T) {
 	pn.Config.SecretKey = "sec-key"
 	pn.Config.AuthKey = "myAuthKey"
 
-	pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	pn.Grant().
 		Read(true).Write(true).Manage(true).
@@ -217,7 +217,7 @@ func TestGrantMultipleMixed(t *testing.T) {
 
 	pn := pubnub.NewPubNub(pamConfigCopy())
 	pn.SetClient(interceptor.GetClient())
-	pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	res, _, err := pn.Grant().
 		Read(true).Write(true).Manage(true).
@@ -245,7 +245,7 @@ func TestGrantSingleChannel(t *testing.T) {
 
 	pn := pubnub.NewPubNub(pamConfigCopy())
 	pn.SetClient(interceptor.GetClient())
-	pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	res, _, err := pn.Grant().
 		Read(true).Write(true).
@@ -481,3 +481,33 @@ func TestGrantMultipleGroupsWithAuth(t *testing.T) {
 	assert.True(res.ChannelGroups["cg2"].AuthKeys["my-pam-key"].ReadEnabled)
 	assert.False(res.ChannelGroups["cg2"].AuthKeys["my-pam-key"].ManageEnabled)
 }
+
+func TestGrantToken(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := pubnub.NewPubNub(pamConfigCopy())
+
+	res, _, err := pn.GrantToken().
+		TTL(10).
+		Channels(map[string]pubnub.ChannelPermissions{
+			"ch1": {Read: true, Write: true},
+		}).
+		UsersPattern(map[string]pubnub.UserSpacePermissions{
+			"^user-.*$": {Read: true},
+		}).
+		Execute()
+
+	assert.Nil(err)
+	if res != nil {
+		token, err := pubnub.ParseToken(res.Data.Token)
+		assert.Nil(err)
+		if token != nil {
+			assert.Equal(10, token.TTL)
+			assert.True(token.Resources.Channels["ch1"].Write)
+			assert.True(token.Patterns.Users["^user-.*$"].Read)
+		}
+
+		pn.SetToken(res.Data.Token)
+		assert.Equal([]string{res.Data.Token}, pn.GetTokens())
+	}
+}
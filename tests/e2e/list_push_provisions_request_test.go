@@ -13,7 +13,7 @@ func TestListPushProvisionsNotStubbed(t *testing.T) {
 	assert := assert.New(t)
 
 	pn := pubnub.NewPubNub(configCopy())
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 	r := GenRandom()
 	ch1 := fmt.Sprintf("testChannel_sub_%d", r.Intn(99999))
 	cg1 := fmt.Sprintf("testCG_sub_%d", r.Intn(99999))
@@ -38,7 +38,7 @@ func TestListPushProvisionsNotStubbedContext(t *testing.T) {
 	assert := assert.New(t)
 
 	pn := pubnub.NewPubNub(configCopy())
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	_, _, err := pn.AddPushNotificationsOnChannelsWithContext(backgroundContext).
 		Channels([]string{"ch2"}).
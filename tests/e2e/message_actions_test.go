@@ -0,0 +1,67 @@
+package e2e
+
+import (
+	"fmt"
+	"testing"
+
+	pubnub "github.com/sprucehealth/pubnub-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageActions(t *testing.T) {
+	assert := assert.New(t)
+
+	pn := pubnub.NewPubNub(configCopy())
+	r := GenRandom()
+
+	ch := fmt.Sprintf("testChannel_msga_%d", r.Intn(99999))
+
+	resPub, _, err := pn.Publish().
+		Channel(ch).
+		Message("hey").
+		Execute()
+	assert.Nil(err)
+
+	messageTimetoken := fmt.Sprintf("%d", resPub.Timestamp)
+
+	resAdd, _, err := pn.AddMessageAction().
+		Channel(ch).
+		MessageTimetoken(messageTimetoken).
+		Action(pubnub.MessageAction{
+			ActionType:  "reaction",
+			ActionValue: "smiley_face",
+		}).
+		Execute()
+	assert.Nil(err)
+	if resAdd != nil {
+		assert.Equal(200, resAdd.Status)
+		assert.Equal("reaction", resAdd.Data.ActionType)
+		assert.Equal("smiley_face", resAdd.Data.ActionValue)
+		assert.Equal(messageTimetoken, resAdd.Data.MessageTimetoken)
+		assert.Equal(pn.Config.UUID, resAdd.Data.UUID)
+	}
+
+	resGet, _, err := pn.GetMessageActions().
+		Channel(ch).
+		Execute()
+	assert.Nil(err)
+	if resGet != nil && resAdd != nil {
+		assert.Equal(200, resGet.Status)
+		assert.Equal(1, len(resGet.Data))
+		if len(resGet.Data) > 0 {
+			assert.Equal(resAdd.Data.ActionTimetoken, resGet.Data[0].ActionTimetoken)
+		}
+	}
+
+	if resAdd != nil {
+		resRem, _, err := pn.RemoveMessageAction().
+			Channel(ch).
+			MessageTimetoken(messageTimetoken).
+			ActionTimetoken(resAdd.Data.ActionTimetoken).
+			Execute()
+		assert.Nil(err)
+		if resRem != nil {
+			assert.Equal(200, resRem.Status)
+		}
+	}
+}
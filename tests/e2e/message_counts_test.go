@@ -28,7 +28,7 @@ func TestMessageCounts(t *testing.T) {
 	assert := a.New(t)
 
 	pn := pubnub.NewPubNub(pamConfigCopy())
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	r := GenRandom()
 	ch1 := fmt.Sprintf("testChannel_sub_%d", r.Intn(99999))
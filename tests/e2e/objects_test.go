@@ -550,8 +550,8 @@ func TestObjectsListeners(t *testing.T) {
 
 	userid := fmt.Sprintf("testlistuser_%d", r.Intn(99999))
 	spaceid := fmt.Sprintf("testlistspace_%d", r.Intn(99999))
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
-	//pnSub.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
+	//pnSub.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	//Subscribe to the channel names
 
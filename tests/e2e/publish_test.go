@@ -71,7 +71,7 @@ func TestPublishSuccessSlice(t *testing.T) {
 	})*/
 
 	pn := pubnub.NewPubNub(config)
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 	//pn.SetClient(interceptor.GetClient())
 
 	_, _, err := pn.Publish().
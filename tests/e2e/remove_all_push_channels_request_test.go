@@ -13,7 +13,7 @@ func TestRemoveAllPushNotifications(t *testing.T) {
 	assert := assert.New(t)
 
 	pn := pubnub.NewPubNub(configCopy())
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	_, _, err := pn.RemoveAllPushNotifications().
 		DeviceIDForPush("cg").
@@ -26,7 +26,7 @@ func TestRemoveAllPushNotificationsContext(t *testing.T) {
 	assert := assert.New(t)
 
 	pn := pubnub.NewPubNub(configCopy())
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	_, _, err := pn.RemoveAllPushNotificationsWithContext(backgroundContext).
 		DeviceIDForPush("cg").
@@ -13,7 +13,7 @@ func TestRemovePushNotificationsFromChannels(t *testing.T) {
 	assert := assert.New(t)
 
 	pn := pubnub.NewPubNub(configCopy())
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	_, _, err := pn.RemovePushNotificationsFromChannels().
 		Channels([]string{"ch"}).
@@ -27,7 +27,7 @@ func TestRemovePushNotificationsFromChannelsContext(t *testing.T) {
 	assert := assert.New(t)
 
 	pn := pubnub.NewPubNub(configCopy())
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	_, _, err := pn.RemovePushNotificationsFromChannelsWithContext(backgroundContext).
 		Channels([]string{"ch"}).
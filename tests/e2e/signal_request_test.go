@@ -24,7 +24,7 @@ func TestSignal(t *testing.T) {
 	for _, ip := range ips {
 		fmt.Printf("%s IN A %s\n", pn.Config.Origin, ip.String())
 	}
-	//	pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//	pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 	pn.Config.SubscribeKey = "demo"
 	pn.Config.PublishKey = "demo"
 
@@ -86,7 +86,7 @@ func SubscribeSignalUnsubscribeMultiCommon(t *testing.T, s interface{}, cipher s
 
 	pn.Config.CipherKey = cipher
 	pn.Config.DisablePNOtherProcessing = disablePNOtherProcessing
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	listener := pubnub.NewListener()
 
@@ -40,7 +40,7 @@ func SubscribesLogsForQueryParams(t *testing.T) {
 		"q2": "v2",
 	}
 
-	pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 	pn.Subscribe().
 		Channels([]string{"ch1", "ch2"}).
 		QueryParam(queryParam).
@@ -77,7 +77,7 @@ func TestRequestMesssageOverflow(t *testing.T) {
 
 	pn := pubnub.NewPubNub(configCopy())
 	pn.Config.MessageQueueOverflowCount = 2
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	timestamp1 := GetTimetoken(pn)
 	for i := 0; i < 3; i++ {
@@ -149,7 +149,7 @@ func TestSubscribeUnsubscribe(t *testing.T) {
 	// }()
 
 	pn := pubnub.NewPubNub(configCopy())
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	listener := pubnub.NewListener()
 
@@ -639,7 +639,7 @@ func SubscribePublishUnsubscribeMultiCommon(t *testing.T, s interface{}, cipher
 
 	pn.Config.CipherKey = cipher
 	pn.Config.DisablePNOtherProcessing = disablePNOtherProcessing
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	listener := pubnub.NewListener()
 
@@ -738,7 +738,7 @@ func SubscribePublishUnsubscribeMultiCommon(t *testing.T, s interface{}, cipher
 
 	pn := pubnub.NewPubNub(configCopy())
 	pn.Config.CipherKey = "enigma"
-	pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	s := map[string]interface{}{
 		"id":        1,
@@ -816,7 +816,7 @@ func SubscribePublishUnsubscribeMultiCommon(t *testing.T, s interface{}, cipher
 	pn := pubnub.NewPubNub(configCopy())
 	pn.Config.CipherKey = "enigma"
 	pn.Config.DisablePNOtherProcessing = true
-	pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	s := map[string]interface{}{
 		"id":        2,
@@ -982,7 +982,7 @@ ch := "testChannel_sub_96112" //fmt.Sprintf("testChannel_sub_%d", r.Intn(99999))
 
 pn := pubnub.NewPubNub(configCopy())
 pn.Config.CipherKey = "enigma"
-pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 /*s := map[string]interface{}{
 	"not_other": "1234",
@@ -1496,12 +1496,12 @@ func TestSubscribe403Error(t *testing.T) {
 	ch := randomized("sub-403-ch")
 
 	pn := pubnub.NewPubNub(pamConfigCopy())
-	pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 	pamConfig := pamConfigCopy()
 	pamConfig.SecretKey = ""
 	pn2 := pubnub.NewPubNub(pamConfig)
 
-	pn2.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	pn2.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 	listener := pubnub.NewListener()
 
 	go func() {
@@ -1572,7 +1572,7 @@ func TestSubscribeSignal(t *testing.T) {
 	errChan := make(chan string)
 
 	pn := pubnub.NewPubNub(configCopy())
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	pn.SetSubscribeClient(interceptor.GetClient())
 	listener := pubnub.NewListener()
@@ -1718,7 +1718,7 @@ func TestSubscribeWithCustomTimetoken(t *testing.T) {
 	errChan := make(chan string)
 
 	pn := pubnub.NewPubNub(configCopy())
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 	//pn.SetSubscribeClient(interceptor.GetClient())
 	listener := pubnub.NewListener()
 
@@ -1969,7 +1969,7 @@ func ReconnectionExhaustion(t *testing.T) {
 	config.PNReconnectionPolicy = pubnub.PNLinearPolicy
 
 	pn := pubnub.NewPubNub(config)
-	//pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	//pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 	pn.Config.AuthKey = "myAuthKey"
 	pn.SetSubscribeClient(interceptor.GetClient())
 	listener := pubnub.NewListener()
@@ -25,7 +25,7 @@ func TestSubscribeParseLogsForAuthKey(t *testing.T) {
 	pn.Config.AuthKey = "myAuthKey"
 	channel := "ch"
 
-	pn.Config.Log = log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
+	pn.Config.Log = pubnub.NewStdLogger(log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile), pubnub.PNLogDebug)
 
 	pn.Subscribe().
 		Channels([]string{channel}).
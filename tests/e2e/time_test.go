@@ -53,3 +53,27 @@ func TestTimeContext(t *testing.T) {
 
 	assert.True(int64(15059085932399340) < res.Timetoken)
 }
+
+func TestTimeTransport(t *testing.T) {
+	assert := assert.New(t)
+
+	interceptor := stubs.NewInterceptor()
+	interceptor.AddStub(&stubs.Stub{
+		Method:             "GET",
+		Path:               "/time/0",
+		Query:              "",
+		ResponseBody:       `[15078947309567840]`,
+		IgnoreQueryKeys:    []string{"uuid", "pnsdk"},
+		ResponseStatusCode: 200,
+	})
+
+	pn := pubnub.NewPubNub(configCopy())
+	pn.SetClient(stubs.NewInterceptor().GetClient())
+
+	res, _, err := pn.Time().
+		Transport(interceptor.Transport).
+		Execute()
+
+	assert.Nil(err)
+	assert.Equal(int64(15078947309567840), res.Timetoken)
+}
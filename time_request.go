@@ -78,6 +78,10 @@ func (o *timeOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *timeOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *timeOpts) context() Context {
 	return o.ctx
 }
@@ -96,8 +100,8 @@ func (o *timeOpts) buildQuery() (*url.Values, error) {
 	return q, nil
 }
 
-func (o *timeOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *timeOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *timeOpts) buildBody() ([]byte, error) {
@@ -0,0 +1,341 @@
+package pubnub
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sprucehealth/pubnub-go/utils"
+)
+
+// PNTokenResources contains the permissions of each of the resources, or patterns, of a token.
+type PNTokenResources struct {
+	Channels      map[string]ChannelPermissions
+	ChannelGroups map[string]GroupPermissions
+	Users         map[string]UserSpacePermissions
+	Spaces        map[string]UserSpacePermissions
+}
+
+// PNToken is the decoded form of a token returned by GrantToken.
+type PNToken struct {
+	Version   int
+	Timestamp int64
+	// TTL in minutes
+	TTL       int
+	Resources PNTokenResources
+	Patterns  PNTokenResources
+	Meta      map[string]interface{}
+	Signature []byte
+}
+
+// ParseToken decodes the CBOR payload of a token returned by GrantToken.
+func ParseToken(token string) (*PNToken, error) {
+	token = strings.NewReplacer("-", "+", "_", "/").Replace(token)
+	if i := len(token) % 4; i != 0 {
+		token += strings.Repeat("=", 4-i)
+	}
+
+	b, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := utils.DecodeCBOR(b)
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("pubnub: token is not a CBOR map")
+	}
+
+	t := &PNToken{
+		Version:   int(cborInt(m["v"])),
+		Timestamp: cborInt(m["t"]),
+		TTL:       int(cborInt(m["ttl"])),
+		Resources: newPNTokenResources(m["res"]),
+		Patterns:  newPNTokenResources(m["pat"]),
+	}
+	t.Meta, _ = m["meta"].(map[string]interface{})
+	t.Signature, _ = m["sig"].([]byte)
+
+	return t, nil
+}
+
+func cborInt(v interface{}) int64 {
+	switch i := v.(type) {
+	case int64:
+		return i
+	case uint64:
+		return int64(i)
+	case float64:
+		return int64(i)
+	}
+
+	return 0
+}
+
+func newPNTokenResources(v interface{}) PNTokenResources {
+	m, _ := v.(map[string]interface{})
+	r := PNTokenResources{
+		Channels:      make(map[string]ChannelPermissions),
+		ChannelGroups: make(map[string]GroupPermissions),
+		Users:         make(map[string]UserSpacePermissions),
+		Spaces:        make(map[string]UserSpacePermissions),
+	}
+
+	chans, _ := m["chan"].(map[string]interface{})
+	for k, v := range chans {
+		bits := cborInt(v)
+		r.Channels[k] = ChannelPermissions{
+			Read:   bits&pnPermissionRead != 0,
+			Write:  bits&pnPermissionWrite != 0,
+			Delete: bits&pnPermissionDelete != 0,
+		}
+	}
+
+	groups, _ := m["grp"].(map[string]interface{})
+	for k, v := range groups {
+		bits := cborInt(v)
+		r.ChannelGroups[k] = GroupPermissions{
+			Read:   bits&pnPermissionRead != 0,
+			Manage: bits&pnPermissionManage != 0,
+		}
+	}
+
+	users, _ := m["usr"].(map[string]interface{})
+	for k, v := range users {
+		r.Users[k] = newUserSpacePermissions(cborInt(v))
+	}
+
+	spaces, _ := m["spc"].(map[string]interface{})
+	for k, v := range spaces {
+		r.Spaces[k] = newUserSpacePermissions(cborInt(v))
+	}
+
+	return r
+}
+
+func newUserSpacePermissions(bits int64) UserSpacePermissions {
+	return UserSpacePermissions{
+		Read:   bits&pnPermissionRead != 0,
+		Write:  bits&pnPermissionWrite != 0,
+		Manage: bits&pnPermissionManage != 0,
+		Delete: bits&pnPermissionDelete != 0,
+		Create: bits&pnPermissionCreate != 0,
+	}
+}
+
+type tokenEntry struct {
+	token    string
+	parsed   *PNToken
+	patterns map[PNResourceType][]*regexp.Regexp
+}
+
+func newTokenEntry(token string, parsed *PNToken) *tokenEntry {
+	e := &tokenEntry{
+		token:    token,
+		parsed:   parsed,
+		patterns: make(map[PNResourceType][]*regexp.Regexp),
+	}
+
+	add := func(resourceType PNResourceType, pattern string) {
+		if re, err := regexp.Compile(pattern); err == nil {
+			e.patterns[resourceType] = append(e.patterns[resourceType], re)
+		}
+	}
+	for p := range parsed.Patterns.Channels {
+		add(PNChannels, p)
+	}
+	for p := range parsed.Patterns.ChannelGroups {
+		add(PNGroups, p)
+	}
+	for p := range parsed.Patterns.Users {
+		add(PNUsers, p)
+	}
+	for p := range parsed.Patterns.Spaces {
+		add(PNSpaces, p)
+	}
+
+	return e
+}
+
+func (e *tokenEntry) expired(now time.Time) bool {
+	if e.parsed.TTL <= 0 || e.parsed.Timestamp <= 0 {
+		return false
+	}
+
+	return now.Unix() > e.parsed.Timestamp+int64(e.parsed.TTL)*60
+}
+
+func (e *tokenEntry) hasResource(resourceType PNResourceType, id string) bool {
+	var ok bool
+	switch resourceType {
+	case PNChannels:
+		_, ok = e.parsed.Resources.Channels[id]
+	case PNGroups:
+		_, ok = e.parsed.Resources.ChannelGroups[id]
+	case PNUsers:
+		_, ok = e.parsed.Resources.Users[id]
+	case PNSpaces:
+		_, ok = e.parsed.Resources.Spaces[id]
+	}
+
+	return ok
+}
+
+func (e *tokenEntry) hasResourceType(resourceType PNResourceType) bool {
+	if len(e.patterns[resourceType]) > 0 {
+		return true
+	}
+
+	switch resourceType {
+	case PNChannels:
+		return len(e.parsed.Resources.Channels) > 0
+	case PNGroups:
+		return len(e.parsed.Resources.ChannelGroups) > 0
+	case PNUsers:
+		return len(e.parsed.Resources.Users) > 0
+	case PNSpaces:
+		return len(e.parsed.Resources.Spaces) > 0
+	}
+
+	return false
+}
+
+// covers returns true if the token grants access to the resource, either directly or through a pattern.
+// An empty id matches any token with permissions on the resource type.
+func (e *tokenEntry) covers(resourceType PNResourceType, id string) bool {
+	if id == "" {
+		return e.hasResourceType(resourceType)
+	}
+
+	if resourceType == PNChannels {
+		id = strings.TrimSuffix(id, "-pnpres")
+	}
+
+	if e.hasResource(resourceType, id) {
+		return true
+	}
+
+	for _, re := range e.patterns[resourceType] {
+		if re.MatchString(id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tokenManager stores the tokens set on the PubNub instance and picks the one to
+// send with a request based on the resources it accesses.
+type tokenManager struct {
+	sync.RWMutex
+
+	tokens []*tokenEntry
+}
+
+func newTokenManager() *tokenManager {
+	return &tokenManager{}
+}
+
+func (m *tokenManager) storeToken(token string) error {
+	parsed, err := ParseToken(token)
+	if err != nil {
+		return err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	for i, e := range m.tokens {
+		if e.token == token {
+			m.tokens = append(m.tokens[:i], m.tokens[i+1:]...)
+			break
+		}
+	}
+	m.tokens = append(m.tokens, newTokenEntry(token, parsed))
+
+	return nil
+}
+
+func (m *tokenManager) getTokens() []string {
+	m.RLock()
+	defer m.RUnlock()
+
+	tokens := make([]string, 0, len(m.tokens))
+	for _, e := range m.tokens {
+		tokens = append(tokens, e.token)
+	}
+
+	return tokens
+}
+
+// tokenFor returns the most recently set token which grants access to all of the resources.
+func (m *tokenManager) tokenFor(resources map[PNResourceType][]string) string {
+	if m == nil || len(resources) == 0 {
+		return ""
+	}
+
+	m.RLock()
+	defer m.RUnlock()
+
+	now := time.Now()
+
+	for i := len(m.tokens) - 1; i >= 0; i-- {
+		e := m.tokens[i]
+		if e.expired(now) {
+			continue
+		}
+
+		matched := true
+		for resourceType, ids := range resources {
+			if len(ids) == 0 {
+				ids = []string{""}
+			}
+			for _, id := range ids {
+				if !e.covers(resourceType, id) {
+					matched = false
+					break
+				}
+			}
+			if !matched {
+				break
+			}
+		}
+
+		if matched {
+			return e.token
+		}
+	}
+
+	return ""
+}
+
+// setAuthParam sets the auth query param to the token which grants access to the resources of a single type.
+func (m *tokenManager) setAuthParam(q *url.Values, resourceType PNResourceType, ids ...string) {
+	if token := m.tokenFor(map[PNResourceType][]string{resourceType: ids}); token != "" {
+		q.Set("auth", token)
+	}
+}
+
+// setChannelsAuthParam sets the auth query param to the token which grants access to all of the
+// channels and channel groups.
+func (m *tokenManager) setChannelsAuthParam(q *url.Values, channels, groups []string) {
+	resources := make(map[PNResourceType][]string)
+	if len(channels) > 0 {
+		resources[PNChannels] = channels
+	}
+	if len(groups) > 0 {
+		resources[PNGroups] = groups
+	}
+
+	if token := m.tokenFor(resources); token != "" {
+		q.Set("auth", token)
+	}
+}
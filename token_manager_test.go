@@ -0,0 +1,130 @@
+package pubnub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// {"v":2,"t":1568805412,"ttl":1440,"res":{"chan":{"ch1":3},"grp":{"cg1":5},"usr":{"user1":31},"spc":{}},"pat":{"chan":{},"grp":{},"usr":{},"spc":{"^space-.*$":1}},"meta":{"role":"client"},"sig":...}
+const testToken1 = "p2F2AmF0Gl2CEiRjdHRsGQWgY3Jlc6RkY2hhbqFjY2gxA2NncnChY2NnMQVjdXNyoWV1c2VyMRgfY3NwY6BjcGF0pGRjaGFuoGNncnCgY3VzcqBjc3BjoWpec3BhY2UtLiokAWRtZXRhoWRyb2xlZmNsaWVudGNzaWdYIKVDmX2E8SeYNQwJve8s2xcb9B7T5KX4CK8v6wxWJjAJ"
+
+// {"v":2,"t":1568805412,"ttl":1440,"res":{"chan":{"ch2":1},"grp":{},"usr":{},"spc":{"space1":3}},"pat":{"chan":{"^ch-.*$":1},"grp":{},"usr":{},"spc":{}},"meta":{},"sig":...}
+const testToken2 = "p2F2AmF0Gl2CEiRjdHRsGQWgY3Jlc6RkY2hhbqFjY2gyAWNncnCgY3VzcqBjc3BjoWZzcGFjZTEDY3BhdKRkY2hhbqFnXmNoLS4qJAFjZ3JwoGN1c3KgY3NwY6BkbWV0YaBjc2lnWCDH70Wv1klLyLtEtSdM4uRtkeulrYtxNqaTgpvqS71aWQ"
+
+// storeFreshToken stores the token as if it had just been granted.
+func storeFreshToken(m *tokenManager, token string) {
+	m.storeToken(token)
+	m.tokens[len(m.tokens)-1].parsed.Timestamp = time.Now().Unix()
+}
+
+func TestParseToken(t *testing.T) {
+	assert := assert.New(t)
+
+	token, err := ParseToken(testToken1)
+	assert.Nil(err)
+
+	assert.Equal(2, token.Version)
+	assert.Equal(int64(1568805412), token.Timestamp)
+	assert.Equal(1440, token.TTL)
+	assert.Equal(map[string]ChannelPermissions{
+		"ch1": {Read: true, Write: true},
+	}, token.Resources.Channels)
+	assert.Equal(map[string]GroupPermissions{
+		"cg1": {Read: true, Manage: true},
+	}, token.Resources.ChannelGroups)
+	assert.Equal(map[string]UserSpacePermissions{
+		"user1": {Read: true, Write: true, Manage: true, Delete: true, Create: true},
+	}, token.Resources.Users)
+	assert.Equal(0, len(token.Resources.Spaces))
+	assert.Equal(map[string]UserSpacePermissions{
+		"^space-.*$": {Read: true},
+	}, token.Patterns.Spaces)
+	assert.Equal("client", token.Meta["role"])
+	assert.Equal(32, len(token.Signature))
+}
+
+func TestParseTokenError(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := ParseToken("not a token")
+	assert.NotNil(err)
+
+	// base64 of the CBOR array [1]
+	_, err = ParseToken("gQE")
+	assert.NotNil(err)
+}
+
+func TestTokenManagerTokenFor(t *testing.T) {
+	assert := assert.New(t)
+
+	m := newTokenManager()
+	storeFreshToken(m, testToken1)
+	storeFreshToken(m, testToken2)
+
+	assert.Equal(testToken1, m.tokenFor(map[PNResourceType][]string{PNChannels: {"ch1"}}))
+	assert.Equal(testToken1, m.tokenFor(map[PNResourceType][]string{PNChannels: {"ch1-pnpres"}}))
+	assert.Equal(testToken2, m.tokenFor(map[PNResourceType][]string{PNChannels: {"ch2", "ch-a"}}))
+	assert.Equal("", m.tokenFor(map[PNResourceType][]string{PNChannels: {"ch1", "ch2"}}))
+	assert.Equal(testToken1, m.tokenFor(map[PNResourceType][]string{PNChannels: {"ch1"}, PNGroups: {"cg1"}}))
+	assert.Equal(testToken1, m.tokenFor(map[PNResourceType][]string{PNUsers: {"user1"}}))
+	assert.Equal(testToken1, m.tokenFor(map[PNResourceType][]string{PNUsers: {}}))
+	assert.Equal(testToken1, m.tokenFor(map[PNResourceType][]string{PNSpaces: {"space-a"}}))
+	assert.Equal(testToken2, m.tokenFor(map[PNResourceType][]string{PNSpaces: {"space1"}}))
+	assert.Equal(testToken2, m.tokenFor(map[PNResourceType][]string{PNSpaces: {}}))
+	assert.Equal("", m.tokenFor(map[PNResourceType][]string{PNUsers: {"user2"}}))
+	assert.Equal("", m.tokenFor(map[PNResourceType][]string{}))
+}
+
+func TestTokenManagerExpiredToken(t *testing.T) {
+	assert := assert.New(t)
+
+	m := newTokenManager()
+	m.storeToken(testToken1)
+
+	assert.Equal([]string{testToken1}, m.getTokens())
+	assert.Equal("", m.tokenFor(map[PNResourceType][]string{PNChannels: {"ch1"}}))
+}
+
+func TestSetTokens(t *testing.T) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+
+	pn.SetTokens([]string{testToken1, "not a token", testToken2})
+	assert.Equal([]string{testToken1, testToken2}, pn.GetTokens())
+
+	pn.SetToken(testToken1)
+	assert.Equal([]string{testToken2, testToken1}, pn.GetTokens())
+}
+
+func TestTokenAuthParam(t *testing.T) {
+	assert := assert.New(t)
+	pn := NewPubNub(NewDemoConfig())
+	pn.Config.AuthKey = "myauthkey"
+	storeFreshToken(pn.tokenManager, testToken1)
+
+	o := newGetUserBuilder(pn)
+	o.ID("user1")
+
+	u, err := buildURL(o.opts)
+	assert.Nil(err)
+	assert.Equal(testToken1, u.Query().Get("auth"))
+
+	o = newGetUserBuilder(pn)
+	o.ID("user2")
+
+	u, err = buildURL(o.opts)
+	assert.Nil(err)
+	assert.Equal("myauthkey", u.Query().Get("auth"))
+
+	s := &subscribeOpts{
+		pubnub:        pn,
+		Channels:      []string{"ch1", "ch1-pnpres"},
+		ChannelGroups: []string{"cg1"},
+	}
+
+	u, err = buildURL(s)
+	assert.Nil(err)
+	assert.Equal(testToken1, u.Query().Get("auth"))
+}
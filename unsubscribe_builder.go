@@ -39,3 +39,16 @@ func (b *unsubscribeBuilder) QueryParam(queryParam map[string]string) *unsubscri
 func (b *unsubscribeBuilder) Execute() {
 	b.pubnub.subscriptionManager.adaptUnsubscribe(b.operation)
 }
+
+// ExecuteContext runs the Unsubscribe request like Execute, and blocks until the leave request
+// completes. The error of the leave request is returned, or the error of the ctx once it's done.
+func (b *unsubscribeBuilder) ExecuteContext(ctx Context) error {
+	left := b.pubnub.subscriptionManager.adaptUnsubscribe(b.operation)
+
+	select {
+	case err := <-left:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
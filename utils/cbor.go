@@ -0,0 +1,211 @@
+package utils
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// CBOR major types, RFC 7049
+const (
+	cborUnsignedInt byte = iota
+	cborNegativeInt
+	cborByteString
+	cborTextString
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+// ErrCBORTruncated is returned when the CBOR input ends before the data item is complete.
+var ErrCBORTruncated = errors.New("cbor: unexpected end of input")
+
+// DecodeCBOR decodes a single CBOR data item into the Go types used by encoding/json:
+// map[string]interface{}, []interface{}, string, bool, nil and float64.
+// Integers are returned as int64 (or uint64 when they do not fit) and byte strings as []byte.
+// Byte string map keys are converted to strings. Tags are skipped and indefinite length items are not supported.
+func DecodeCBOR(data []byte) (interface{}, error) {
+	d := &cborDecoder{data: data}
+
+	v, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("cbor: %d unexpected trailing bytes", len(d.data)-d.pos)
+	}
+
+	return v, nil
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *cborDecoder) next(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, ErrCBORTruncated
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+
+	return b, nil
+}
+
+// head reads the initial byte and the argument of a data item.
+func (d *cborDecoder) head() (byte, byte, uint64, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	major := b[0] >> 5
+	info := b[0] & 0x1f
+
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		b, err = d.next(1)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, uint64(b[0]), nil
+	case info == 25:
+		b, err = d.next(2)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, uint64(binary.BigEndian.Uint16(b)), nil
+	case info == 26:
+		b, err = d.next(4)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, uint64(binary.BigEndian.Uint32(b)), nil
+	case info == 27:
+		b, err = d.next(8)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, binary.BigEndian.Uint64(b), nil
+	}
+
+	return 0, 0, 0, fmt.Errorf("cbor: unsupported additional information %d", info)
+}
+
+func (d *cborDecoder) decode() (interface{}, error) {
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case cborUnsignedInt:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+	case cborNegativeInt:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: negative integer overflows int64")
+		}
+		return -1 - int64(arg), nil
+	case cborByteString:
+		b, err := d.next(arg)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, len(b))
+		copy(out, b)
+		return out, nil
+	case cborTextString:
+		b, err := d.next(arg)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case cborArray:
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, ErrCBORTruncated
+		}
+		arr := make([]interface{}, 0, int(arg))
+		for i := uint64(0); i < arg; i++ {
+			v, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+	case cborMap:
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, ErrCBORTruncated
+		}
+		m := make(map[string]interface{}, int(arg))
+		for i := uint64(0); i < arg; i++ {
+			k, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			switch key := k.(type) {
+			case string:
+				m[key] = v
+			case []byte:
+				m[string(key)] = v
+			default:
+				m[fmt.Sprintf("%v", key)] = v
+			}
+		}
+		return m, nil
+	case cborTag:
+		return d.decode()
+	}
+
+	// cborSimple
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return float64(halfToFloat32(uint16(arg))), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case 27:
+		return math.Float64frombits(arg), nil
+	}
+
+	return nil, fmt.Errorf("cbor: unsupported simple value %d", arg)
+}
+
+// halfToFloat32 converts an IEEE 754 half precision float to a float32.
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h) & 0x3ff
+
+	switch exp {
+	case 0:
+		// subnormal
+		f := float32(frac) / (1 << 24)
+		if sign != 0 {
+			return -f
+		}
+		return f
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | frac<<13)
+	}
+
+	return math.Float32frombits(sign | (exp+112)<<23 | frac<<13)
+}
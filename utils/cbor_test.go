@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeCBORScalars(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := map[string]interface{}{
+		"00":                 int64(0),
+		"17":                 int64(23),
+		"1818":               int64(24),
+		"1903e8":             int64(1000),
+		"1a000f4240":         int64(1000000),
+		"1bffffffffffffffff": uint64(18446744073709551615),
+		"20":                 int64(-1),
+		"3903e7":             int64(-1000),
+		"f4":                 false,
+		"f5":                 true,
+		"f6":                 nil,
+		"f93c00":             float64(1),
+		"f9c400":             float64(-4),
+		"fa47c35000":         float64(100000),
+		"fb3ff199999999999a": float64(1.1),
+		"6449455446":         "IETF",
+		"4401020304":         []byte{1, 2, 3, 4},
+		"c11a514b67b0":       int64(1363896240),
+	}
+
+	for in, expected := range tests {
+		b, _ := hex.DecodeString(in)
+		v, err := DecodeCBOR(b)
+
+		assert.Nil(err, in)
+		assert.Equal(expected, v, in)
+	}
+}
+
+func TestDecodeCBORCollections(t *testing.T) {
+	assert := assert.New(t)
+
+	// {"a": 1, "b": [2, 3], h'63': "d"}
+	b, _ := hex.DecodeString("a3616101616282020341636164")
+	v, err := DecodeCBOR(b)
+
+	assert.Nil(err)
+	assert.Equal(map[string]interface{}{
+		"a": int64(1),
+		"b": []interface{}{int64(2), int64(3)},
+		"c": "d",
+	}, v)
+}
+
+func TestDecodeCBORErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, in := range []string{"", "19", "6449", "a26161", "8301", "0000", "5f"} {
+		b, _ := hex.DecodeString(in)
+		_, err := DecodeCBOR(b)
+
+		assert.NotNil(err, in)
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 )
@@ -17,6 +20,151 @@ import (
 // 16 byte IV
 var valIV = "0123456789012345"
 
+const (
+	// cryptorHeaderSentinel marks the start of the header of the payloads encrypted by a cryptor.
+	cryptorHeaderSentinel = "PNED"
+	// cryptorHeaderVersion is the version of the header layout:
+	// sentinel (4 bytes), version (1 byte), cryptor ID (4 bytes),
+	// metadata length (1 byte, or 0xff followed by 2 bytes) and metadata.
+	cryptorHeaderVersion byte = 1
+	cryptorIDLength           = 4
+
+	// AESCBCCryptorID is the cryptor ID in the header of the payloads encrypted with AES-256-CBC and a random IV.
+	AESCBCCryptorID = "ACRH"
+)
+
+// ErrNoCryptorHeader is returned by DecodeCryptorHeader when the data does not start with a cryptor header,
+// in which case it was encrypted with the legacy static IV.
+var ErrNoCryptorHeader = errors.New("cryptor header not found")
+
+// EncodeCryptorHeader creates the header which is prefixed to the payloads encrypted by the cryptor with the id.
+// The metadata (for example the IV) is stored in the header.
+func EncodeCryptorHeader(id string, metadata []byte) ([]byte, error) {
+	if len(id) != cryptorIDLength {
+		return nil, fmt.Errorf("cryptor id must be %d bytes: %q", cryptorIDLength, id)
+	}
+	if len(metadata) > 0xffff {
+		return nil, fmt.Errorf("cryptor metadata too long: %d", len(metadata))
+	}
+
+	header := bytes.NewBufferString(cryptorHeaderSentinel)
+	header.WriteByte(cryptorHeaderVersion)
+	header.WriteString(id)
+	if len(metadata) < 0xff {
+		header.WriteByte(byte(len(metadata)))
+	} else {
+		header.WriteByte(0xff)
+		l := make([]byte, 2)
+		binary.BigEndian.PutUint16(l, uint16(len(metadata)))
+		header.Write(l)
+	}
+	header.Write(metadata)
+
+	return header.Bytes(), nil
+}
+
+// DecodeCryptorHeader splits the data into the cryptor ID, the metadata and the encrypted payload.
+// ErrNoCryptorHeader is returned if the data has no header.
+func DecodeCryptorHeader(data []byte) (id string, metadata []byte, payload []byte, err error) {
+	if !bytes.HasPrefix(data, []byte(cryptorHeaderSentinel)) {
+		return "", nil, data, ErrNoCryptorHeader
+	}
+
+	pos := len(cryptorHeaderSentinel)
+	if len(data) < pos+1+cryptorIDLength+1 {
+		return "", nil, data, errors.New("cryptor header is truncated")
+	}
+
+	if v := data[pos]; v != cryptorHeaderVersion {
+		return "", nil, data, fmt.Errorf("unknown cryptor header version %d", v)
+	}
+	pos++
+
+	id = string(data[pos : pos+cryptorIDLength])
+	pos += cryptorIDLength
+
+	metadataLen := int(data[pos])
+	pos++
+	if metadataLen == 0xff {
+		if len(data) < pos+2 {
+			return "", nil, data, errors.New("cryptor header is truncated")
+		}
+		metadataLen = int(binary.BigEndian.Uint16(data[pos : pos+2]))
+		pos += 2
+	}
+
+	if len(data) < pos+metadataLen {
+		return "", nil, data, errors.New("cryptor header is truncated")
+	}
+
+	return id, data[pos : pos+metadataLen], data[pos+metadataLen:], nil
+}
+
+// EncryptAESCBC encrypts the data with AES-256-CBC using the SHA-256 of the cipherKey
+// as the key and a random IV, which is returned along with the encrypted data.
+func EncryptAESCBC(cipherKey string, data []byte) (iv []byte, encrypted []byte, err error) {
+	key := sha256.Sum256([]byte(cipherKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	iv = make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, nil, err
+	}
+
+	value := padWithPKCS7(data)
+	encrypted = make([]byte, len(value))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, value)
+
+	return iv, encrypted, nil
+}
+
+// DecryptAESCBC decrypts the data encrypted by EncryptAESCBC.
+func DecryptAESCBC(cipherKey string, iv []byte, data []byte) ([]byte, error) {
+	key := sha256.Sum256([]byte(cipherKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("invalid iv len %d", len(iv))
+	}
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("invalid data len %d", len(data))
+	}
+
+	decrypted := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, data)
+
+	return unpadPKCS7(decrypted)
+}
+
+// EncryptStringWithRandomIV creates the base64 encoded encrypted string using the
+// cipherKey, AES-256-CBC and a random IV. The IV is stored in the cryptor header
+// which is prefixed to the encrypted string, so that identical messages do not
+// yield identical encrypted strings.
+// It accepts the following parameters:
+// cipherKey: cipher key to use to encrypt.
+// message: to encrypted.
+//
+// returns the base64 encoded encrypted string.
+func EncryptStringWithRandomIV(cipherKey string, message string) (string, error) {
+	iv, encrypted, err := EncryptAESCBC(cipherKey, []byte(message))
+	if err != nil {
+		return "", err
+	}
+
+	header, err := EncodeCryptorHeader(AESCBCCryptorID, iv)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(append(header, encrypted...)), nil
+}
+
 // EncryptString creates the base64 encoded encrypted string using the
 // cipherKey.
 // It accepts the following parameters:
@@ -44,7 +192,8 @@ type B struct {
 	Value string
 }
 
-// DecryptString decodes encrypted string using the cipherKey
+// DecryptString decodes encrypted string using the cipherKey.
+// Both the strings encrypted with EncryptString and EncryptStringWithRandomIV are accepted.
 //
 // It accepts the following parameters:
 // cipherKey: cipher key to use to decrypt.
@@ -67,6 +216,16 @@ func DecryptString(cipherKey string, message string) (
 	if decodeErr != nil {
 		return "***decrypt error***", fmt.Errorf("decrypt error on decode: %s", decodeErr)
 	}
+
+	// messages encrypted with a random IV carry a cryptor header,
+	// the others were encrypted with the legacy static IV
+	if id, iv, payload, headerErr := DecodeCryptorHeader(value); headerErr == nil && id == AESCBCCryptorID {
+		val, err := DecryptAESCBC(cipherKey, iv, payload)
+		if err == nil {
+			return string(val), nil
+		}
+	}
+
 	decrypter := cipher.NewCBCDecrypter(block, []byte(valIV))
 	//to handle decryption errors
 	defer func() {
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
@@ -460,6 +461,76 @@ func TestComplexClassEncryption(t *testing.T) {
 	assert.Equal("Bc846Ri5HK1ixqP/dzAyZq23Z/NBlcPn2UX8h38xTGINs72yF5gtU0t9fFEMxjY+DmezWt0nG7eN7RABrj697tK1nooVHYIxgDLMsjMTw5N0K+rUM823n7LcHfEoXaX8oH2E6zkg6iK5pmT8nlh6LF6Bw1G5zkluT8oTjnbFJcpEvTyT2ZKzcqptgYsE9XZiEn84zv0wjDMxSJzlM7cbe2JpLtR99mdkUf8SMVr+J0ym6Z9c02MKLP6bygWzdG9zTdkLSIxJE3R9Yt76XeRFdrbRNWkuQM/uItDsE23+8RKwZRyAScoDMwFAg+BSa6KF1tS6cJlyjxA8o5e9iWykKuHO0h1uAiapzTx9iZluOH2bVZgTUu1GABjXveMBAkrZ1eG4nVOlytsAr1oSekKvWxzyUEP2kFSrtQbg6oGECb1OMmj5bd21cx0vpDWr/juGT7/n4sBr7gYsWDvBaU7awN9Y7bcq14jtiXq/2iNNW0zoI3xe6+qByimHaiAgVoqO", encrypted)
 }
 
+// TestRandomIVEncryption tests the random IV encryption round trip.
+// Each encryption of the same message should yield a different string.
+func TestRandomIVEncryption(t *testing.T) {
+	assert := assert.New(t)
+
+	message := "{\"text\":\"hey\",\"unicode\":\"漢字 😀\"}"
+
+	encrypted1, err := EncryptStringWithRandomIV("enigma", message)
+	assert.NoError(err)
+	encrypted2, err := EncryptStringWithRandomIV("enigma", message)
+	assert.NoError(err)
+	assert.NotEqual(encrypted1, encrypted2)
+
+	raw, _ := base64.StdEncoding.DecodeString(encrypted1)
+	assert.Equal([]byte("PNED\x01ACRH\x10"), raw[:10])
+
+	decrypted, decErr := DecryptString("enigma", encrypted1)
+	assert.NoError(decErr)
+	assert.Equal(message, decrypted)
+
+	decrypted, decErr = DecryptString("enigma", encrypted2)
+	assert.NoError(decErr)
+	assert.Equal(message, decrypted)
+
+	_, decErr = DecryptString("test", encrypted1)
+	assert.Error(decErr)
+}
+
+// TestRandomIVDecryptionLegacy tests that strings encrypted with the static IV still decrypt.
+func TestRandomIVDecryptionLegacy(t *testing.T) {
+	assert := assert.New(t)
+
+	decrypted, decErr := DecryptString("enigma", EncryptString("enigma", "yay!"))
+	assert.NoError(decErr)
+	assert.Equal("yay!", decrypted)
+}
+
+func TestCryptorHeader(t *testing.T) {
+	assert := assert.New(t)
+
+	header, err := EncodeCryptorHeader(AESCBCCryptorID, []byte("0123456789012345"))
+	assert.NoError(err)
+
+	id, metadata, payload, err := DecodeCryptorHeader(append(header, []byte("payload")...))
+	assert.NoError(err)
+	assert.Equal(AESCBCCryptorID, id)
+	assert.Equal([]byte("0123456789012345"), metadata)
+	assert.Equal([]byte("payload"), payload)
+
+	longMetadata := bytes.Repeat([]byte("m"), 300)
+	header, err = EncodeCryptorHeader("ABCD", longMetadata)
+	assert.NoError(err)
+	assert.Equal(byte(0xff), header[9])
+
+	id, metadata, payload, err = DecodeCryptorHeader(header)
+	assert.NoError(err)
+	assert.Equal("ABCD", id)
+	assert.Equal(longMetadata, metadata)
+	assert.Empty(payload)
+
+	_, _, _, err = DecodeCryptorHeader([]byte("legacy payload"))
+	assert.Equal(ErrNoCryptorHeader, err)
+
+	_, _, _, err = DecodeCryptorHeader(header[:20])
+	assert.Error(err)
+
+	_, err = EncodeCryptorHeader("TOOLONG", nil)
+	assert.Error(err)
+}
+
 // Data represents a <data> element.
 type data struct {
 	XMLName xml.Name `xml:"data"`
@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// LegacyCryptorID is the ID of the cryptor which encrypts with the static IV.
+// The payloads it encrypts have no cryptor header.
+const LegacyCryptorID = "\x00\x00\x00\x00"
+
+// EncryptedData is the output of a Cryptor. The Metadata is stored in the cryptor header
+// of the payload and handed back to the Cryptor on decryption.
+type EncryptedData struct {
+	Metadata []byte
+	Data     []byte
+}
+
+// Cryptor encrypts and decrypts the payloads sent to and received from PubNub.
+type Cryptor interface {
+	// ID is the 4 byte identifier stored in the header of the payloads encrypted by the Cryptor.
+	ID() string
+	Encrypt(data []byte) (*EncryptedData, error)
+	Decrypt(data *EncryptedData) ([]byte, error)
+}
+
+type legacyCryptor struct {
+	cipherKey string
+}
+
+// NewLegacyCryptor creates the Cryptor which encrypts with the cipherKey and the static IV,
+// the same as EncryptString.
+func NewLegacyCryptor(cipherKey string) Cryptor {
+	return &legacyCryptor{cipherKey: cipherKey}
+}
+
+func (c *legacyCryptor) ID() string {
+	return LegacyCryptorID
+}
+
+func (c *legacyCryptor) Encrypt(data []byte) (*EncryptedData, error) {
+	block, err := aesCipher(c.cipherKey)
+	if err != nil {
+		return nil, err
+	}
+
+	value := padWithPKCS7([]byte(encodeNonASCIIChars(string(data))))
+	encrypted := make([]byte, len(value))
+	cipher.NewCBCEncrypter(block, []byte(valIV)).CryptBlocks(encrypted, value)
+
+	return &EncryptedData{Data: encrypted}, nil
+}
+
+func (c *legacyCryptor) Decrypt(data *EncryptedData) ([]byte, error) {
+	block, err := aesCipher(c.cipherKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data.Data) == 0 || len(data.Data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("invalid data len %d", len(data.Data))
+	}
+
+	decrypted := make([]byte, len(data.Data))
+	cipher.NewCBCDecrypter(block, []byte(valIV)).CryptBlocks(decrypted, data.Data)
+
+	return unpadPKCS7(decrypted)
+}
+
+type aesCBCCryptor struct {
+	cipherKey string
+}
+
+// NewAESCBCCryptor creates the Cryptor which encrypts with AES-256-CBC and a random IV,
+// the same as EncryptStringWithRandomIV.
+func NewAESCBCCryptor(cipherKey string) Cryptor {
+	return &aesCBCCryptor{cipherKey: cipherKey}
+}
+
+func (c *aesCBCCryptor) ID() string {
+	return AESCBCCryptorID
+}
+
+func (c *aesCBCCryptor) Encrypt(data []byte) (*EncryptedData, error) {
+	iv, encrypted, err := EncryptAESCBC(c.cipherKey, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptedData{Metadata: iv, Data: encrypted}, nil
+}
+
+func (c *aesCBCCryptor) Decrypt(data *EncryptedData) ([]byte, error) {
+	return DecryptAESCBC(c.cipherKey, data.Metadata, data.Data)
+}
+
+// CryptoModule encrypts the payloads with a single Cryptor and decrypts them with
+// the Cryptor matching the ID in their header.
+type CryptoModule struct {
+	encryptor  Cryptor
+	decryptors map[string]Cryptor
+}
+
+// NewCryptoModule creates a CryptoModule which encrypts with the encryptor.
+// The encryptor and the decryptors are used for decryption.
+func NewCryptoModule(encryptor Cryptor, decryptors ...Cryptor) *CryptoModule {
+	m := &CryptoModule{
+		encryptor:  encryptor,
+		decryptors: make(map[string]Cryptor, len(decryptors)+1),
+	}
+
+	for _, c := range decryptors {
+		if c != nil {
+			m.decryptors[c.ID()] = c
+		}
+	}
+	if encryptor != nil {
+		m.decryptors[encryptor.ID()] = encryptor
+	}
+
+	return m
+}
+
+// Encrypt encrypts the data and prefixes the cryptor header,
+// unless the encryptor is the legacy cryptor.
+func (m *CryptoModule) Encrypt(data []byte) ([]byte, error) {
+	if m.encryptor == nil {
+		return nil, errors.New("crypto module has no encryptor")
+	}
+
+	encrypted, err := m.encryptor.Encrypt(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.encryptor.ID() == LegacyCryptorID {
+		return encrypted.Data, nil
+	}
+
+	header, err := EncodeCryptorHeader(m.encryptor.ID(), encrypted.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(header, encrypted.Data...), nil
+}
+
+// Decrypt decrypts the data with the Cryptor matching the ID in its header.
+// Data without a header is decrypted with the legacy cryptor, if one is registered.
+func (m *CryptoModule) Decrypt(data []byte) ([]byte, error) {
+	id, metadata, payload, err := DecodeCryptorHeader(data)
+	if err == nil {
+		if c, ok := m.decryptors[id]; ok {
+			decrypted, decErr := c.Decrypt(&EncryptedData{Metadata: metadata, Data: payload})
+			if decErr == nil {
+				return decrypted, nil
+			}
+			err = decErr
+		} else {
+			err = fmt.Errorf("unknown cryptor id %q", id)
+		}
+	}
+
+	// a legacy payload may start with the header sentinel by chance
+	legacy, ok := m.decryptors[LegacyCryptorID]
+	if !ok {
+		return nil, err
+	}
+
+	return legacy.Decrypt(&EncryptedData{Data: data})
+}
+
+// EncryptString encrypts the message and returns the base64 encoded string.
+func (m *CryptoModule) EncryptString(message string) (string, error) {
+	encrypted, err := m.Encrypt([]byte(message))
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+// DecryptString decrypts the base64 encoded message.
+func (m *CryptoModule) DecryptString(message string) (string, error) {
+	if message == "" {
+		return "", errors.New("message is empty")
+	}
+
+	value, err := base64.StdEncoding.DecodeString(message)
+	if err != nil {
+		return "", fmt.Errorf("decrypt error on decode: %s", err)
+	}
+
+	decrypted, err := m.Decrypt(value)
+	if err != nil {
+		return "", fmt.Errorf("decrypt error: %s", err)
+	}
+
+	return string(decrypted), nil
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// xorCryptor is a custom Cryptor which stores a key id in the metadata.
+type xorCryptor struct {
+	key byte
+}
+
+func (c *xorCryptor) ID() string {
+	return "XORC"
+}
+
+func (c *xorCryptor) Encrypt(data []byte) (*EncryptedData, error) {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[i] = b ^ c.key
+	}
+
+	return &EncryptedData{Metadata: []byte{c.key}, Data: out}, nil
+}
+
+func (c *xorCryptor) Decrypt(data *EncryptedData) ([]byte, error) {
+	if len(data.Metadata) != 1 || data.Metadata[0] != c.key {
+		return nil, errors.New("unknown key")
+	}
+
+	out := make([]byte, len(data.Data))
+	for i, b := range data.Data {
+		out[i] = b ^ c.key
+	}
+
+	return out, nil
+}
+
+func TestCryptoModuleLegacy(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewCryptoModule(NewLegacyCryptor("enigma"))
+
+	encrypted, err := m.EncryptString("yay!")
+	assert.Nil(err)
+	assert.Equal(EncryptString("enigma", "yay!"), encrypted)
+
+	decrypted, err := m.DecryptString(EncryptString("enigma", "yay!"))
+	assert.Nil(err)
+	assert.Equal("yay!", decrypted)
+}
+
+func TestCryptoModuleAESCBC(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewCryptoModule(NewAESCBCCryptor("enigma"), NewLegacyCryptor("enigma"))
+
+	encrypted, err := m.EncryptString("yay!")
+	assert.Nil(err)
+
+	raw, _ := base64.StdEncoding.DecodeString(encrypted)
+	assert.True(bytes.HasPrefix(raw, []byte("PNED\x01ACRH")))
+
+	decrypted, err := DecryptString("enigma", encrypted)
+	assert.Nil(err)
+	assert.Equal("yay!", decrypted)
+
+	decrypted2, err := m.DecryptString(encrypted)
+	assert.Nil(err)
+	assert.Equal("yay!", decrypted2)
+
+	decrypted2, err = m.DecryptString(EncryptString("enigma", "yay!"))
+	assert.Nil(err)
+	assert.Equal("yay!", decrypted2)
+}
+
+func TestCryptoModuleCustomCryptor(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewCryptoModule(&xorCryptor{key: 7}, NewLegacyCryptor("enigma"))
+
+	encrypted, err := m.Encrypt([]byte("hello"))
+	assert.Nil(err)
+
+	id, metadata, payload, err := DecodeCryptorHeader(encrypted)
+	assert.Nil(err)
+	assert.Equal("XORC", id)
+	assert.Equal([]byte{7}, metadata)
+	assert.NotEqual([]byte("hello"), payload)
+
+	decrypted, err := m.Decrypt(encrypted)
+	assert.Nil(err)
+	assert.Equal([]byte("hello"), decrypted)
+
+	// payloads of the other registered cryptors are still decrypted
+	decryptedString, err := m.DecryptString(EncryptString("enigma", "yay!"))
+	assert.Nil(err)
+	assert.Equal("yay!", decryptedString)
+}
+
+func TestCryptoModuleUnknownCryptor(t *testing.T) {
+	assert := assert.New(t)
+
+	encrypted, err := NewCryptoModule(&xorCryptor{key: 7}).Encrypt([]byte("hello"))
+	assert.Nil(err)
+
+	_, err = NewCryptoModule(NewAESCBCCryptor("enigma")).Decrypt(encrypted)
+	assert.Contains(err.Error(), "unknown cryptor id")
+
+	_, err = NewCryptoModule(NewAESCBCCryptor("enigma")).Decrypt([]byte("legacy"))
+	assert.Equal(ErrNoCryptorHeader, err)
+
+	_, err = NewCryptoModule(nil).Encrypt([]byte("hello"))
+	assert.NotNil(err)
+}
@@ -57,40 +57,42 @@ func Serialize(msg interface{}) ([]byte, error) {
 	return jsonSerialized, nil
 }
 
+// SerializeAndEncrypt serializes the msg if needed and encrypts it with the cipherKey and the static IV.
 func SerializeAndEncrypt(msg interface{}, cipherKey string, serialize bool) (string, error) {
-	var encrypted string
+	return SerializeAndEncryptWithModule(msg, NewCryptoModule(NewLegacyCryptor(cipherKey)), serialize)
+}
+
+// SerializeAndEncryptWithModule serializes the msg if needed and encrypts it with the module.
+func SerializeAndEncryptWithModule(msg interface{}, module *CryptoModule, serialize bool) (string, error) {
+	var plain string
 	if serialize {
 		jsonSerialized, errJSONMarshal := json.Marshal(msg)
 		if errJSONMarshal != nil {
 			return "", errJSONMarshal
 		}
-		encrypted = EncryptString(cipherKey, string(jsonSerialized))
+		plain = string(jsonSerialized)
 	} else {
 		if serializedMsg, ok := msg.(string); ok {
-			encrypted = EncryptString(cipherKey, serializedMsg)
+			plain = serializedMsg
 		} else {
 			return "", pnerr.NewBuildRequestError("Message is not JSON serialized.")
 		}
 	}
 
-	return encrypted, nil
+	return module.EncryptString(plain)
 }
 
+// SerializeEncryptAndSerialize encrypts the msg with SerializeAndEncrypt and serializes the encrypted string.
 func SerializeEncryptAndSerialize(msg interface{}, cipherKey string, serialize bool) (string, error) {
-	var encrypted string
+	return SerializeEncryptAndSerializeWithModule(msg, NewCryptoModule(NewLegacyCryptor(cipherKey)), serialize)
+}
 
-	if serialize {
-		jsonSerialized, errJSONMarshal := json.Marshal(msg)
-		if errJSONMarshal != nil {
-			return "", errJSONMarshal
-		}
-		encrypted = EncryptString(cipherKey, string(jsonSerialized))
-	} else {
-		if serializedMsg, ok := msg.(string); ok {
-			encrypted = EncryptString(cipherKey, serializedMsg)
-		} else {
-			return "", pnerr.NewBuildRequestError("Message is not JSON serialized.")
-		}
+// SerializeEncryptAndSerializeWithModule encrypts the msg with SerializeAndEncryptWithModule and
+// serializes the encrypted string.
+func SerializeEncryptAndSerializeWithModule(msg interface{}, module *CryptoModule, serialize bool) (string, error) {
+	encrypted, err := SerializeAndEncryptWithModule(msg, module, serialize)
+	if err != nil {
+		return "", err
 	}
 	jsonSerialized, errJSONMarshal := json.Marshal(encrypted)
 	if errJSONMarshal != nil {
@@ -27,3 +27,23 @@ func TestURLEncode(t *testing.T) {
 	assert.Equal("%5B%22hey1%22%2C%20%22hey2%22%2C%20%22hey3%5D",
 		URLEncode(`["hey1", "hey2", "hey3]`))
 }
+
+func TestSerializeAndEncrypt(t *testing.T) {
+	assert := assert.New(t)
+
+	encrypted, err := SerializeAndEncrypt("yay!", "enigma", true)
+	assert.Nil(err)
+	assert.Equal(EncryptString("enigma", `"yay!"`), encrypted)
+
+	module := NewCryptoModule(NewLegacyCryptor("enigma"))
+	withModule, err := SerializeAndEncryptWithModule("yay!", module, true)
+	assert.Nil(err)
+	assert.Equal(encrypted, withModule)
+
+	serialized, err := SerializeEncryptAndSerialize(`"yay!"`, "enigma", false)
+	assert.Nil(err)
+	assert.Equal(`"`+encrypted+`"`, serialized)
+
+	_, err = SerializeEncryptAndSerializeWithModule(42, module, false)
+	assert.NotNil(err)
+}
@@ -87,6 +87,10 @@ func (o *whereNowOpts) client() *http.Client {
 	return o.pubnub.GetClient()
 }
 
+func (o *whereNowOpts) transport() http.RoundTripper {
+	return o.Transport
+}
+
 func (o *whereNowOpts) context() Context {
 	return o.ctx
 }
@@ -111,8 +115,8 @@ func (o *whereNowOpts) buildQuery() (*url.Values, error) {
 	return q, nil
 }
 
-func (o *whereNowOpts) jobQueue() chan *JobQItem {
-	return o.pubnub.jobQueue
+func (o *whereNowOpts) requestWorkers() *RequestWorkers {
+	return o.pubnub.requestWorkers
 }
 
 func (o *whereNowOpts) buildBody() ([]byte, error) {